@@ -0,0 +1,55 @@
+package consts
+
+// AI生成题目任务状态 0=排队中 1=生成中 2=已完成 3=已取消 4=失败
+const (
+	AIGenerateJobStatusQueued = iota
+	AIGenerateJobStatusRunning
+	AIGenerateJobStatusSucceeded
+	AIGenerateJobStatusCanceled
+	AIGenerateJobStatusFailed
+)
+
+// AI生成题目的数量限制：同步接口单次最多AIGenerateSyncMaxCount道，
+// 异步任务最多AIGenerateJobMaxCount道，按AIGenerateChunkSize道一批调用大模型
+const (
+	AIGenerateSyncMaxCount    = 10
+	AIGenerateJobMaxCount     = 200
+	AIGenerateJobMaxTargets   = 20 // 单个任务最多的分类数
+	AIGenerateChunkSize       = 10
+	AIGenerateChunkMaxRetries = 3 // 单批调用失败（网络错误、回复无法解析等）时的最大重试次数
+	AIGenerateJobMaxRunning   = 2 // 同时执行的任务数，其余任务排队
+)
+
+// IsAIGenerateJobFinished 判断任务是否已结束（完成、取消或失败）
+func IsAIGenerateJobFinished(status int) bool {
+	switch status {
+	case AIGenerateJobStatusSucceeded, AIGenerateJobStatusCanceled, AIGenerateJobStatusFailed:
+		return true
+	}
+	return false
+}
+
+func GetAIGenerateJobStatusName(status int) string {
+	switch status {
+	case AIGenerateJobStatusQueued:
+		return "排队中"
+	case AIGenerateJobStatusRunning:
+		return "生成中"
+	case AIGenerateJobStatusSucceeded:
+		return "已完成"
+	case AIGenerateJobStatusCanceled:
+		return "已取消"
+	case AIGenerateJobStatusFailed:
+		return "失败"
+	}
+	return "未知"
+}
+
+// 提示词模板限制
+const (
+	DefaultPromptTemplateName   = "default" // 内置默认模板，未指定模板时使用，不可删除
+	PromptTemplateNameMaxLen    = 64
+	PromptTemplateContentMaxLen = 20000
+	PromptDifficultyMaxLen      = 20
+	PromptExampleMaxCount       = 5 // few-shot示例题目的最大数量
+)
@@ -0,0 +1,35 @@
+package consts
+
+// 考试会话状态 0=进行中 1=已交卷
+const (
+	ExamSessionStatusInProgress = iota
+	ExamSessionStatusFinished
+)
+
+// 单题作答结果 0=未作答 1=正确 2=错误 3=待评阅（问答题需自评或AI评阅）
+const (
+	AnswerResultUnanswered = iota
+	AnswerResultCorrect
+	AnswerResultWrong
+	AnswerResultPendingReview
+)
+
+// 考试会话题目数量限制
+const (
+	ExamSessionDefaultCount = 10
+	ExamSessionMaxCount     = 50
+)
+
+func GetAnswerResultName(result int) string {
+	switch result {
+	case AnswerResultUnanswered:
+		return "未作答"
+	case AnswerResultCorrect:
+		return "正确"
+	case AnswerResultWrong:
+		return "错误"
+	case AnswerResultPendingReview:
+		return "待评阅"
+	}
+	return "未知"
+}
@@ -0,0 +1,25 @@
+package consts
+
+// 大模型调用结果 1=成功 2=失败
+const (
+	LLMCallStatusSucceeded = 1
+	LLMCallStatusFailed    = 2
+)
+
+// 大模型调用场景
+const (
+	LLMCallSceneGenerate = "generate" // 同步生成题目
+	LLMCallSceneJob      = "job"      // 异步生成题目任务
+	LLMCallSceneGrade    = "grade"    // 问答题AI评分
+	LLMCallSceneEnrich   = "enrich"   // 为已有题目补充解析
+)
+
+// 大模型用量统计与预算
+const (
+	LLMCallStatsMaxDays = 90 // 用量统计单次查询的最大天数
+	// LLMDailyTokenBudgetEnv 全部服务每日token预算（输入+输出），未设置或为0表示不限制；
+	// 单个服务的预算使用LLMDailyTokenBudgetEnv加服务名大写后缀，如LLM_DAILY_TOKEN_BUDGET_DOUBAO
+	LLMDailyTokenBudgetEnv = "LLM_DAILY_TOKEN_BUDGET"
+	// LLMTokenPriceEnvPrefix 服务的token单价（元/百万token），格式为"输入单价,输出单价"，如LLM_TOKEN_PRICE_DOUBAO=0.8,2
+	LLMTokenPriceEnvPrefix = "LLM_TOKEN_PRICE_"
+)
@@ -1,24 +1,102 @@
 package consts
 
-// 题目录入方式，默认0=手动 1=excel表格 2=豆包AI 3=阿里AI 4=云雾AI
+// 题目录入方式，默认0=手动 1=excel表格 2=豆包AI 3=阿里AI 4=云雾AI 5=OpenAI兼容接口
 const (
 	QuestionImportTypeManual = iota
 	QuestionImportTypeExcel
 	QuestionImportTypeAiDouBao
 	QuestionImportTypeAiAli
 	QuestionImportTypeAiYunWu
+	QuestionImportTypeAiOpenAI
 )
 
-// 题目类型 0=选择题，1=填空题，2=问答题
+// 题目类型 0=选择题（单选），1=填空题，2=问答题，3=多选题，4=判断题，5=排序题，6=连线题
 const (
 	QuestionTypeChoice = iota
 	QuestionTypeFillInTheBlank
 	QuestionTypeShortAnswer
+	QuestionTypeMultipleChoice
+	QuestionTypeTrueFalse
+	QuestionTypeOrdering
+	QuestionTypeMatching
 )
 
+// QuestionTypes 全部题型（按题型值升序）
+var QuestionTypes = []int{
+	QuestionTypeChoice,
+	QuestionTypeFillInTheBlank,
+	QuestionTypeShortAnswer,
+	QuestionTypeMultipleChoice,
+	QuestionTypeTrueFalse,
+	QuestionTypeOrdering,
+	QuestionTypeMatching,
+}
+
+// 题目审核状态：0=已通过（正式题库） 1=待审核（AI生成的草稿） 2=已驳回
+// 只有已通过的题目参与随机练习、考试抽题与统计
+const (
+	QuestionStatusApproved = iota
+	QuestionStatusPending
+	QuestionStatusRejected
+)
+
+// QuestionRejectReasonMaxLen 驳回原因最大字符数
+const QuestionRejectReasonMaxLen = 500
+
+// QuestionExternalIDMaxLen 外部题目编号最大字符数
+const QuestionExternalIDMaxLen = 64
+
+// 重复题目的处理策略（新增、Excel导入、AI生成时使用）
+const (
+	DuplicatePolicySkip  = "skip"  // 跳过重复题目（默认）
+	DuplicatePolicyFlag  = "flag"  // 仍然入库，并记录疑似重复的已有题目ID
+	DuplicatePolicyMerge = "merge" // 不新增题目，将解析、备注、分类补充到已有题目
+)
+
+// 题干相似度阈值：归一化题干的字符二元组Jaccard相似度达到该值即视为疑似重复
+const (
+	DuplicateSimilarityThreshold    = 0.8
+	DuplicateSimilarityMinThreshold = 0.5 // 查询重复题目聚类时允许的最小阈值
+)
+
+// DuplicateClusterMaxQuestions 查询重复题目聚类时单次参与比较的最大题目数，超过时需按题型或一级分类缩小范围
+const DuplicateClusterMaxQuestions = 5000
+
+// 选项题（单选、多选、排序、连线）的选项数量与长度限制
+const (
+	QuestionOptionMinCount = 2
+	QuestionOptionMaxCount = 8
+	QuestionOptionMaxLen   = 1000 // 单个选项的最大字符数
+)
+
+// 填空题的空数量与正则长度限制
+const (
+	FillBlankMaxCount    = 20
+	FillBlankRegexMaxLen = 200
+)
+
+// 判断题的标准答案
+const (
+	TrueFalseAnswerTrue  = "对"
+	TrueFalseAnswerFalse = "错"
+)
+
+// MatchingOptionSeparator 连线题每个选项中左项与右项的分隔符，如“MySQL|关系型”
+const MatchingOptionSeparator = "|"
+
 func CheckQuestionType(questionType int) bool {
 	switch questionType {
-	case QuestionTypeChoice, QuestionTypeFillInTheBlank, QuestionTypeShortAnswer:
+	case QuestionTypeChoice, QuestionTypeFillInTheBlank, QuestionTypeShortAnswer,
+		QuestionTypeMultipleChoice, QuestionTypeTrueFalse, QuestionTypeOrdering, QuestionTypeMatching:
+		return true
+	}
+	return false
+}
+
+// IsOptionQuestionType 判断题型是否需要选项（单选、多选、排序、连线）
+func IsOptionQuestionType(questionType int) bool {
+	switch questionType {
+	case QuestionTypeChoice, QuestionTypeMultipleChoice, QuestionTypeOrdering, QuestionTypeMatching:
 		return true
 	}
 	return false
@@ -32,6 +110,77 @@ func GetQuestionTypeName(questionType int) string {
 		return "填空题"
 	case QuestionTypeShortAnswer:
 		return "简答题"
+	case QuestionTypeMultipleChoice:
+		return "多选题"
+	case QuestionTypeTrueFalse:
+		return "判断题"
+	case QuestionTypeOrdering:
+		return "排序题"
+	case QuestionTypeMatching:
+		return "连线题"
 	}
 	return "未知"
 }
+
+// GetQuestionTypeByName 根据题型名称（如“选择题”）获取题型
+func GetQuestionTypeByName(name string) (int, bool) {
+	for _, questionType := range QuestionTypes {
+		if GetQuestionTypeName(questionType) == name {
+			return questionType, true
+		}
+	}
+	return 0, false
+}
+
+// CheckQuestionStatus 判断题目审核状态是否合法
+func CheckQuestionStatus(status int) bool {
+	switch status {
+	case QuestionStatusApproved, QuestionStatusPending, QuestionStatusRejected:
+		return true
+	}
+	return false
+}
+
+// CheckDuplicatePolicy 判断重复题目处理策略是否合法（空值表示使用默认的跳过策略）
+func CheckDuplicatePolicy(policy string) bool {
+	switch policy {
+	case "", DuplicatePolicySkip, DuplicatePolicyFlag, DuplicatePolicyMerge:
+		return true
+	}
+	return false
+}
+
+// AI补充题目解析建议的状态 0=待处理 1=已采纳 2=已忽略
+const (
+	QuestionSuggestionStatusPending = iota
+	QuestionSuggestionStatusAccepted
+	QuestionSuggestionStatusIgnored
+)
+
+// 采纳AI建议时可写入题目的字段
+const (
+	QuestionSuggestionFieldAnalysis = "analysis" // 答案解析
+	QuestionSuggestionFieldRemark   = "remark"   // 题目备注（难度与考察要点）
+)
+
+// QuestionDifficulties AI建议的难度取值
+var QuestionDifficulties = []string{"简单", "中等", "困难"}
+
+// AI补充解析的数量限制：单次最多QuestionEnrichMaxCount道题，每QuestionEnrichBatchSize道题调用一次大模型，
+// 最多QuestionEnrichConcurrency个批次并发调用
+const (
+	QuestionEnrichDefaultCount = 10
+	QuestionEnrichMaxCount     = 20
+	QuestionEnrichBatchSize    = 5
+	QuestionEnrichConcurrency  = 3
+	QuestionKeyPointMaxCount   = 5
+)
+
+// CheckQuestionSuggestionStatus 判断建议状态是否合法
+func CheckQuestionSuggestionStatus(status int) bool {
+	switch status {
+	case QuestionSuggestionStatusPending, QuestionSuggestionStatusAccepted, QuestionSuggestionStatusIgnored:
+		return true
+	}
+	return false
+}
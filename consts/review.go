@@ -0,0 +1,46 @@
+package consts
+
+// 复习自评等级 0=忘记(again) 1=困难(hard) 2=良好(good) 3=简单(easy)
+const (
+	ReviewRatingAgain = iota
+	ReviewRatingHard
+	ReviewRatingGood
+	ReviewRatingEasy
+)
+
+// SM-2 算法参数
+const (
+	ReviewInitialEaseFactor = 2.5 // 初始难度系数
+	ReviewMinEaseFactor     = 1.3 // 最小难度系数
+)
+
+// 复习计划默认配置
+const (
+	ReviewDefaultDailyLimit  = 50  // 每日默认复习上限
+	ReviewMaxDailyLimit      = 500 // 每日复习上限的最大值
+	ReviewDefaultForecastDay = 7   // 默认预测天数
+	ReviewMaxForecastDay     = 60  // 最大预测天数
+)
+
+func CheckReviewRating(rating int) bool {
+	switch rating {
+	case ReviewRatingAgain, ReviewRatingHard, ReviewRatingGood, ReviewRatingEasy:
+		return true
+	}
+	return false
+}
+
+// GetReviewQuality 将自评等级映射为 SM-2 的回忆质量（0-5）
+func GetReviewQuality(rating int) int {
+	switch rating {
+	case ReviewRatingAgain:
+		return 1
+	case ReviewRatingHard:
+		return 3
+	case ReviewRatingGood:
+		return 4
+	case ReviewRatingEasy:
+		return 5
+	}
+	return 0
+}
@@ -1,13 +1,22 @@
 package consts
 
+import "sync"
+
 // PrimaryTag 一级分类结构体
 type PrimaryTag struct {
-	Name      string   `json:"name"`       // 一级分类名称
-	SecondTag []string `json:"second_tag"` // 该一级分类下的二级分类列表
+	Name      string    `json:"name"`               // 一级分类名称
+	SecondTag []string  `json:"second_tag"`         // 该一级分类下的全部下级分类（各层级平铺）
+	Children  []TagNode `json:"children,omitempty"` // 该一级分类下的分类树
+}
+
+// TagNode 一级分类下的多层级分类节点
+type TagNode struct {
+	Name     string    `json:"name"`
+	Children []TagNode `json:"children,omitempty"`
 }
 
-// KnowledgeTree 全部知识体系数据（硬编码在此文件，全局可访问）
-var KnowledgeTree = []PrimaryTag{
+// DefaultKnowledgeTree 默认知识体系数据，仅用于首次启动时初始化标签表
+var DefaultKnowledgeTree = []PrimaryTag{
 	{
 		Name: "算法",
 		SecondTag: []string{
@@ -40,9 +49,46 @@ var KnowledgeTree = []PrimaryTag{
 	},
 }
 
+// 分类字段长度限制（按字符数计）
+const (
+	TagPrimaryNameMaxLen   = 50
+	TagSecondaryNameMaxLen = 100
+	TagDescriptionMaxLen   = 500
+)
+
+var (
+	// knowledgeTree 知识体系内存缓存（启动时及标签变更后从数据库刷新）
+	knowledgeTree   = DefaultKnowledgeTree
+	knowledgeTreeMu sync.RWMutex
+)
+
+// GetKnowledgeTree 获取当前知识体系（只读，调用方不要修改返回值）
+func GetKnowledgeTree() []PrimaryTag {
+	knowledgeTreeMu.RLock()
+	defer knowledgeTreeMu.RUnlock()
+	return knowledgeTree
+}
+
+// SetKnowledgeTree 替换知识体系缓存
+func SetKnowledgeTree(tree []PrimaryTag) {
+	knowledgeTreeMu.Lock()
+	defer knowledgeTreeMu.Unlock()
+	knowledgeTree = tree
+}
+
+// GetPrimaryTagNames 获取全部一级分类名称
+func GetPrimaryTagNames() []string {
+	tree := GetKnowledgeTree()
+	names := make([]string, 0, len(tree))
+	for _, pt := range tree {
+		names = append(names, pt.Name)
+	}
+	return names
+}
+
 // IsValidPrimaryTag 判断一级 tag 是否合法
 func IsValidPrimaryTag(tag string) bool {
-	for _, pt := range KnowledgeTree {
+	for _, pt := range GetKnowledgeTree() {
 		if pt.Name == tag {
 			return true
 		}
@@ -52,7 +98,7 @@ func IsValidPrimaryTag(tag string) bool {
 
 // IsValidSecondaryTag 判断二级 tag 是否合法（在整个知识体系中是否存在）
 func IsValidSecondaryTag(tag string) bool {
-	for _, pt := range KnowledgeTree {
+	for _, pt := range GetKnowledgeTree() {
 		for _, st := range pt.SecondTag {
 			if st == tag {
 				return true
@@ -64,7 +110,7 @@ func IsValidSecondaryTag(tag string) bool {
 
 // IsSecondaryOfPrimary 判断某二级 tag 是否属于某一级 tag
 func IsSecondaryOfPrimary(primary, secondary string) bool {
-	for _, pt := range KnowledgeTree {
+	for _, pt := range GetKnowledgeTree() {
 		if pt.Name == primary {
 			for _, st := range pt.SecondTag {
 				if st == secondary {
@@ -76,3 +122,45 @@ func IsSecondaryOfPrimary(primary, secondary string) bool {
 	}
 	return false // 一级分类不存在
 }
+
+// GetTagDescendants 获取一级分类下某分类及其全部下级分类的名称（分类不存在时返回nil）
+func GetTagDescendants(primary, secondary string) []string {
+	for _, pt := range GetKnowledgeTree() {
+		if pt.Name != primary {
+			continue
+		}
+		if node := findTagNode(pt.Children, secondary); node != nil {
+			return collectTagNames(*node, nil)
+		}
+		// 未构建分类树时（如默认知识体系），二级分类没有下级
+		for _, st := range pt.SecondTag {
+			if st == secondary {
+				return []string{secondary}
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// findTagNode 在分类树中查找指定名称的节点
+func findTagNode(nodes []TagNode, name string) *TagNode {
+	for i := range nodes {
+		if nodes[i].Name == name {
+			return &nodes[i]
+		}
+		if node := findTagNode(nodes[i].Children, name); node != nil {
+			return node
+		}
+	}
+	return nil
+}
+
+// collectTagNames 收集节点及其全部下级节点的名称
+func collectTagNames(node TagNode, names []string) []string {
+	names = append(names, node.Name)
+	for _, child := range node.Children {
+		names = collectTagNames(child, names)
+	}
+	return names
+}
@@ -0,0 +1,81 @@
+package consts
+
+import "time"
+
+// 登录态相关配置
+const (
+	UserTokenCookieName  = "exam_token"       // 登录态Cookie名称
+	UserTokenHeaderName  = "Authorization"    // 登录态请求头名称（Bearer Token）
+	UserTokenExpire      = 7 * 24 * time.Hour // 登录态有效期
+	ContextKeyUserID     = "user_id"          // gin.Context中保存当前用户ID的key
+	ContextKeyUser       = "user"             // gin.Context中保存当前用户的key
+	UserPasswordMinLen   = 6
+	UserPasswordMaxLen   = 64
+	UserUsernameMinLen   = 3
+	UserUsernameMaxLen   = 32
+	UserNicknameMaxLen   = 50
+	UserTokenRandomBytes = 32
+)
+
+// 用户角色：admin=管理员 editor=编辑 learner=学员
+const (
+	UserRoleAdmin   = "admin"
+	UserRoleEditor  = "editor"
+	UserRoleLearner = "learner"
+)
+
+// 权限点
+const (
+	PermissionPractice          = "practice"           // 练习、收藏、错题、复习
+	PermissionQuestionEdit      = "question:edit"      // 新增/更新/导入/AI生成题目、按条件导出
+	PermissionQuestionDelete    = "question:delete"    // 删除题目
+	PermissionQuestionReview    = "question:review"    // 审核AI生成的题目草稿
+	PermissionQuestionExportAll = "question:exportAll" // 导出全部题库
+	PermissionUserManage        = "user:manage"        // 管理用户角色
+	PermissionTagManage         = "tag:manage"         // 管理知识树分类
+	PermissionPromptManage      = "prompt:manage"      // 管理AI生成题目的提示词模板
+	PermissionLLMUsageView      = "llm:usage"          // 查看大模型调用用量统计
+)
+
+// rolePermissions 角色拥有的权限
+var rolePermissions = map[string][]string{
+	UserRoleAdmin: {
+		PermissionPractice, PermissionQuestionEdit, PermissionQuestionDelete, PermissionQuestionReview,
+		PermissionQuestionExportAll, PermissionUserManage, PermissionTagManage, PermissionPromptManage,
+		PermissionLLMUsageView,
+	},
+	UserRoleEditor: {
+		PermissionPractice, PermissionQuestionEdit, PermissionQuestionReview,
+	},
+	UserRoleLearner: {
+		PermissionPractice,
+	},
+}
+
+// CheckUserRole 判断角色是否合法
+func CheckUserRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RoleHasPermission 判断角色是否拥有某权限
+func RoleHasPermission(role, permission string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func GetUserRoleName(role string) string {
+	switch role {
+	case UserRoleAdmin:
+		return "管理员"
+	case UserRoleEditor:
+		return "编辑"
+	case UserRoleLearner:
+		return "学员"
+	}
+	return "未知"
+}
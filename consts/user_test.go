@@ -0,0 +1,44 @@
+package consts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试RoleHasPermission按角色判断权限
+func TestRoleHasPermission(t *testing.T) {
+	allPermissions := []string{
+		PermissionPractice, PermissionQuestionEdit, PermissionQuestionDelete, PermissionQuestionReview,
+		PermissionQuestionExportAll, PermissionUserManage, PermissionTagManage, PermissionPromptManage,
+		PermissionLLMUsageView,
+	}
+	testCases := []struct {
+		role    string
+		allowed []string
+	}{
+		{UserRoleAdmin, allPermissions},
+		{UserRoleEditor, []string{PermissionPractice, PermissionQuestionEdit, PermissionQuestionReview}},
+		{UserRoleLearner, []string{PermissionPractice}},
+		{"guest", nil},
+		{"", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.role, func(t *testing.T) {
+			for _, permission := range allPermissions {
+				assert.Equal(t, contains(tc.allowed, permission), RoleHasPermission(tc.role, permission), permission)
+			}
+			assert.False(t, RoleHasPermission(tc.role, "unknown"))
+		})
+	}
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
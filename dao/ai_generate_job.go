@@ -0,0 +1,120 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+	"gorm.io/gorm"
+)
+
+// AIGenerateJobDao AI生成题目任务DAO
+type AIGenerateJobDao struct {
+	db *gorm.DB
+}
+
+// NewAIGenerateJobDao 创建AI生成题目任务DAO实例
+func NewAIGenerateJobDao(db *gorm.DB) *AIGenerateJobDao {
+	return &AIGenerateJobDao{
+		db: db,
+	}
+}
+
+// unfinishedJobStatuses 未结束的任务状态
+var unfinishedJobStatuses = []int{consts.AIGenerateJobStatusQueued, consts.AIGenerateJobStatusRunning}
+
+// CreateJob 创建任务
+func (d *AIGenerateJobDao) CreateJob(job *model.AIGenerateJob) error {
+	return d.db.Create(job).Error
+}
+
+// GetJobByID 根据ID获取任务
+func (d *AIGenerateJobDao) GetJobByID(id uint) (*model.AIGenerateJob, error) {
+	var job model.AIGenerateJob
+	if err := d.db.Where("id = ?", id).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetJobsByUser 分页获取用户提交的任务（按提交时间倒序）
+func (d *AIGenerateJobDao) GetJobsByUser(userID uint, page, size int) ([]*model.AIGenerateJob, int64, error) {
+	var jobs []*model.AIGenerateJob
+	var total int64
+	query := d.db.Model(&model.AIGenerateJob{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset((page - 1) * size).Limit(size).Find(&jobs).Error
+	return jobs, total, err
+}
+
+// StartJob 将排队中的任务标记为生成中，任务已被取消时返回false
+func (d *AIGenerateJobDao) StartJob(id uint, startedAt time.Time) (bool, error) {
+	result := d.db.Model(&model.AIGenerateJob{}).
+		Where("id = ? AND status = ?", id, consts.AIGenerateJobStatusQueued).
+		Updates(map[string]interface{}{
+			"status":     consts.AIGenerateJobStatusRunning,
+			"started_at": startedAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// SaveJobProgress 保存生成中任务的进度与结果，任务已不在生成中（如已取消）时返回false
+func (d *AIGenerateJobDao) SaveJobProgress(job *model.AIGenerateJob) (bool, error) {
+	result := d.db.Model(&model.AIGenerateJob{}).
+		Where("id = ? AND status = ?", job.ID, consts.AIGenerateJobStatusRunning).
+		Updates(jobProgressColumns(job))
+	return result.RowsAffected > 0, result.Error
+}
+
+// jobProgressColumns 任务进度与结果对应的更新字段
+func jobProgressColumns(job *model.AIGenerateJob) map[string]interface{} {
+	return map[string]interface{}{
+		"finished_chunks": job.FinishedChunks,
+		"failed_chunks":   job.FailedChunks,
+		"question_ids":    job.QuestionIDs,
+		"rejections":      job.Rejections,
+	}
+}
+
+// SaveCanceledJobProgress 保存已取消任务的最终进度与结果（取消时正在入库的批次完成后记录其题目）
+func (d *AIGenerateJobDao) SaveCanceledJobProgress(job *model.AIGenerateJob) error {
+	return d.db.Model(&model.AIGenerateJob{}).
+		Where("id = ? AND status = ?", job.ID, consts.AIGenerateJobStatusCanceled).
+		Updates(jobProgressColumns(job)).Error
+}
+
+// FinishJob 结束生成中的任务，status为完成或失败
+func (d *AIGenerateJobDao) FinishJob(id uint, status int, errorMsg string, finishedAt time.Time) error {
+	return d.db.Model(&model.AIGenerateJob{}).
+		Where("id = ? AND status = ?", id, consts.AIGenerateJobStatusRunning).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"error_msg":   errorMsg,
+			"finished_at": finishedAt,
+		}).Error
+}
+
+// CancelJob 取消排队中或生成中的任务，任务已结束时返回false
+func (d *AIGenerateJobDao) CancelJob(id uint, finishedAt time.Time) (bool, error) {
+	result := d.db.Model(&model.AIGenerateJob{}).
+		Where("id = ? AND status IN ?", id, unfinishedJobStatuses).
+		Updates(map[string]interface{}{
+			"status":      consts.AIGenerateJobStatusCanceled,
+			"finished_at": finishedAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// FailUnfinishedJobs 将全部未结束的任务标记为失败（服务重启后任务无法继续执行），返回更新的数量
+func (d *AIGenerateJobDao) FailUnfinishedJobs(errorMsg string, finishedAt time.Time) (int64, error) {
+	result := d.db.Model(&model.AIGenerateJob{}).
+		Where("status IN ?", unfinishedJobStatuses).
+		Updates(map[string]interface{}{
+			"status":      consts.AIGenerateJobStatusFailed,
+			"error_msg":   errorMsg,
+			"finished_at": finishedAt,
+		})
+	return result.RowsAffected, result.Error
+}
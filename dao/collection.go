@@ -23,27 +23,27 @@ func (d *CollectionDao) CreateCollection(collection *model.ExamQuestionCollectio
 }
 
 // DeleteCollection 删除收藏
-func (d *CollectionDao) DeleteCollection(questionID uint) error {
-	return d.db.Where("question_id = ?", questionID).Delete(&model.ExamQuestionCollection{}).Error
+func (d *CollectionDao) DeleteCollection(userID, questionID uint) error {
+	return d.db.Where("user_id = ? AND question_id = ?", userID, questionID).Delete(&model.ExamQuestionCollection{}).Error
 }
 
-// GetCollectionByQuestionID 根据题目ID获取收藏
-func (d *CollectionDao) GetCollectionByQuestionID(questionID uint) (*model.ExamQuestionCollection, error) {
+// GetCollectionByQuestionID 根据题目ID获取用户的收藏
+func (d *CollectionDao) GetCollectionByQuestionID(userID, questionID uint) (*model.ExamQuestionCollection, error) {
 	var collection model.ExamQuestionCollection
-	err := d.db.Where("question_id = ?", questionID).First(&collection).Error
+	err := d.db.Where("user_id = ? AND question_id = ?", userID, questionID).First(&collection).Error
 	if err != nil {
 		return nil, err
 	}
 	return &collection, nil
 }
 
-// GetCollectionList 获取收藏列表
-func (d *CollectionDao) GetCollectionList(tag, secondTag string, page, size int) ([]*model.ExamQuestionCollection, int64, error) {
+// GetCollectionList 获取用户的收藏列表
+func (d *CollectionDao) GetCollectionList(userID uint, tag, secondTag string, page, size int) ([]*model.ExamQuestionCollection, int64, error) {
 	var collections []*model.ExamQuestionCollection
 	var total int64
-	
-	query := d.db.Model(&model.ExamQuestionCollection{})
-	
+
+	query := d.db.Model(&model.ExamQuestionCollection{}).Where("user_id = ?", userID)
+
 	// 筛选条件
 	if tag != "" {
 		query = query.Where("tag = ?", tag)
@@ -51,35 +51,42 @@ func (d *CollectionDao) GetCollectionList(tag, secondTag string, page, size int)
 	if secondTag != "" {
 		query = query.Where("second_tag = ?", secondTag)
 	}
-	
+
 	// 获取总数
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
-	
+
 	// 分页查询
 	offset := (page - 1) * size
 	err := query.Preload("Question").Offset(offset).Limit(size).Order("created_at DESC").Find(&collections).Error
 	if err != nil {
 		return nil, 0, err
 	}
-	
+
 	return collections, total, nil
 }
 
-// BatchGetCollectionStatus 批量获取题目收藏状态
-func (d *CollectionDao) BatchGetCollectionStatus(questionIDs []uint) (map[uint]bool, error) {
+// BatchGetCollectionStatus 批量获取用户的题目收藏状态
+func (d *CollectionDao) BatchGetCollectionStatus(userID uint, questionIDs []uint) (map[uint]bool, error) {
 	var collections []*model.ExamQuestionCollection
-	err := d.db.Where("question_id IN ?", questionIDs).Find(&collections).Error
+	err := d.db.Where("user_id = ? AND question_id IN ?", userID, questionIDs).Find(&collections).Error
 	if err != nil {
 		return nil, err
 	}
-	
+
 	// 构建结果映射
 	result := make(map[uint]bool)
 	for _, collection := range collections {
 		result[collection.QuestionID] = true
 	}
-	
+
 	return result, nil
 }
+
+// CountByUserID 统计用户的收藏数量
+func (d *CollectionDao) CountByUserID(userID uint) (int64, error) {
+	var total int64
+	err := d.db.Model(&model.ExamQuestionCollection{}).Where("user_id = ?", userID).Count(&total).Error
+	return total, err
+}
@@ -0,0 +1,137 @@
+package dao
+
+import (
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+	"gorm.io/gorm"
+)
+
+// ExamSessionDao 考试会话DAO
+type ExamSessionDao struct {
+	db *gorm.DB
+}
+
+// NewExamSessionDao 创建考试会话DAO实例
+func NewExamSessionDao(db *gorm.DB) *ExamSessionDao {
+	return &ExamSessionDao{
+		db: db,
+	}
+}
+
+// CreateSession 创建考试会话
+func (d *ExamSessionDao) CreateSession(session *model.ExamSession) error {
+	return d.db.Create(session).Error
+}
+
+// CreateAnswers 批量创建会话作答记录
+func (d *ExamSessionDao) CreateAnswers(answers []*model.ExamSessionAnswer) error {
+	if len(answers) == 0 {
+		return nil
+	}
+	return d.db.CreateInBatches(answers, 100).Error
+}
+
+// GetSessionByID 根据ID获取考试会话
+func (d *ExamSessionDao) GetSessionByID(id uint) (*model.ExamSession, error) {
+	var session model.ExamSession
+	if err := d.db.Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetAnswersBySessionID 获取会话的全部作答记录（含题目，按顺序）
+func (d *ExamSessionDao) GetAnswersBySessionID(sessionID uint) ([]*model.ExamSessionAnswer, error) {
+	var answers []*model.ExamSessionAnswer
+	err := d.db.Preload("Question").Where("session_id = ?", sessionID).Order("seq ASC").Find(&answers).Error
+	return answers, err
+}
+
+// UpdateAnswer 更新单题作答记录
+func (d *ExamSessionDao) UpdateAnswer(answer *model.ExamSessionAnswer) error {
+	return d.db.Model(&model.ExamSessionAnswer{}).Where("id = ?", answer.ID).
+		Select("user_answer", "result", "blank_results", "answered_at").Updates(answer).Error
+}
+
+// UpdateAnswerAIGrade 更新作答记录的AI评分结果
+func (d *ExamSessionDao) UpdateAnswerAIGrade(answer *model.ExamSessionAnswer) error {
+	return d.db.Model(&model.ExamSessionAnswer{}).Where("id = ?", answer.ID).
+		Select("ai_grade", "ai_graded_at").Updates(answer).Error
+}
+
+// GetSessionList 分页获取用户的考试会话（练习记录），按创建时间倒序
+func (d *ExamSessionDao) GetSessionList(userID uint, page, size int) ([]*model.ExamSession, int64, error) {
+	var sessions []*model.ExamSession
+	var total int64
+
+	query := d.db.Model(&model.ExamSession{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Offset(offset).Limit(size).Order("id DESC").Find(&sessions).Error; err != nil {
+		return nil, 0, err
+	}
+	return sessions, total, nil
+}
+
+// SessionStatistics 用户练习汇总
+type SessionStatistics struct {
+	SessionCount  int64 `json:"session_count"`  // 练习次数
+	FinishedCount int64 `json:"finished_count"` // 已交卷次数
+	CorrectCount  int64 `json:"correct_count"`  // 累计答对题数
+	WrongCount    int64 `json:"wrong_count"`    // 累计答错题数（含未作答）
+	PendingCount  int64 `json:"pending_count"`  // 待评阅题数
+}
+
+// GetSessionStatistics 汇总用户的练习数据
+func (d *ExamSessionDao) GetSessionStatistics(userID uint) (*SessionStatistics, error) {
+	var stats SessionStatistics
+	err := d.db.Model(&model.ExamSession{}).
+		Select("COUNT(*) AS session_count, "+
+			"COALESCE(SUM(status = ?), 0) AS finished_count, "+
+			"COALESCE(SUM(correct_count), 0) AS correct_count, "+
+			"COALESCE(SUM(wrong_count), 0) AS wrong_count, "+
+			"COALESCE(SUM(pending_count), 0) AS pending_count", consts.ExamSessionStatusFinished).
+		Where("user_id = ?", userID).Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// QuestionTypeAnswerStatistics 用户按题型的作答汇总
+type QuestionTypeAnswerStatistics struct {
+	QuestionType  int   `json:"question_type"`
+	AnsweredCount int64 `json:"answered_count"` // 已作答题数
+	CorrectCount  int64 `json:"correct_count"`  // 答对题数
+	WrongCount    int64 `json:"wrong_count"`    // 答错题数（含未作答）
+	PendingCount  int64 `json:"pending_count"`  // 待评阅题数
+}
+
+// GetAnswerStatisticsByType 按题型汇总用户已交卷考试的作答情况
+func (d *ExamSessionDao) GetAnswerStatisticsByType(userID uint) ([]*QuestionTypeAnswerStatistics, error) {
+	var stats []*QuestionTypeAnswerStatistics
+	err := d.db.Table("exam_session_answer AS a").
+		Joins("JOIN exam_session AS s ON s.id = a.session_id").
+		Joins("JOIN exam_questions AS q ON q.id = a.question_id").
+		Select("q.question_type AS question_type, "+
+			"COALESCE(SUM(a.answered_at IS NOT NULL), 0) AS answered_count, "+
+			"COALESCE(SUM(a.result = ?), 0) AS correct_count, "+
+			"COALESCE(SUM(a.result IN ?), 0) AS wrong_count, "+
+			"COALESCE(SUM(a.result = ?), 0) AS pending_count",
+			consts.AnswerResultCorrect,
+			[]int{consts.AnswerResultWrong, consts.AnswerResultUnanswered},
+			consts.AnswerResultPendingReview).
+		Where("s.user_id = ? AND s.status = ?", userID, consts.ExamSessionStatusFinished).
+		Group("q.question_type").Order("q.question_type ASC").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// UpdateSession 更新考试会话的状态与成绩
+func (d *ExamSessionDao) UpdateSession(session *model.ExamSession) error {
+	return d.db.Model(&model.ExamSession{}).Where("id = ?", session.ID).
+		Select("status", "correct_count", "wrong_count", "pending_count", "score", "finished_at").Updates(session).Error
+}
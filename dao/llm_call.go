@@ -0,0 +1,72 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+	"gorm.io/gorm"
+)
+
+// LLMCallDao 大模型调用记录DAO
+type LLMCallDao struct {
+	db *gorm.DB
+}
+
+// NewLLMCallDao 创建大模型调用记录DAO实例
+func NewLLMCallDao(db *gorm.DB) *LLMCallDao {
+	return &LLMCallDao{
+		db: db,
+	}
+}
+
+// CreateCall 新增调用记录
+func (d *LLMCallDao) CreateCall(call *model.ExamLLMCall) error {
+	return d.db.Create(call).Error
+}
+
+// SetCallQuestionIDs 记录调用产生或处理的题目ID
+func (d *LLMCallDao) SetCallQuestionIDs(id uint, questionIDs []uint) error {
+	return d.db.Model(&model.ExamLLMCall{}).Where("id = ?", id).
+		Update("question_ids", model.UintList(questionIDs)).Error
+}
+
+// SumTokensSince 统计since之后的token用量（输入+输出），provider为空时统计全部服务
+func (d *LLMCallDao) SumTokensSince(provider string, since time.Time) (int64, error) {
+	var total int64
+	query := d.db.Model(&model.ExamLLMCall{}).Where("created_at >= ?", since)
+	if provider != "" {
+		query = query.Where("provider = ?", provider)
+	}
+	err := query.Select("COALESCE(SUM(prompt_tokens + completion_tokens), 0)").Scan(&total).Error
+	return total, err
+}
+
+// LLMCallDailyStatistics 按天、按服务的调用汇总
+type LLMCallDailyStatistics struct {
+	Day              string  `json:"day"` // 日期，如2026-10-17
+	Provider         string  `json:"provider"`
+	CallCount        int64   `json:"call_count"`
+	FailedCount      int64   `json:"failed_count"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	Cost             float64 `json:"cost"`
+	AvgLatencyMs     int64   `json:"avg_latency_ms"`
+}
+
+// GetDailyStatistics 按天、按服务汇总[start, end)内的调用记录
+func (d *LLMCallDao) GetDailyStatistics(start, end time.Time) ([]*LLMCallDailyStatistics, error) {
+	var stats []*LLMCallDailyStatistics
+	err := d.db.Model(&model.ExamLLMCall{}).
+		Select("DATE_FORMAT(created_at, '%Y-%m-%d') AS day, provider, "+
+			"COUNT(*) AS call_count, "+
+			"COALESCE(SUM(status = ?), 0) AS failed_count, "+
+			"COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens, "+
+			"COALESCE(SUM(completion_tokens), 0) AS completion_tokens, "+
+			"COALESCE(SUM(cost), 0) AS cost, "+
+			"CAST(COALESCE(AVG(latency_ms), 0) AS SIGNED) AS avg_latency_ms", consts.LLMCallStatusFailed).
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Group("day, provider").Order("day ASC, provider ASC").
+		Scan(&stats).Error
+	return stats, err
+}
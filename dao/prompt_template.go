@@ -0,0 +1,72 @@
+package dao
+
+import (
+	"github.com/vaynedu/exam_system/model"
+	"gorm.io/gorm"
+)
+
+// PromptTemplateDao 提示词模板DAO
+type PromptTemplateDao struct {
+	db *gorm.DB
+}
+
+// NewPromptTemplateDao 创建提示词模板DAO实例
+func NewPromptTemplateDao(db *gorm.DB) *PromptTemplateDao {
+	return &PromptTemplateDao{
+		db: db,
+	}
+}
+
+// CreateTemplate 新增模板版本
+func (d *PromptTemplateDao) CreateTemplate(tpl *model.ExamPromptTemplate) error {
+	return d.db.Create(tpl).Error
+}
+
+// GetLatestTemplate 获取模板的最新版本（含已停用的模板），不存在时返回gorm.ErrRecordNotFound
+func (d *PromptTemplateDao) GetLatestTemplate(name string) (*model.ExamPromptTemplate, error) {
+	var tpl model.ExamPromptTemplate
+	if err := d.db.Where("name = ?", name).Order("version DESC").First(&tpl).Error; err != nil {
+		return nil, err
+	}
+	return &tpl, nil
+}
+
+// GetTemplateVersion 获取模板的指定版本
+func (d *PromptTemplateDao) GetTemplateVersion(name string, version int) (*model.ExamPromptTemplate, error) {
+	var tpl model.ExamPromptTemplate
+	if err := d.db.Where("name = ? AND version = ?", name, version).First(&tpl).Error; err != nil {
+		return nil, err
+	}
+	return &tpl, nil
+}
+
+// GetTemplateByID 根据版本ID获取模板
+func (d *PromptTemplateDao) GetTemplateByID(id uint) (*model.ExamPromptTemplate, error) {
+	var tpl model.ExamPromptTemplate
+	if err := d.db.Where("id = ?", id).First(&tpl).Error; err != nil {
+		return nil, err
+	}
+	return &tpl, nil
+}
+
+// GetTemplateVersions 获取模板的全部版本（版本号倒序）
+func (d *PromptTemplateDao) GetTemplateVersions(name string) ([]*model.ExamPromptTemplate, error) {
+	var tpls []*model.ExamPromptTemplate
+	err := d.db.Where("name = ?", name).Order("version DESC").Find(&tpls).Error
+	return tpls, err
+}
+
+// GetLatestEnabledTemplates 获取全部启用模板的最新版本（按名称排序）
+func (d *PromptTemplateDao) GetLatestEnabledTemplates() ([]*model.ExamPromptTemplate, error) {
+	var tpls []*model.ExamPromptTemplate
+	latest := d.db.Session(&gorm.Session{NewDB: true}).Model(&model.ExamPromptTemplate{}).
+		Select("MAX(id)").Group("name")
+	err := d.db.Where("enabled = ? AND id IN (?)", true, latest).Order("name ASC").Find(&tpls).Error
+	return tpls, err
+}
+
+// DisableTemplate 停用模板的全部版本
+func (d *PromptTemplateDao) DisableTemplate(name string) (int64, error) {
+	result := d.db.Model(&model.ExamPromptTemplate{}).Where("name = ?", name).Update("enabled", false)
+	return result.RowsAffected, result.Error
+}
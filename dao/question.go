@@ -2,9 +2,12 @@ package dao
 
 import (
 	"strconv"
+	"time"
 
+	"github.com/vaynedu/exam_system/consts"
 	"github.com/vaynedu/exam_system/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type QuestionDao struct {
@@ -17,45 +20,91 @@ func NewQuestionDao(db *gorm.DB) *QuestionDao {
 	}
 }
 
-// CreateQuestion 创建题目
+// CreateQuestion 创建题目（同时写入题目-分类关联）
 func (q *QuestionDao) CreateQuestion(question *model.ExamQuestion) error {
+	question.SyncOptions()
+	withMainTag(question)
 	return q.db.Omit("CreatedAt").Create(&question).Error
 }
 
-// CreateQuestionsInBatches 批量创建题目
+// CreateQuestionsInBatches 批量创建题目（同时写入题目-分类关联）
 func (q *QuestionDao) CreateQuestionsInBatches(questions []*model.ExamQuestion, batchSize int) error {
+	for _, question := range questions {
+		question.SyncOptions()
+		withMainTag(question)
+	}
 	return q.db.CreateInBatches(questions, batchSize).Error
 }
 
 // GetRandomQuestions 随机获取指定数量的题目
 func (q *QuestionDao) GetRandomQuestions(limit int) ([]model.ExamQuestion, error) {
 	var questions []model.ExamQuestion
-	err := q.db.Order("RAND()").Limit(limit).Find(&questions).Error
+	err := q.db.Scopes(ApprovedQuestions).Order("RAND()").Limit(limit).Find(&questions).Error
 	return questions, err
 }
 
-// GetRandomQuestionsByTag 根据标签随机获取指定数量的题目
-func (q *QuestionDao) GetRandomQuestionsByTag(tag, secondTag string, limit int) ([]model.ExamQuestion, error) {
+// GetRandomQuestionsByTags 根据分类随机获取指定数量的题目
+func (q *QuestionDao) GetRandomQuestionsByTags(filter QuestionTagFilter, limit int) ([]model.ExamQuestion, error) {
 	var questions []model.ExamQuestion
 	// TODO mysql的随机依靠排序，性能很差，需要从业务层去控制
-	query := q.db.Order("RAND()").Limit(limit)
+	query := q.db.Model(&model.ExamQuestion{}).Scopes(ApprovedQuestions).Order("RAND()").Limit(limit)
+	query = q.WithTagFilter(query, filter)
+
+	err := query.Find(&questions).Error
+	return questions, err
+}
+
+// GetRandomQuestionsByCondition 根据分类和题型随机获取指定数量的题目，questionType<0表示不限题型
+func (q *QuestionDao) GetRandomQuestionsByCondition(filter QuestionTagFilter, questionType int, limit int) ([]model.ExamQuestion, error) {
+	var questions []model.ExamQuestion
+	query := q.db.Model(&model.ExamQuestion{}).Scopes(ApprovedQuestions).Order("RAND()").Limit(limit)
+	query = q.WithTagFilter(query, filter)
 
-	// 如果指定了标签，则添加标签过滤条件
-	if tag != "" && secondTag != "" {
-		query = query.Where("tag = ? AND second_tag = ?", tag, secondTag)
-	} else if tag != "" {
-		query = query.Where("tag = ?", tag)
-	} else if secondTag != "" {
-		query = query.Where("second_tag = ?", secondTag)
+	if questionType >= 0 {
+		query = query.Where("question_type = ?", questionType)
 	}
 
 	err := query.Find(&questions).Error
 	return questions, err
 }
 
-// UpdateQuestion 更新题目
+// UpdateQuestion 更新题目（不更新分类关联、审核状态及来源信息，分类关联使用ReplaceQuestionTags，审核状态使用ReviewQuestions）
 func (q *QuestionDao) UpdateQuestion(question *model.ExamQuestion) error {
-	return q.db.Model(&model.ExamQuestion{}).Where("id = ?", question.ID).Updates(question).Error
+	question.SyncOptions()
+	return q.db.Model(&model.ExamQuestion{}).Omit(clause.Associations).
+		Omit("status", "reviewed_by", "reviewed_at", "reject_reason", "duplicate_of", "prompt_template_id").
+		Where("id = ?", question.ID).Updates(question).Error
+}
+
+// UpdateQuestionColumns 更新题目的指定字段（如采纳AI建议时写入答案解析、备注）
+func (q *QuestionDao) UpdateQuestionColumns(id uint, columns map[string]interface{}) error {
+	return q.db.Model(&model.ExamQuestion{}).Where("id = ?", id).Updates(columns).Error
+}
+
+// ReviewQuestions 审核题目：将ids中处于fromStatus状态的题目改为status，记录审核人与时间，返回实际更新的数量
+func (q *QuestionDao) ReviewQuestions(ids []uint, fromStatus, status int, reviewerID uint, rejectReason string, reviewedAt time.Time) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := q.db.Model(&model.ExamQuestion{}).
+		Where("id IN ? AND status = ?", ids, fromStatus).
+		Updates(map[string]interface{}{
+			"status":        status,
+			"reviewed_by":   reviewerID,
+			"reviewed_at":   reviewedAt,
+			"reject_reason": rejectReason,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// GetQuestionIDsByStatus 获取ids中处于指定审核状态的题目ID
+func (q *QuestionDao) GetQuestionIDsByStatus(ids []uint, status int) ([]uint, error) {
+	var result []uint
+	if len(ids) == 0 {
+		return result, nil
+	}
+	err := q.db.Model(&model.ExamQuestion{}).Where("id IN ? AND status = ?", ids, status).Pluck("id", &result).Error
+	return result, err
 }
 
 // DeleteQuestion 删除题目
@@ -63,47 +112,268 @@ func (q *QuestionDao) DeleteQuestion(id uint) error {
 	return q.db.Delete(&model.ExamQuestion{}, id).Error
 }
 
-// GetAllQuestions 获取全部题目
-func (q *QuestionDao) GetAllQuestions() ([]model.ExamQuestion, error) {
+// GetQuestionsByIDList 根据ID列表获取题目
+func (q *QuestionDao) GetQuestionsByIDList(ids []uint) ([]model.ExamQuestion, error) {
 	var questions []model.ExamQuestion
-	err := q.db.Find(&questions).Error
+	if len(ids) == 0 {
+		return questions, nil
+	}
+	err := q.db.Where("id IN ?", ids).Find(&questions).Error
 	return questions, err
 }
 
-// GetQuestionsByIDList 根据ID列表获取题目
-func (q *QuestionDao) GetQuestionsByIDList(ids []uint) ([]model.ExamQuestion, error) {
+// GetApprovedQuestionsByIDList 根据ID列表获取已通过审核的题目（未审核、已驳回的题目不返回）
+func (q *QuestionDao) GetApprovedQuestionsByIDList(ids []uint) ([]model.ExamQuestion, error) {
 	var questions []model.ExamQuestion
 	if len(ids) == 0 {
 		return questions, nil
 	}
-	err := q.db.Where("id IN ?", ids).Find(&questions).Error
+	err := q.db.Scopes(ApprovedQuestions).Where("id IN ?", ids).Find(&questions).Error
 	return questions, err
 }
 
-// GetQuestionsByFilter 根据筛选条件获取题目
-func (q *QuestionDao) GetQuestionsByFilter(tag, secondTag, questionType, keyword string) ([]model.ExamQuestion, error) {
+// GetQuestionsByExternalIDs 根据外部题目编号列表获取题目（不限审核状态）
+func (q *QuestionDao) GetQuestionsByExternalIDs(externalIDs []string) ([]model.ExamQuestion, error) {
 	var questions []model.ExamQuestion
+	if len(externalIDs) == 0 {
+		return questions, nil
+	}
+	err := q.db.Where("external_id IN ?", externalIDs).Order("id ASC").Find(&questions).Error
+	return questions, err
+}
+
+// QuestionExportFilter 导出题目的范围：IDs不为空时导出指定题目，否则导出符合筛选条件（为空表示不限）的题目；
+// 只导出已通过审核的题目，IncludeUnapproved为true（有审核权限）时按ID导出的题目不限审核状态
+type QuestionExportFilter struct {
+	IDs               []uint
+	IncludeUnapproved bool
+	Tag               string
+	SecondTag         string
+	QuestionType      string
+	Keyword           string
+}
+
+// exportQuery 构造导出范围的查询
+func (q *QuestionDao) exportQuery(filter QuestionExportFilter) *gorm.DB {
 	query := q.db.Model(&model.ExamQuestion{})
+	if len(filter.IDs) > 0 {
+		query = query.Where("id IN ?", filter.IDs)
+		if !filter.IncludeUnapproved {
+			query = query.Scopes(ApprovedQuestions)
+		}
+		return query
+	}
 
-	// 添加筛选条件
-	if tag != "" {
-		query = query.Where("tag = ?", tag)
+	query = query.Scopes(ApprovedQuestions)
+	if filter.Tag != "" {
+		query = query.Where("tag = ?", filter.Tag)
 	}
-	if secondTag != "" {
-		query = query.Where("second_tag = ?", secondTag)
+	if filter.SecondTag != "" {
+		query = query.Where("second_tag = ?", filter.SecondTag)
 	}
-	if questionType != "" {
+	if filter.QuestionType != "" {
 		// 转换题型为数字
-		typeInt, err := strconv.Atoi(questionType)
+		typeInt, err := strconv.Atoi(filter.QuestionType)
 		if err == nil {
 			query = query.Where("question_type = ?", int8(typeInt))
 		}
 	}
-	if keyword != "" {
+	if filter.Keyword != "" {
 		// 在题干中搜索关键词
-		query = query.Where("question_title LIKE ?", "%"+keyword+"%")
+		query = query.Where("question_title LIKE ?", "%"+filter.Keyword+"%")
 	}
+	return query
+}
 
-	err := query.Find(&questions).Error
+// GetExportTags 获取导出范围内题目的一级分类（去重、升序，未分类为空串）
+func (q *QuestionDao) GetExportTags(filter QuestionExportFilter) ([]string, error) {
+	var tags []string
+	err := q.exportQuery(filter).Distinct("tag").Order("tag ASC").Pluck("tag", &tags).Error
+	return tags, err
+}
+
+// FindExportQuestionsInBatches 按ID升序分批读取导出范围内的题目，每批调用一次fn，fn返回错误时停止读取
+func (q *QuestionDao) FindExportQuestionsInBatches(filter QuestionExportFilter, batchSize int, fn func([]model.ExamQuestion) error) error {
+	var questions []model.ExamQuestion
+	return q.exportQuery(filter).FindInBatches(&questions, batchSize, func(_ *gorm.DB, _ int) error {
+		return fn(questions)
+	}).Error
+}
+
+// DedupeCandidateFilter 参与查重的题目范围（已通过及待审核，不含已驳回），各条件为空表示不限
+type DedupeCandidateFilter struct {
+	QuestionTypes []int8
+	Tags          []string // 一级分类（空串表示未分类）
+	Fingerprints  []string // 题干指纹，查找完全重复的题目
+}
+
+// dedupeCandidateQuery 按查重范围构建查询
+func (q *QuestionDao) dedupeCandidateQuery(filter DedupeCandidateFilter) *gorm.DB {
+	query := q.db.Model(&model.ExamQuestion{}).
+		Where("status IN ?", []int{consts.QuestionStatusApproved, consts.QuestionStatusPending})
+	if len(filter.QuestionTypes) > 0 {
+		query = query.Where("question_type IN ?", filter.QuestionTypes)
+	}
+	if filter.Tags != nil {
+		query = query.Where("tag IN ?", filter.Tags)
+	}
+	if filter.Fingerprints != nil {
+		query = query.Where("title_fingerprint IN ?", filter.Fingerprints)
+	}
+	return query
+}
+
+// GetDedupeCandidates 获取参与查重的题目，只查询查重和展示需要的字段，按ID升序
+func (q *QuestionDao) GetDedupeCandidates(filter DedupeCandidateFilter) ([]model.ExamQuestion, error) {
+	var questions []model.ExamQuestion
+	err := q.dedupeCandidateQuery(filter).
+		Select("id, question_type, question_title, title_fingerprint, tag, second_tag, status, upload_type, duplicate_of").
+		Order("id ASC").Find(&questions).Error
 	return questions, err
 }
+
+// CountDedupeCandidates 统计参与查重的题目数量
+func (q *QuestionDao) CountDedupeCandidates(filter DedupeCandidateFilter) (int64, error) {
+	var total int64
+	err := q.dedupeCandidateQuery(filter).Count(&total).Error
+	return total, err
+}
+
+// FindQuestionsWithoutFingerprintInBatches 按ID升序分批读取尚未计算题干指纹的题目（只查询ID与题干），每批调用一次fn
+func (q *QuestionDao) FindQuestionsWithoutFingerprintInBatches(batchSize int, fn func([]model.ExamQuestion) error) error {
+	var questions []model.ExamQuestion
+	return q.db.Model(&model.ExamQuestion{}).Select("id, question_title").Where("title_fingerprint = ''").
+		FindInBatches(&questions, batchSize, func(_ *gorm.DB, _ int) error {
+			return fn(questions)
+		}).Error
+}
+
+// SetTitleFingerprint 写入题目的题干指纹（不更新updated_at）
+func (q *QuestionDao) SetTitleFingerprint(id uint, fingerprint string) error {
+	return q.db.Model(&model.ExamQuestion{}).Where("id = ?", id).UpdateColumn("title_fingerprint", fingerprint).Error
+}
+
+// MergeDuplicateQuestion 将重复题目的内容合并到已有题目：只补充已有题目为空的答案解析和备注，并追加缺少的分类
+func (q *QuestionDao) MergeDuplicateQuestion(id uint, analysis, remark string, tags []*model.ExamQuestionTag) error {
+	if analysis != "" {
+		if err := q.db.Model(&model.ExamQuestion{}).Where("id = ? AND answer_analysis = ''", id).
+			Update("answer_analysis", analysis).Error; err != nil {
+			return err
+		}
+	}
+	if remark != "" {
+		if err := q.db.Model(&model.ExamQuestion{}).Where("id = ? AND question_remark = ''", id).
+			Update("question_remark", remark).Error; err != nil {
+			return err
+		}
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	newTags := make([]*model.ExamQuestionTag, 0, len(tags))
+	for _, tag := range tags {
+		newTags = append(newTags, &model.ExamQuestionTag{QuestionID: id, Tag: tag.Tag, SecondTag: tag.SecondTag})
+	}
+	// 已存在的分类由唯一索引uk_question_tag忽略
+	return q.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&newTags).Error
+}
+
+// QuestionTypeCount 题型题目数量
+type QuestionTypeCount struct {
+	QuestionType int   `json:"question_type"`
+	Count        int64 `json:"count"`
+}
+
+// CountByQuestionType 按题型统计已通过审核的题目数量
+func (q *QuestionDao) CountByQuestionType() ([]*QuestionTypeCount, error) {
+	var counts []*QuestionTypeCount
+	err := q.db.Model(&model.ExamQuestion{}).Scopes(ApprovedQuestions).
+		Select("question_type, COUNT(*) AS count").
+		Group("question_type").Order("question_type ASC").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// GetQuestionTags 获取题目的全部分类
+func (q *QuestionDao) GetQuestionTags(questionID uint) ([]*model.ExamQuestionTag, error) {
+	var tags []*model.ExamQuestionTag
+	err := q.db.Where("question_id = ?", questionID).Order("id ASC").Find(&tags).Error
+	return tags, err
+}
+
+// ReplaceQuestionTags 用新的分类列表替换题目的全部分类
+func (q *QuestionDao) ReplaceQuestionTags(questionID uint, tags []*model.ExamQuestionTag) error {
+	if err := q.DeleteQuestionTags(questionID); err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	for _, tag := range tags {
+		tag.ID = 0
+		tag.QuestionID = questionID
+	}
+	return q.db.Create(&tags).Error
+}
+
+// DeleteQuestionTags 删除题目的全部分类
+func (q *QuestionDao) DeleteQuestionTags(questionID uint) error {
+	return q.db.Where("question_id = ?", questionID).Delete(&model.ExamQuestionTag{}).Error
+}
+
+// TagMatch 单个筛选分类：命中一级分类Tag下SecondTags中任一分类即可，SecondTags为空表示整个一级分类
+type TagMatch struct {
+	Tag        string
+	SecondTags []string
+}
+
+// QuestionTagFilter 题目多分类筛选条件
+type QuestionTagFilter struct {
+	Matches  []TagMatch
+	MatchAll bool // true=需同时命中全部分类 false=命中任一分类即可
+}
+
+// WithTagFilter 为题目查询追加多分类筛选条件（基于题目-分类关联表），没有筛选分类时原样返回
+func (q *QuestionDao) WithTagFilter(query *gorm.DB, filter QuestionTagFilter) *gorm.DB {
+	if len(filter.Matches) == 0 {
+		return query
+	}
+	if filter.MatchAll {
+		for _, match := range filter.Matches {
+			query = query.Where("exam_questions.id IN (?)", q.questionIDsByTags(match))
+		}
+		return query
+	}
+	return query.Where("exam_questions.id IN (?)", q.questionIDsByTags(filter.Matches...))
+}
+
+// questionIDsByTags 构建命中任一分类的题目ID子查询
+func (q *QuestionDao) questionIDsByTags(matches ...TagMatch) *gorm.DB {
+	cond := q.db.Session(&gorm.Session{NewDB: true})
+	for _, match := range matches {
+		if len(match.SecondTags) == 0 {
+			cond = cond.Or("tag = ?", match.Tag)
+		} else {
+			cond = cond.Or("tag = ? AND second_tag IN ?", match.Tag, match.SecondTags)
+		}
+	}
+	return q.db.Session(&gorm.Session{NewDB: true}).Model(&model.ExamQuestionTag{}).Select("question_id").Where(cond)
+}
+
+// ApprovedQuestions 查询范围：只包含已通过审核的题目（正式题库）
+func ApprovedQuestions(db *gorm.DB) *gorm.DB {
+	return db.Where("exam_questions.status = ?", consts.QuestionStatusApproved)
+}
+
+// withMainTag 确保题目的分类列表包含其主分类（Tag/SecondTag）
+func withMainTag(question *model.ExamQuestion) {
+	if question.Tag == "" {
+		return
+	}
+	for _, tag := range question.Tags {
+		if tag.Tag == question.Tag && tag.SecondTag == question.SecondTag {
+			return
+		}
+	}
+	question.Tags = append(question.Tags, &model.ExamQuestionTag{Tag: question.Tag, SecondTag: question.SecondTag})
+}
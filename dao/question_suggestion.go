@@ -0,0 +1,68 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+	"gorm.io/gorm"
+)
+
+// QuestionSuggestionDao AI补充解析建议DAO
+type QuestionSuggestionDao struct {
+	db *gorm.DB
+}
+
+// NewQuestionSuggestionDao 创建AI补充解析建议DAO实例
+func NewQuestionSuggestionDao(db *gorm.DB) *QuestionSuggestionDao {
+	return &QuestionSuggestionDao{
+		db: db,
+	}
+}
+
+// CreateSuggestions 批量创建建议
+func (d *QuestionSuggestionDao) CreateSuggestions(suggestions []*model.ExamQuestionSuggestion) error {
+	if len(suggestions) == 0 {
+		return nil
+	}
+	return d.db.Create(&suggestions).Error
+}
+
+// GetSuggestionByID 根据ID获取建议（含题目）
+func (d *QuestionSuggestionDao) GetSuggestionByID(id uint) (*model.ExamQuestionSuggestion, error) {
+	var suggestion model.ExamQuestionSuggestion
+	if err := d.db.Preload("Question").Where("id = ?", id).First(&suggestion).Error; err != nil {
+		return nil, err
+	}
+	return &suggestion, nil
+}
+
+// GetSuggestionsByStatus 分页获取指定状态的建议（含题目，按创建时间倒序）
+func (d *QuestionSuggestionDao) GetSuggestionsByStatus(status, page, size int) ([]*model.ExamQuestionSuggestion, int64, error) {
+	var suggestions []*model.ExamQuestionSuggestion
+	var total int64
+	query := d.db.Model(&model.ExamQuestionSuggestion{}).Where("status = ?", status)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Preload("Question").Order("id DESC").Offset((page - 1) * size).Limit(size).Find(&suggestions).Error
+	return suggestions, total, err
+}
+
+// PendingQuestionIDs 有待处理建议的题目ID子查询
+func (d *QuestionSuggestionDao) PendingQuestionIDs() *gorm.DB {
+	return d.db.Session(&gorm.Session{NewDB: true}).Model(&model.ExamQuestionSuggestion{}).
+		Select("question_id").Where("status = ?", consts.QuestionSuggestionStatusPending)
+}
+
+// ReviewSuggestion 将待处理的建议改为已采纳或已忽略，建议已处理时返回false
+func (d *QuestionSuggestionDao) ReviewSuggestion(id uint, status int, reviewerID uint, reviewedAt time.Time) (bool, error) {
+	result := d.db.Model(&model.ExamQuestionSuggestion{}).
+		Where("id = ? AND status = ?", id, consts.QuestionSuggestionStatusPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_by": reviewerID,
+			"reviewed_at": reviewedAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,98 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/vaynedu/exam_system/model"
+	"gorm.io/gorm"
+)
+
+// ReviewDao 间隔复习DAO
+type ReviewDao struct {
+	db *gorm.DB
+}
+
+// NewReviewDao 创建间隔复习DAO实例
+func NewReviewDao(db *gorm.DB) *ReviewDao {
+	return &ReviewDao{
+		db: db,
+	}
+}
+
+// ReviewForecast 某日待复习数量
+type ReviewForecast struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+// GetCardByQuestionID 根据题目ID获取用户的复习卡片
+func (d *ReviewDao) GetCardByQuestionID(userID, questionID uint) (*model.ExamReviewCard, error) {
+	var card model.ExamReviewCard
+	if err := d.db.Where("user_id = ? AND question_id = ?", userID, questionID).First(&card).Error; err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// SaveCard 新增或更新复习卡片
+func (d *ReviewDao) SaveCard(card *model.ExamReviewCard) error {
+	return d.db.Save(card).Error
+}
+
+// dueQuery 构建到期卡片查询
+func (d *ReviewDao) dueQuery(userID uint, tag, secondTag string, dueBefore time.Time) *gorm.DB {
+	query := d.db.Model(&model.ExamReviewCard{}).Where("user_id = ? AND due_date < ?", userID, dueBefore)
+	if tag != "" {
+		query = query.Where("tag = ?", tag)
+	}
+	if secondTag != "" {
+		query = query.Where("second_tag = ?", secondTag)
+	}
+	return query
+}
+
+// GetDueCards 获取到期的复习卡片（含题目），按到期时间升序
+func (d *ReviewDao) GetDueCards(userID uint, tag, secondTag string, dueBefore time.Time, limit int) ([]*model.ExamReviewCard, error) {
+	var cards []*model.ExamReviewCard
+	err := d.dueQuery(userID, tag, secondTag, dueBefore).Preload("Question").
+		Order("due_date ASC").Limit(limit).Find(&cards).Error
+	return cards, err
+}
+
+// CountDueCards 统计到期的复习卡片数量
+func (d *ReviewDao) CountDueCards(userID uint, tag, secondTag string, dueBefore time.Time) (int64, error) {
+	var total int64
+	err := d.dueQuery(userID, tag, secondTag, dueBefore).Count(&total).Error
+	return total, err
+}
+
+// CountReviewedSince 统计某时间之后已复习的卡片数量
+func (d *ReviewDao) CountReviewedSince(userID uint, since time.Time) (int64, error) {
+	var total int64
+	err := d.db.Model(&model.ExamReviewCard{}).Where("user_id = ? AND last_reviewed_at >= ?", userID, since).Count(&total).Error
+	return total, err
+}
+
+// GetDueForecast 按日统计[from, to)区间内到期的卡片数量
+func (d *ReviewDao) GetDueForecast(userID uint, from, to time.Time) ([]ReviewForecast, error) {
+	var rows []ReviewForecast
+	err := d.db.Model(&model.ExamReviewCard{}).
+		Select("DATE_FORMAT(due_date, '%Y-%m-%d') AS date, COUNT(*) AS count").
+		Where("user_id = ? AND due_date >= ? AND due_date < ?", userID, from, to).
+		Group("date").Order("date ASC").Scan(&rows).Error
+	return rows, err
+}
+
+// GetSetting 获取用户的复习设置（不存在时返回gorm.ErrRecordNotFound）
+func (d *ReviewDao) GetSetting(userID uint) (*model.ExamReviewSetting, error) {
+	var setting model.ExamReviewSetting
+	if err := d.db.Where("user_id = ?", userID).First(&setting).Error; err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+// SaveSetting 新增或更新复习设置
+func (d *ReviewDao) SaveSetting(setting *model.ExamReviewSetting) error {
+	return d.db.Save(setting).Error
+}
@@ -0,0 +1,244 @@
+package dao
+
+import (
+	"github.com/vaynedu/exam_system/model"
+	"gorm.io/gorm"
+)
+
+// TagDao 分类标签DAO
+type TagDao struct {
+	db *gorm.DB
+}
+
+// NewTagDao 创建分类标签DAO实例
+func NewTagDao(db *gorm.DB) *TagDao {
+	return &TagDao{
+		db: db,
+	}
+}
+
+// GetAllPrimaryTags 获取全部一级分类（含二级分类），按排序字段升序
+func (d *TagDao) GetAllPrimaryTags() ([]*model.ExamTagPrimary, error) {
+	var tags []*model.ExamTagPrimary
+	err := d.db.Preload("Secondaries", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order ASC, id ASC")
+	}).Order("sort_order ASC, id ASC").Find(&tags).Error
+	return tags, err
+}
+
+// CountPrimaryTags 统计一级分类数量
+func (d *TagDao) CountPrimaryTags() (int64, error) {
+	var total int64
+	err := d.db.Model(&model.ExamTagPrimary{}).Count(&total).Error
+	return total, err
+}
+
+// GetPrimaryTagByID 根据ID获取一级分类
+func (d *TagDao) GetPrimaryTagByID(id uint) (*model.ExamTagPrimary, error) {
+	var tag model.ExamTagPrimary
+	if err := d.db.Where("id = ?", id).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetPrimaryTagByName 根据名称获取一级分类
+func (d *TagDao) GetPrimaryTagByName(name string) (*model.ExamTagPrimary, error) {
+	var tag model.ExamTagPrimary
+	if err := d.db.Where("name = ?", name).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// CreatePrimaryTag 创建一级分类
+func (d *TagDao) CreatePrimaryTag(tag *model.ExamTagPrimary) error {
+	return d.db.Create(tag).Error
+}
+
+// UpdatePrimaryTag 更新一级分类的排序、描述与启用状态
+func (d *TagDao) UpdatePrimaryTag(tag *model.ExamTagPrimary) error {
+	return d.db.Model(&model.ExamTagPrimary{}).Where("id = ?", tag.ID).
+		Select("sort_order", "description", "enabled").Updates(tag).Error
+}
+
+// DeletePrimaryTag 删除一级分类
+func (d *TagDao) DeletePrimaryTag(id uint) error {
+	return d.db.Delete(&model.ExamTagPrimary{}, id).Error
+}
+
+// CountSecondaryTags 统计一级分类下的二级分类数量
+func (d *TagDao) CountSecondaryTags(primaryID uint) (int64, error) {
+	var total int64
+	err := d.db.Model(&model.ExamTagSecondary{}).Where("primary_id = ?", primaryID).Count(&total).Error
+	return total, err
+}
+
+// GetSecondaryTagByID 根据ID获取二级分类
+func (d *TagDao) GetSecondaryTagByID(id uint) (*model.ExamTagSecondary, error) {
+	var tag model.ExamTagSecondary
+	if err := d.db.Where("id = ?", id).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetSecondaryTagByName 根据一级分类ID和名称获取二级分类
+func (d *TagDao) GetSecondaryTagByName(primaryID uint, name string) (*model.ExamTagSecondary, error) {
+	var tag model.ExamTagSecondary
+	if err := d.db.Where("primary_id = ? AND name = ?", primaryID, name).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// CreateSecondaryTag 创建二级分类
+func (d *TagDao) CreateSecondaryTag(tag *model.ExamTagSecondary) error {
+	return d.db.Create(tag).Error
+}
+
+// CreateSecondaryTagsInBatches 批量创建二级分类
+func (d *TagDao) CreateSecondaryTagsInBatches(tags []*model.ExamTagSecondary, batchSize int) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	return d.db.CreateInBatches(tags, batchSize).Error
+}
+
+// UpdateSecondaryTag 更新二级分类的排序、描述与启用状态
+func (d *TagDao) UpdateSecondaryTag(tag *model.ExamTagSecondary) error {
+	return d.db.Model(&model.ExamTagSecondary{}).Where("id = ?", tag.ID).
+		Select("sort_order", "description", "enabled").Updates(tag).Error
+}
+
+// DeleteSecondaryTag 删除二级分类
+func (d *TagDao) DeleteSecondaryTag(id uint) error {
+	return d.db.Delete(&model.ExamTagSecondary{}, id).Error
+}
+
+// GetSecondaryTagsByPrimaryID 获取一级分类下的全部二级分类
+func (d *TagDao) GetSecondaryTagsByPrimaryID(primaryID uint) ([]*model.ExamTagSecondary, error) {
+	var tags []*model.ExamTagSecondary
+	err := d.db.Where("primary_id = ?", primaryID).Order("sort_order ASC, id ASC").Find(&tags).Error
+	return tags, err
+}
+
+// RenamePrimaryTag 修改一级分类名称
+func (d *TagDao) RenamePrimaryTag(id uint, name string) error {
+	return d.db.Model(&model.ExamTagPrimary{}).Where("id = ?", id).Update("name", name).Error
+}
+
+// RenameSecondaryTag 修改二级分类名称
+func (d *TagDao) RenameSecondaryTag(id uint, name string) error {
+	return d.db.Model(&model.ExamTagSecondary{}).Where("id = ?", id).Update("name", name).Error
+}
+
+// MoveSecondaryTag 将二级分类移动到指定一级分类下的指定上级分类（parentID为0表示直属于一级分类）
+func (d *TagDao) MoveSecondaryTag(id, primaryID, parentID uint) error {
+	return d.db.Model(&model.ExamTagSecondary{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"primary_id": primaryID, "parent_id": parentID}).Error
+}
+
+// MoveSecondaryTagsToPrimary 批量修改二级分类所属的一级分类（上级关系不变）
+func (d *TagDao) MoveSecondaryTagsToPrimary(ids []uint, primaryID uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return d.db.Model(&model.ExamTagSecondary{}).Where("id IN ?", ids).Update("primary_id", primaryID).Error
+}
+
+// ReparentSecondaryTags 将某分类的直接下级分类挂到新的上级分类下
+func (d *TagDao) ReparentSecondaryTags(fromParentID, toParentID uint) error {
+	return d.db.Model(&model.ExamTagSecondary{}).Where("parent_id = ?", fromParentID).Update("parent_id", toParentID).Error
+}
+
+// CountChildTags 统计某分类的直接下级分类数量
+func (d *TagDao) CountChildTags(parentID uint) (int64, error) {
+	var total int64
+	err := d.db.Model(&model.ExamTagSecondary{}).Where("parent_id = ?", parentID).Count(&total).Error
+	return total, err
+}
+
+// TagRef 业务数据中引用的分类，SecondTag为空表示整个一级分类
+type TagRef struct {
+	Tag       string
+	SecondTag string
+}
+
+// tagRefModels 冗余存储了分类名称的业务表
+var tagRefModels = []interface{}{
+	&model.ExamQuestion{},
+	&model.ExamQuestionTag{},
+	&model.ExamQuestionCollection{},
+	&model.ExamWrongQuestion{},
+	&model.ExamReviewCard{},
+	&model.ExamSession{},
+}
+
+// CountQuestionRefs 统计引用任一分类的题目数量（按题目-分类关联表去重）
+func (d *TagDao) CountQuestionRefs(refs ...TagRef) (int64, error) {
+	var total int64
+	err := d.tagRefQuery(&model.ExamQuestionTag{}, refs...).Distinct("question_id").Count(&total).Error
+	return total, err
+}
+
+// CountCollectionRefs 统计引用任一分类的收藏数量
+func (d *TagDao) CountCollectionRefs(refs ...TagRef) (int64, error) {
+	var total int64
+	err := d.tagRefQuery(&model.ExamQuestionCollection{}, refs...).Count(&total).Error
+	return total, err
+}
+
+// ReplaceTagRefs 将所有业务表中引用from分类的记录改为引用to分类
+// from.SecondTag为空时只替换一级分类名称，二级分类保持不变
+func (d *TagDao) ReplaceTagRefs(from, to TagRef) error {
+	if err := d.removeConflictQuestionTags(from, to); err != nil {
+		return err
+	}
+	for _, m := range tagRefModels {
+		query := d.tagRefQuery(m, from)
+		var err error
+		if from.SecondTag == "" {
+			err = query.Update("tag", to.Tag).Error
+		} else {
+			err = query.Updates(map[string]interface{}{"tag": to.Tag, "second_tag": to.SecondTag}).Error
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeConflictQuestionTags 删除替换后会与题目已有分类重复的关联记录，避免违反唯一索引
+func (d *TagDao) removeConflictQuestionTags(from, to TagRef) error {
+	var ids []uint
+	query := d.db.Table("exam_question_tag AS s").
+		Joins("JOIN exam_question_tag AS t ON t.question_id = s.question_id").
+		Where("s.tag = ? AND t.tag = ?", from.Tag, to.Tag)
+	if from.SecondTag == "" {
+		query = query.Where("t.second_tag = s.second_tag")
+	} else {
+		query = query.Where("s.second_tag = ? AND t.second_tag = ?", from.SecondTag, to.SecondTag)
+	}
+	if err := query.Pluck("s.id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return d.db.Where("id IN ?", ids).Delete(&model.ExamQuestionTag{}).Error
+}
+
+// tagRefQuery 构建按分类筛选的查询（多个分类之间为或关系）
+func (d *TagDao) tagRefQuery(m interface{}, refs ...TagRef) *gorm.DB {
+	cond := d.db.Session(&gorm.Session{NewDB: true})
+	for _, ref := range refs {
+		if ref.SecondTag == "" {
+			cond = cond.Or("tag = ?", ref.Tag)
+		} else {
+			cond = cond.Or("tag = ? AND second_tag = ?", ref.Tag, ref.SecondTag)
+		}
+	}
+	return d.db.Model(m).Where(cond)
+}
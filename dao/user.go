@@ -0,0 +1,99 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/vaynedu/exam_system/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserDao 用户DAO
+type UserDao struct {
+	db *gorm.DB
+}
+
+// NewUserDao 创建用户DAO实例
+func NewUserDao(db *gorm.DB) *UserDao {
+	return &UserDao{
+		db: db,
+	}
+}
+
+// CreateUser 创建用户
+func (d *UserDao) CreateUser(user *model.ExamUser) error {
+	return d.db.Create(user).Error
+}
+
+// GetUserByID 根据ID获取用户
+func (d *UserDao) GetUserByID(id uint) (*model.ExamUser, error) {
+	var user model.ExamUser
+	if err := d.db.Where("id = ?", id).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserByUsername 根据用户名获取用户
+func (d *UserDao) GetUserByUsername(username string) (*model.ExamUser, error) {
+	var user model.ExamUser
+	if err := d.db.Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateToken 保存登录态
+func (d *UserDao) CreateToken(token *model.ExamUserToken) error {
+	return d.db.Create(token).Error
+}
+
+// GetValidToken 根据token摘要获取未过期的登录态
+func (d *UserDao) GetValidToken(tokenHash string, now time.Time) (*model.ExamUserToken, error) {
+	var token model.ExamUserToken
+	if err := d.db.Where("token_hash = ? AND expires_at > ?", tokenHash, now).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// DeleteToken 删除登录态（退出登录）
+func (d *UserDao) DeleteToken(tokenHash string) error {
+	return d.db.Where("token_hash = ?", tokenHash).Delete(&model.ExamUserToken{}).Error
+}
+
+// DeleteExpiredTokens 清理用户已过期的登录态
+func (d *UserDao) DeleteExpiredTokens(userID uint, now time.Time) error {
+	return d.db.Where("user_id = ? AND expires_at <= ?", userID, now).Delete(&model.ExamUserToken{}).Error
+}
+
+// HasUsersForUpdate 判断是否已有用户，同时以SELECT ... FOR UPDATE锁定第一个用户（空表时锁定插入间隙），
+// 需在事务中与CreateUser一起调用，使并发注册时只有一个请求能成为第一个用户
+func (d *UserDao) HasUsersForUpdate() (bool, error) {
+	var ids []uint
+	err := d.db.Model(&model.ExamUser{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Order("id ASC").Limit(1).Pluck("id", &ids).Error
+	return len(ids) > 0, err
+}
+
+// UpdateUserRole 更新用户角色
+func (d *UserDao) UpdateUserRole(userID uint, role string) error {
+	return d.db.Model(&model.ExamUser{}).Where("id = ?", userID).Update("role", role).Error
+}
+
+// GetUserList 分页获取用户列表
+func (d *UserDao) GetUserList(page, size int) ([]*model.ExamUser, int64, error) {
+	var users []*model.ExamUser
+	var total int64
+
+	query := d.db.Model(&model.ExamUser{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Offset(offset).Limit(size).Order("id ASC").Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
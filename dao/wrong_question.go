@@ -0,0 +1,118 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/vaynedu/exam_system/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WrongQuestionDao 错题本DAO
+type WrongQuestionDao struct {
+	db *gorm.DB
+}
+
+// NewWrongQuestionDao 创建错题本DAO实例
+func NewWrongQuestionDao(db *gorm.DB) *WrongQuestionDao {
+	return &WrongQuestionDao{
+		db: db,
+	}
+}
+
+// RecordWrongQuestion 记录一次答错：不存在则新增，已存在则累加次数并重置掌握状态
+func (d *WrongQuestionDao) RecordWrongQuestion(userID uint, question *model.ExamQuestion, userAnswer string, wrongAt time.Time) error {
+	wrong := &model.ExamWrongQuestion{
+		UserID:      userID,
+		QuestionID:  question.ID,
+		Tag:         question.Tag,
+		SecondTag:   question.SecondTag,
+		WrongCount:  1,
+		LastAnswer:  userAnswer,
+		LastWrongAt: wrongAt,
+	}
+	return d.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"wrong_count":   gorm.Expr("wrong_count + 1"),
+			"last_answer":   userAnswer,
+			"last_wrong_at": wrongAt,
+			"tag":           question.Tag,
+			"second_tag":    question.SecondTag,
+			"is_mastered":   false,
+			"mastered_at":   nil,
+		}),
+	}).Create(wrong).Error
+}
+
+// GetWrongQuestionList 获取用户的错题列表，mastered为空表示不限掌握状态
+func (d *WrongQuestionDao) GetWrongQuestionList(userID uint, tag, secondTag string, mastered *bool, page, size int) ([]*model.ExamWrongQuestion, int64, error) {
+	var wrongs []*model.ExamWrongQuestion
+	var total int64
+
+	query := d.db.Model(&model.ExamWrongQuestion{}).Where("user_id = ?", userID)
+
+	// 筛选条件
+	if tag != "" {
+		query = query.Where("tag = ?", tag)
+	}
+	if secondTag != "" {
+		query = query.Where("second_tag = ?", secondTag)
+	}
+	if mastered != nil {
+		query = query.Where("is_mastered = ?", *mastered)
+	}
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	offset := (page - 1) * size
+	err := query.Preload("Question").Offset(offset).Limit(size).Order("last_wrong_at DESC").Find(&wrongs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return wrongs, total, nil
+}
+
+// MarkMastered 标记错题为已掌握，返回受影响行数
+func (d *WrongQuestionDao) MarkMastered(userID, questionID uint, masteredAt time.Time) (int64, error) {
+	result := d.db.Model(&model.ExamWrongQuestion{}).Where("user_id = ? AND question_id = ?", userID, questionID).
+		Updates(map[string]interface{}{"is_mastered": true, "mastered_at": masteredAt})
+	return result.RowsAffected, result.Error
+}
+
+// GetRandomWrongQuestions 从用户未掌握的错题中随机获取指定数量的题目
+func (d *WrongQuestionDao) GetRandomWrongQuestions(userID uint, tag, secondTag string, questionType int, limit int) ([]model.ExamQuestion, error) {
+	var questions []model.ExamQuestion
+	query := d.db.Model(&model.ExamQuestion{}).Select("exam_questions.*").
+		Joins("JOIN exam_wrong_question w ON w.question_id = exam_questions.id").
+		Where("w.user_id = ? AND w.is_mastered = ?", userID, false).
+		Scopes(ApprovedQuestions).
+		Order("RAND()").Limit(limit)
+
+	if tag != "" {
+		query = query.Where("exam_questions.tag = ?", tag)
+	}
+	if secondTag != "" {
+		query = query.Where("exam_questions.second_tag = ?", secondTag)
+	}
+	if questionType >= 0 {
+		query = query.Where("exam_questions.question_type = ?", questionType)
+	}
+
+	err := query.Find(&questions).Error
+	return questions, err
+}
+
+// CountByUserID 统计用户错题数量（未掌握/全部）
+func (d *WrongQuestionDao) CountByUserID(userID uint) (unmastered, total int64, err error) {
+	query := d.db.Model(&model.ExamWrongQuestion{}).Where("user_id = ?", userID)
+	if err = query.Count(&total).Error; err != nil {
+		return 0, 0, err
+	}
+	err = query.Where("is_mastered = ?", false).Count(&unmastered).Error
+	return unmastered, total, err
+}
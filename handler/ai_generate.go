@@ -2,8 +2,10 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/exam_system/consts"
 	"github.com/vaynedu/exam_system/service"
 )
 
@@ -36,7 +38,7 @@ func GenerateAIQuestion(c *gin.Context) {
 	}
 
 	// 调用Service层生成AI题目
-	questions, err := service.GenerateAIQuestionService(ctx, req.QuestionType, req.Tag, req.SecondTag, req.Count, req.Requirements)
+	questions, rejections, err := service.GenerateAIQuestionService(ctx, c.GetUint(consts.ContextKeyUserID), &req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": 500,
@@ -50,6 +52,8 @@ func GenerateAIQuestion(c *gin.Context) {
 			"code": 200,
 			"msg":  "AI题目生成成功，但无有效题目",
 			"data": questions,
+			// 未通过校验的题目及原因
+			"rejected": rejections,
 		})
 		return
 	}
@@ -57,7 +61,123 @@ func GenerateAIQuestion(c *gin.Context) {
 	// 返回成功结果
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
-		"msg":  "AI题目生成成功",
+		"msg":  "AI题目生成成功，已进入待审核队列",
 		"data": questions,
+		// 未通过校验的题目及原因
+		"rejected": rejections,
 	})
 }
+
+// SubmitAIGenerateJob 提交AI生成题目异步任务，立即返回任务ID，之后通过GetAIGenerateJob轮询进度
+func SubmitAIGenerateJob(c *gin.Context) {
+	var req service.SubmitAIGenerateJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	job, err := service.SubmitAIGenerateJobService(c.GetUint(consts.ContextKeyUserID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "提交AI生成任务失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "任务已提交，生成的题目将进入待审核队列",
+		"data": job,
+	})
+}
+
+// GetAIGenerateJob 查询AI生成任务的状态、进度及已生成的题目
+func GetAIGenerateJob(c *gin.Context) {
+	jobID, ok := parseAIGenerateJobID(c)
+	if !ok {
+		return
+	}
+
+	detail, err := service.GetAIGenerateJobService(c.GetUint(consts.ContextKeyUserID), jobID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "获取任务失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": detail,
+	})
+}
+
+// GetAIGenerateJobs 分页获取当前用户提交的AI生成任务
+func GetAIGenerateJobs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.Query("page"))
+	if page <= 0 {
+		page = 1
+	}
+	size, _ := strconv.Atoi(c.Query("size"))
+	if size <= 0 || size > 100 {
+		size = 10
+	}
+
+	jobs, total, err := service.GetAIGenerateJobsService(c.GetUint(consts.ContextKeyUserID), page, size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 500,
+			"msg":  "获取任务列表失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": gin.H{
+			"jobs":  jobs,
+			"total": total,
+			"page":  page,
+			"size":  size,
+		},
+	})
+}
+
+// CancelAIGenerateJob 取消排队中或生成中的AI生成任务
+func CancelAIGenerateJob(c *gin.Context) {
+	jobID, ok := parseAIGenerateJobID(c)
+	if !ok {
+		return
+	}
+
+	if err := service.CancelAIGenerateJobService(c.GetUint(consts.ContextKeyUserID), jobID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "取消任务失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "任务已取消，已生成的题目保留在待审核队列",
+	})
+}
+
+// parseAIGenerateJobID 解析路径中的任务ID，失败时直接写入400响应
+func parseAIGenerateJobID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "任务ID格式错误",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/exam_system/consts"
 	"github.com/vaynedu/exam_system/service"
 )
 
@@ -27,7 +28,7 @@ func CreateCollection(c *gin.Context) {
 	}
 
 	// 调用服务创建收藏
-	if err := service.CreateCollectionService(req.QuestionID, req.Tag, req.SecondTag); err != nil {
+	if err := service.CreateCollectionService(c.GetUint(consts.ContextKeyUserID), req.QuestionID, req.Tag, req.SecondTag); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code": 400,
 			"msg":  err.Error(),
@@ -56,7 +57,7 @@ func DeleteCollection(c *gin.Context) {
 	}
 
 	// 调用服务删除收藏
-	if err := service.DeleteCollectionService(uint(questionID)); err != nil {
+	if err := service.DeleteCollectionService(c.GetUint(consts.ContextKeyUserID), uint(questionID)); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code": 400,
 			"msg":  err.Error(),
@@ -85,7 +86,7 @@ func GetCollectionStatus(c *gin.Context) {
 	}
 
 	// 调用服务获取收藏状态
-	isCollected, err := service.GetCollectionStatusService(uint(questionID))
+	isCollected, err := service.GetCollectionStatusService(c.GetUint(consts.ContextKeyUserID), uint(questionID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": 500,
@@ -116,7 +117,7 @@ func BatchGetCollectionStatus(c *gin.Context) {
 	}
 
 	// 调用服务批量获取收藏状态
-	statusMap, err := service.BatchGetCollectionStatusService(questionIDs)
+	statusMap, err := service.BatchGetCollectionStatusService(c.GetUint(consts.ContextKeyUserID), questionIDs)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": 500,
@@ -151,7 +152,7 @@ func GetCollectionList(c *gin.Context) {
 	}
 
 	// 调用服务获取收藏列表
-	collections, total, err := service.GetCollectionListService(tag, secondTag, page, size)
+	collections, total, err := service.GetCollectionListService(c.GetUint(consts.ContextKeyUserID), tag, secondTag, page, size)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": 500,
@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/service"
+)
+
+// CreateExamSession 创建考试会话（按条件随机抽题）
+func CreateExamSession(c *gin.Context) {
+	var req service.CreateExamSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	session, err := service.CreateExamSessionService(c.GetUint(consts.ContextKeyUserID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "创建考试失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "创建考试成功",
+		"data": session,
+	})
+}
+
+// GetExamSessionQuestions 获取考试题目（不含答案）
+func GetExamSessionQuestions(c *gin.Context) {
+	sessionID, ok := parseExamSessionID(c)
+	if !ok {
+		return
+	}
+
+	session, questions, err := service.GetExamSessionQuestionsService(c.GetUint(consts.ContextKeyUserID), sessionID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "获取考试题目失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": gin.H{
+			"session":   session,
+			"questions": questions,
+		},
+	})
+}
+
+// SubmitExamAnswer 提交单题答案
+func SubmitExamAnswer(c *gin.Context) {
+	sessionID, ok := parseExamSessionID(c)
+	if !ok {
+		return
+	}
+
+	var req service.ExamAnswerItem
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	if err := service.SubmitExamAnswerService(c.GetUint(consts.ContextKeyUserID), sessionID, req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "提交答案失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "提交答案成功",
+	})
+}
+
+// BatchSubmitExamAnswers 批量提交答案
+func BatchSubmitExamAnswers(c *gin.Context) {
+	sessionID, ok := parseExamSessionID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Answers []service.ExamAnswerItem `json:"answers"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	if err := service.BatchSubmitExamAnswersService(c.GetUint(consts.ContextKeyUserID), sessionID, req.Answers); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "提交答案失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "提交答案成功",
+	})
+}
+
+// FinishExamSession 交卷并返回判分结果
+func FinishExamSession(c *gin.Context) {
+	sessionID, ok := parseExamSessionID(c)
+	if !ok {
+		return
+	}
+
+	result, err := service.FinishExamSessionService(c.GetUint(consts.ContextKeyUserID), sessionID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "交卷失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "交卷成功",
+		"data": result,
+	})
+}
+
+// GetExamSessionResult 获取考试结果
+func GetExamSessionResult(c *gin.Context) {
+	sessionID, ok := parseExamSessionID(c)
+	if !ok {
+		return
+	}
+
+	result, err := service.GetExamSessionResultService(c.GetUint(consts.ContextKeyUserID), sessionID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "获取考试结果失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": result,
+	})
+}
+
+// SelfReviewExamAnswer 问答题自评
+func SelfReviewExamAnswer(c *gin.Context) {
+	sessionID, ok := parseExamSessionID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		QuestionID uint `json:"question_id" binding:"required"`
+		IsCorrect  bool `json:"is_correct"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	session, err := service.SelfReviewExamAnswerService(c.GetUint(consts.ContextKeyUserID), sessionID, req.QuestionID, req.IsCorrect)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "评阅失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "评阅成功",
+		"data": session,
+	})
+}
+
+// AIGradeExamAnswer 问答题AI评分
+func AIGradeExamAnswer(c *gin.Context) {
+	sessionID, ok := parseExamSessionID(c)
+	if !ok {
+		return
+	}
+
+	var req service.AIGradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	answer, err := service.AIGradeExamAnswerService(c.Request.Context(), c.GetUint(consts.ContextKeyUserID), sessionID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "AI评分失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "AI评分成功",
+		"data": answer,
+	})
+}
+
+// GetExamSessionList 获取当前用户的练习记录
+func GetExamSessionList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > 100 {
+		size = 10
+	}
+
+	sessions, total, err := service.GetExamSessionListService(c.GetUint(consts.ContextKeyUserID), page, size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 500,
+			"msg":  "获取练习记录失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": gin.H{
+			"sessions": sessions,
+			"total":    total,
+			"page":     page,
+			"size":     size,
+		},
+	})
+}
+
+// parseExamSessionID 解析路径中的会话ID，失败时直接写入400响应
+func parseExamSessionID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "考试会话ID格式错误",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
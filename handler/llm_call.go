@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/exam_system/service"
+)
+
+// GetLLMCallStatistics 按天、按服务统计大模型调用的token用量、耗时与费用（start/end格式2006-01-02，默认最近7天）
+func GetLLMCallStatistics(c *gin.Context) {
+	stats, err := service.GetLLMCallStatisticsService(c.Query("start"), c.Query("end"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "获取大模型用量统计失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": stats,
+	})
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/service"
+)
+
+// GetPromptTemplates 获取全部提示词模板（各模板的最新版本）
+func GetPromptTemplates(c *gin.Context) {
+	tpls, err := service.GetPromptTemplatesService()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 500,
+			"msg":  "获取提示词模板失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": tpls,
+	})
+}
+
+// GetPromptTemplateVersions 获取提示词模板的全部版本
+func GetPromptTemplateVersions(c *gin.Context) {
+	tpls, err := service.GetPromptTemplateVersionsService(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "获取提示词模板失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": tpls,
+	})
+}
+
+// CreatePromptTemplate 新增提示词模板
+func CreatePromptTemplate(c *gin.Context) {
+	var req service.SavePromptTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	tpl, err := service.CreatePromptTemplateService(c.GetUint(consts.ContextKeyUserID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "新增提示词模板失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "新增提示词模板成功",
+		"data": tpl,
+	})
+}
+
+// UpdatePromptTemplate 修改提示词模板（保存为新版本）
+func UpdatePromptTemplate(c *gin.Context) {
+	var req service.SavePromptTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	tpl, err := service.UpdatePromptTemplateService(c.GetUint(consts.ContextKeyUserID), c.Param("name"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "修改提示词模板失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "修改提示词模板成功",
+		"data": tpl,
+	})
+}
+
+// DeletePromptTemplate 删除提示词模板（停用全部版本）
+func DeletePromptTemplate(c *gin.Context) {
+	if err := service.DeletePromptTemplateService(c.Param("name")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "删除提示词模板失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "删除提示词模板成功",
+	})
+}
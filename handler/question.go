@@ -2,7 +2,9 @@ package handler
 
 import (
 	"fmt"
+	"mime/multipart"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -11,6 +13,7 @@ import (
 	"github.com/vaynedu/exam_system/config"
 	"github.com/vaynedu/exam_system/consts"
 	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/middleware"
 	"github.com/vaynedu/exam_system/model"
 	"github.com/vaynedu/exam_system/service"
 	"github.com/xuri/excelize/v2"
@@ -27,18 +30,29 @@ func AddQuestion(c *gin.Context) {
 		return
 	}
 
-	// 调用Service层处理业务逻辑
-	if err := service.AddQuestionService(&req); err != nil {
+	// 调用Service层处理业务逻辑（duplicate_policy：与已有题目重复时的处理策略）
+	duplicate, err := service.AddQuestionService(&req, c.Query("duplicate_policy"))
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"msg": "新增题目失败：" + err.Error(),
+			"msg":       "新增题目失败：" + err.Error(),
+			"duplicate": duplicate,
+		})
+		return
+	}
+	if duplicate != nil && duplicate.Action == consts.DuplicatePolicyMerge {
+		c.JSON(http.StatusOK, gin.H{
+			"msg":       duplicate.ActionDescription(),
+			"code":      200,
+			"duplicate": duplicate,
 		})
 		return
 	}
 
 	// 返回成功结果
 	c.JSON(http.StatusOK, gin.H{
-		"msg":  fmt.Sprintf("新增题目成功，题目ID：%d", req.ID),
-		"code": 200,
+		"msg":       fmt.Sprintf("新增题目成功，题目ID：%d", req.ID),
+		"code":      200,
+		"duplicate": duplicate, // 按flag策略新增时，记录疑似重复的已有题目
 	})
 }
 
@@ -113,6 +127,11 @@ func ImportExcelQuestionV1(c *gin.Context) {
 			continue
 		}
 
+		// 末尾的空单元格不会被读出，补齐到11列（一级、二级分类可为空），避免越界
+		for len(row) < 11 {
+			row = append(row, "")
+		}
+
 		// 提取并格式化数据
 		typeStr := strings.TrimSpace(row[0])
 		title := strings.TrimSpace(row[1])
@@ -231,9 +250,13 @@ func GetRandom10Questions(c *gin.Context) {
 	// 获取请求参数中的tag和second_tag
 	tag := c.Query("tag")
 	secondTag := c.Query("second_tag")
+	tags, matchAll, ok := parseTagFilterQuery(c)
+	if !ok {
+		return
+	}
 
 	// 调用Service层获取随机题目
-	questions, err := service.GetRandomQuestionsService(tag, secondTag, 10)
+	questions, err := service.GetRandomQuestionsService(tag, secondTag, tags, matchAll, 10)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"msg": "获取题目失败：" + err.Error(),
@@ -259,56 +282,183 @@ func GetRandom10Questions(c *gin.Context) {
 
 // ImportExcelQuestion 导入Excel题目
 func ImportExcelQuestion(c *gin.Context) {
-	// 1. 接受上传的Excel文件
-	file, err := c.FormFile("excelFile")
+	// 1. 接收并打开上传的文件
+	src, opts, ok := openUploadedImportFile(c)
+	if !ok {
+		return
+	}
+	defer src.Close()
+
+	// 2. 调用Service层核心逻辑
+	result, err := service.ImportExcelQuestions(src, opts)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"msg": "获取excel文件失败：" + err.Error(),
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 500,
+			"msg":  "导入题目失败：" + err.Error(),
 		})
+		return
 	}
 
-	// 2. 基础格式校验（仅.xlsx）
-	if !strings.HasSuffix(file.Filename, ".xlsx") {
+	// 3. 构造响应返回
+	msg := fmt.Sprintf("导入完成！新增：%d 道，更新：%d 道，未变化：%d 道，失败：%d 道",
+		result.InsertedCount, result.UpdatedCount, result.UnchangedCount, result.FailCount)
+	if result.Aborted {
+		msg = fmt.Sprintf("存在 %d 行未通过校验，已取消导入，未写入任何题目", result.FailCount)
+	}
+	if len(result.Duplicates) > 0 {
+		msg += fmt.Sprintf("，重复：%d 道", len(result.Duplicates))
+	}
+	if result.FailCount > 0 {
+		first := result.FailedRows[0]
+		msg += fmt.Sprintf("（首个无效行：工作表%s第 %d 行，%s）", first.Sheet, first.Row, first.Error)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  msg,
+		"data": result,
+	})
+}
+
+// PreviewExcelQuestionImport 预览Excel导入：只解析校验不入库，返回每一行解析出的题目或错误原因；
+// report=true时返回追加了“错误原因”列的Excel副本（未通过校验的行标红）
+func PreviewExcelQuestionImport(c *gin.Context) {
+	src, opts, ok := openUploadedImportFile(c)
+	if !ok {
+		return
+	}
+	defer src.Close()
+
+	if report, _ := strconv.ParseBool(c.PostForm("report")); report {
+		file, err := service.BuildExcelImportReport(src, opts)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 400,
+				"msg":  "生成校验报告失败：" + err.Error(),
+			})
+			return
+		}
+		defer file.Close()
+
+		filename := fmt.Sprintf("import_report_%s.xlsx", time.Now().Format("20060102150405"))
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+		if err := file.Write(c.Writer); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": 500,
+				"msg":  "生成Excel文件失败：" + err.Error(),
+			})
+		}
+		return
+	}
+
+	preview, err := service.PreviewExcelQuestions(src, opts)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code": 400,
-			"msg":  "仅支持.xlsx格式的Excel文件！",
+			"msg":  "预览导入失败：" + err.Error(),
 		})
 		return
 	}
-	// 3. 打开文件并调用Service层处理
-	src, err := file.Open()
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  fmt.Sprintf("共 %d 行，可导入：%d 行，有误：%d 行", preview.TotalCount, preview.ValidCount, preview.InvalidCount),
+		"data": preview,
+	})
+}
+
+// DownloadImportTemplate 下载Excel导入模板（表头、填写说明、各题型示例及题型、分类下拉列表）
+func DownloadImportTemplate(c *gin.Context) {
+	file, err := service.BuildImportTemplate()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": 500,
-			"msg":  "打开Excel文件失败：" + err.Error(),
+			"msg":  err.Error(),
 		})
 		return
 	}
-	defer src.Close()
+	defer file.Close()
+
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", "attachment; filename=question_import_template.xlsx")
+	if err := file.Write(c.Writer); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 500,
+			"msg":  "生成Excel文件失败：" + err.Error(),
+		})
+	}
+}
 
-	// 4. 调用Service层核心逻辑
-	successCount, failCount, invalidRow, err := service.ImportExcelQuestions(src)
+// openUploadedImportFile 打开表单字段excelFile上传的.xlsx/.csv文件，并读取导入选项：
+// duplicate_policy为重复题目的处理策略（默认跳过），sheet_as_tag=true时一级分类为空的行使用工作表名作为一级分类，
+// all_or_nothing=true时存在无效行则取消整个导入；失败时直接写入错误响应
+func openUploadedImportFile(c *gin.Context) (multipart.File, *service.ExcelImportOptions, bool) {
+	file, err := c.FormFile("excelFile")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "获取excel文件失败：" + err.Error(),
+		})
+		return nil, nil, false
+	}
+
+	// 基础格式校验（.xlsx或.csv）
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if ext != ".xlsx" && ext != ".csv" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "仅支持.xlsx格式的Excel文件或.csv文件！",
+		})
+		return nil, nil, false
+	}
+
+	src, err := file.Open()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": 500,
-			"msg":  "导入题目失败：" + err.Error(),
+			"msg":  "打开Excel文件失败：" + err.Error(),
 		})
-		return
+		return nil, nil, false
+	}
+	sheetAsTag, _ := strconv.ParseBool(c.PostForm("sheet_as_tag"))
+	allOrNothing, _ := strconv.ParseBool(c.PostForm("all_or_nothing"))
+	return src, &service.ExcelImportOptions{
+		Filename:        file.Filename,
+		DuplicatePolicy: c.PostForm("duplicate_policy"),
+		SheetAsTag:      sheetAsTag,
+		AllOrNothing:    allOrNothing,
+	}, true
+}
+
+// GetDuplicateQuestionClusters 查询题库中疑似重复的题目聚类（可按题型type、一级分类tag缩小范围）
+func GetDuplicateQuestionClusters(c *gin.Context) {
+	var threshold float64
+	if value := c.Query("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 400,
+				"msg":  "相似度阈值格式错误",
+			})
+			return
+		}
+		threshold = parsed
 	}
 
-	// 5. 构造响应返回
-	msg := "导入完成！成功：%d 道，失败：%d 道"
-	if failCount > 0 {
-		msg += "（首个无效行：Excel第 %d 行）"
-		c.JSON(http.StatusOK, gin.H{
-			"code": 200,
-			"msg":  fmt.Sprintf(msg, successCount, failCount, invalidRow),
+	clusters, err := service.GetDuplicateClustersService(c.Query("type"), c.Query("tag"), threshold)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "查询重复题目失败：" + err.Error(),
 		})
 		return
 	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
-		"msg":  fmt.Sprintf(msg, successCount, failCount),
+		"data": gin.H{
+			"clusters": clusters,
+			"total":    len(clusters),
+		},
 	})
 }
 
@@ -319,6 +469,10 @@ func GetQuestionsByFilter(c *gin.Context) {
 	secondTag := c.Query("second_tag")
 	questionType := c.Query("type")
 	keyword := c.Query("keyword")
+	tags, matchAll, ok := parseTagFilterQuery(c)
+	if !ok {
+		return
+	}
 
 	// 分页参数
 	page, _ := strconv.Atoi(c.Query("page"))
@@ -331,7 +485,7 @@ func GetQuestionsByFilter(c *gin.Context) {
 	}
 
 	// 调用Service层获取题目列表
-	questions, total, err := service.GetQuestionsByFilterService(tag, secondTag, questionType, keyword, page, size)
+	questions, total, err := service.GetQuestionsByFilterService(tag, secondTag, tags, matchAll, questionType, keyword, page, size)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"msg":  "获取题目列表失败：" + err.Error(),
@@ -477,139 +631,35 @@ func ExportExcelQuestion(c *gin.Context) {
 		return
 	}
 
-	// 调用Service层获取题目数据
-	questions, err := service.ExportExcelQuestionService(req)
+	// 导出全部题库仅限管理员
+	if req.ExportAll && !middleware.HasPermission(c, consts.PermissionQuestionExportAll) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code": 403,
+			"msg":  "没有权限导出全部题库",
+		})
+		return
+	}
+
+	// 按ID导出待审核、已驳回的题目需有审核权限，否则只导出已通过审核的题目
+	req.IncludeUnapproved = middleware.HasPermission(c, consts.PermissionQuestionReview)
+
+	// 调用Service层分批读取题目并流式写入Excel
+	file, count, err := service.ExportExcelQuestions(req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": 500,
-			"msg":  "导出题目失败：" + err.Error(),
+			"msg":  err.Error(),
 		})
 		return
 	}
-
-	if len(questions) == 0 {
+	if count == 0 {
 		c.JSON(http.StatusOK, gin.H{
 			"code": 200,
 			"msg":  "没有找到符合条件的题目",
 		})
 		return
 	}
-
-	// 创建Excel文件
-	file := excelize.NewFile()
-	sheetName := "题目列表"
-	index, _ := file.NewSheet(sheetName)
-	file.SetActiveSheet(index)
-
-	// 设置表头
-	headers := []string{
-		"题型", "题干", "选项A", "选项B", "选项C", "选项D",
-		"正确答案", "解析", "备注", "一级分类", "二级分类",
-	}
-	for i, header := range headers {
-		cell, _ := excelize.CoordinatesToCellName(i+1, 1) // 第1行
-		err := file.SetCellValue(sheetName, cell, header)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": 500,
-				"msg":  "设置表头失败：" + err.Error(),
-			})
-			return
-		}
-	}
-
-	// 填充数据
-	for i, question := range questions {
-		row := i + 2 // 从第2行开始
-		err := file.SetCellValue(sheetName, fmt.Sprintf("A%d", row), question.QuestionType)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": 500,
-				"msg":  "写入题型失败：" + err.Error(),
-			})
-			return
-		}
-		err = file.SetCellValue(sheetName, fmt.Sprintf("B%d", row), question.QuestionTitle)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": 500,
-				"msg":  "写入题干失败：" + err.Error(),
-			})
-			return
-		}
-		err = file.SetCellValue(sheetName, fmt.Sprintf("C%d", row), question.OptionA)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": 500,
-				"msg":  "写入选项A失败：" + err.Error(),
-			})
-			return
-		}
-		err = file.SetCellValue(sheetName, fmt.Sprintf("D%d", row), question.OptionB)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": 500,
-				"msg":  "写入选项B失败：" + err.Error(),
-			})
-			return
-		}
-		err = file.SetCellValue(sheetName, fmt.Sprintf("E%d", row), question.OptionC)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": 500,
-				"msg":  "写入选项C失败：" + err.Error(),
-			})
-			return
-		}
-		err = file.SetCellValue(sheetName, fmt.Sprintf("F%d", row), question.OptionD)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": 500,
-				"msg":  "写入选项D失败：" + err.Error(),
-			})
-			return
-		}
-		err = file.SetCellValue(sheetName, fmt.Sprintf("G%d", row), question.CorrectAnswer)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": 500,
-				"msg":  "写入正确答案失败：" + err.Error(),
-			})
-			return
-		}
-		err = file.SetCellValue(sheetName, fmt.Sprintf("H%d", row), question.AnswerAnalysis)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": 500,
-				"msg":  "写入解析失败：" + err.Error(),
-			})
-			return
-		}
-		err = file.SetCellValue(sheetName, fmt.Sprintf("I%d", row), question.QuestionRemark)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": 500,
-				"msg":  "写入备注失败：" + err.Error(),
-			})
-			return
-		}
-		err = file.SetCellValue(sheetName, fmt.Sprintf("J%d", row), question.Tag)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": 500,
-				"msg":  "写入一级分类失败：" + err.Error(),
-			})
-			return
-		}
-		err = file.SetCellValue(sheetName, fmt.Sprintf("K%d", row), question.SecondTag)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": 500,
-				"msg":  "写入二级分类失败：" + err.Error(),
-			})
-			return
-		}
-	}
+	defer file.Close()
 
 	// 生成带时间戳的文件名
 	timestamp := time.Now().Format("20060102150405") // 格式：年月日时分秒
@@ -631,8 +681,7 @@ func ExportExcelQuestion(c *gin.Context) {
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
 
 	// 将Excel文件写入响应
-	err = file.Write(c.Writer)
-	if err != nil {
+	if err := file.Write(c.Writer); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": 500,
 			"msg":  "生成Excel文件失败：" + err.Error(),
@@ -640,3 +689,21 @@ func ExportExcelQuestion(c *gin.Context) {
 		return
 	}
 }
+
+// parseTagFilterQuery 解析多分类筛选参数：tags可重复传或以逗号分隔（如tags=数据存储/Redis,系统设计/分布式锁），
+// match=all表示需同时命中全部分类，默认命中任一即可；解析失败时直接写入400响应
+func parseTagFilterQuery(c *gin.Context) ([]dao.TagRef, bool, bool) {
+	var values []string
+	for _, value := range c.QueryArray("tags") {
+		values = append(values, strings.Split(value, ",")...)
+	}
+	tags, err := service.ParseTagFilters(values)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "分类筛选参数错误：" + err.Error(),
+		})
+		return nil, false, false
+	}
+	return tags, c.Query("match") == "all", true
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+	"github.com/vaynedu/exam_system/service"
+)
+
+// GetReviewQuestions 获取审核队列中的题目（status默认1=待审核）
+func GetReviewQuestions(c *gin.Context) {
+	status, err := strconv.Atoi(c.DefaultQuery("status", strconv.Itoa(consts.QuestionStatusPending)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "审核状态格式错误",
+		})
+		return
+	}
+
+	// 分页参数
+	page, _ := strconv.Atoi(c.Query("page"))
+	if page <= 0 {
+		page = 1
+	}
+	size, _ := strconv.Atoi(c.Query("size"))
+	if size <= 0 || size > 100 {
+		size = 10
+	}
+
+	questions, total, err := service.GetReviewQuestionsService(status, c.Query("tag"), c.Query("second_tag"),
+		c.Query("type"), c.Query("keyword"), page, size)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "获取审核队列失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": gin.H{
+			"questions": questions,
+			"total":     total,
+			"page":      page,
+			"size":      size,
+		},
+	})
+}
+
+// UpdateReviewQuestion 编辑待审核的题目
+func UpdateReviewQuestion(c *gin.Context) {
+	id, ok := parseReviewQuestionID(c)
+	if !ok {
+		return
+	}
+
+	var req model.ExamQuestion
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+	req.ID = id
+
+	if err := service.UpdateReviewQuestionService(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "编辑待审核题目失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "编辑待审核题目成功",
+	})
+}
+
+// ApproveReviewQuestion 审核通过单道题目
+func ApproveReviewQuestion(c *gin.Context) {
+	id, ok := parseReviewQuestionID(c)
+	if !ok {
+		return
+	}
+	result, err := service.ApproveQuestionsService([]uint{id}, c.GetUint(consts.ContextKeyUserID))
+	respondReviewResult(c, "审核通过", result, err)
+}
+
+// RejectReviewQuestion 驳回单道题目
+func RejectReviewQuestion(c *gin.Context) {
+	id, ok := parseReviewQuestionID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	result, err := service.RejectQuestionsService([]uint{id}, c.GetUint(consts.ContextKeyUserID), req.Reason)
+	respondReviewResult(c, "驳回", result, err)
+}
+
+// BatchApproveReviewQuestions 批量审核通过题目
+func BatchApproveReviewQuestions(c *gin.Context) {
+	var req struct {
+		IDs []uint `json:"ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	result, err := service.ApproveQuestionsService(req.IDs, c.GetUint(consts.ContextKeyUserID))
+	respondReviewResult(c, "批量审核通过", result, err)
+}
+
+// BatchRejectReviewQuestions 批量驳回题目
+func BatchRejectReviewQuestions(c *gin.Context) {
+	var req struct {
+		IDs    []uint `json:"ids" binding:"required"`
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	result, err := service.RejectQuestionsService(req.IDs, c.GetUint(consts.ContextKeyUserID), req.Reason)
+	respondReviewResult(c, "批量驳回", result, err)
+}
+
+// respondReviewResult 输出审核结果
+func respondReviewResult(c *gin.Context, action string, result *service.QuestionReviewResult, err error) {
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  action + "失败：" + err.Error(),
+		})
+		return
+	}
+	if len(result.Succeeded) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  action + "失败：题目不存在或不是待审核状态",
+			"data": result,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  action + "成功",
+		"data": result,
+	})
+}
+
+// parseReviewQuestionID 解析路径中的题目ID，失败时直接写入400响应
+func parseReviewQuestionID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "题目ID格式错误",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/service"
+)
+
+// EnrichQuestions 为已有题目生成AI补充建议（答案解析、难度、考察要点），题目筛选条件与题目列表相同，通过查询参数传入
+func EnrichQuestions(c *gin.Context) {
+	var req service.EnrichQuestionsRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 400,
+				"msg":  "参数解析失败：" + err.Error(),
+			})
+			return
+		}
+	}
+
+	tags, matchAll, ok := parseTagFilterQuery(c)
+	if !ok {
+		return
+	}
+	req.Tag = c.Query("tag")
+	req.SecondTag = c.Query("second_tag")
+	req.Tags = tags
+	req.MatchAll = matchAll
+	req.QuestionType = c.Query("type")
+	req.Keyword = c.Query("keyword")
+
+	result, err := service.EnrichQuestionsService(c.Request.Context(), c.GetUint(consts.ContextKeyUserID), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "生成AI建议失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "已生成" + strconv.Itoa(len(result.Suggestions)) + "条建议，" + strconv.Itoa(len(result.Failed)) + "道题失败",
+		"data": result,
+	})
+}
+
+// GetQuestionSuggestions 分页获取AI建议（status默认0=待处理）
+func GetQuestionSuggestions(c *gin.Context) {
+	status, err := strconv.Atoi(c.DefaultQuery("status", strconv.Itoa(consts.QuestionSuggestionStatusPending)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "建议状态格式错误",
+		})
+		return
+	}
+
+	// 分页参数
+	page, _ := strconv.Atoi(c.Query("page"))
+	if page <= 0 {
+		page = 1
+	}
+	size, _ := strconv.Atoi(c.Query("size"))
+	if size <= 0 || size > 100 {
+		size = 10
+	}
+
+	suggestions, total, err := service.GetQuestionSuggestionsService(status, page, size)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "获取AI建议失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": gin.H{
+			"suggestions": suggestions,
+			"total":       total,
+			"page":        page,
+			"size":        size,
+		},
+	})
+}
+
+// AcceptQuestionSuggestion 采纳AI建议，fields可选analysis/remark，不传则全部采纳
+func AcceptQuestionSuggestion(c *gin.Context) {
+	id, ok := parseQuestionSuggestionID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Fields []string `json:"fields"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 400,
+				"msg":  "参数解析失败：" + err.Error(),
+			})
+			return
+		}
+	}
+
+	suggestion, err := service.AcceptQuestionSuggestionService(c.GetUint(consts.ContextKeyUserID), id, req.Fields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "采纳建议失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "已采纳建议",
+		"data": suggestion,
+	})
+}
+
+// IgnoreQuestionSuggestion 忽略AI建议
+func IgnoreQuestionSuggestion(c *gin.Context) {
+	id, ok := parseQuestionSuggestionID(c)
+	if !ok {
+		return
+	}
+
+	if err := service.IgnoreQuestionSuggestionService(c.GetUint(consts.ContextKeyUserID), id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "忽略建议失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "已忽略建议",
+	})
+}
+
+// parseQuestionSuggestionID 解析路径中的建议ID，失败时直接写入400响应
+func parseQuestionSuggestionID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "建议ID格式错误",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/service"
+)
+
+// GetDueReviewQuestions 获取今日待复习题目
+func GetDueReviewQuestions(c *gin.Context) {
+	tag := c.Query("tag")
+	secondTag := c.Query("second_tag")
+
+	result, err := service.GetDueReviewQuestionsService(c.GetUint(consts.ContextKeyUserID), tag, secondTag)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "获取待复习题目失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": result,
+	})
+}
+
+// RateReview 复习自评（0=忘记 1=困难 2=良好 3=简单）
+func RateReview(c *gin.Context) {
+	var req struct {
+		QuestionID uint `json:"question_id" binding:"required"`
+		Rating     int  `json:"rating"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	card, err := service.RateReviewService(c.GetUint(consts.ContextKeyUserID), req.QuestionID, req.Rating)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "复习自评失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "复习自评成功",
+		"data": card,
+	})
+}
+
+// GetReviewForecast 预测未来几天的待复习数量
+func GetReviewForecast(c *gin.Context) {
+	days, _ := strconv.Atoi(c.Query("days"))
+
+	forecast, err := service.GetReviewForecastService(c.GetUint(consts.ContextKeyUserID), days)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "获取复习预测失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": forecast,
+	})
+}
+
+// GetReviewSetting 获取复习设置
+func GetReviewSetting(c *gin.Context) {
+	setting, err := service.GetReviewSettingService(c.GetUint(consts.ContextKeyUserID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 500,
+			"msg":  "获取复习设置失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": setting,
+	})
+}
+
+// UpdateReviewSetting 更新复习设置
+func UpdateReviewSetting(c *gin.Context) {
+	var req struct {
+		DailyLimit int `json:"daily_limit" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	setting, err := service.UpdateReviewSettingService(c.GetUint(consts.ContextKeyUserID), req.DailyLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "更新复习设置失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "更新复习设置成功",
+		"data": setting,
+	})
+}
@@ -15,6 +15,10 @@ func GetSpecialQuestionsByFilter(c *gin.Context) {
 	secondTag := c.Query("second_tag")
 	questionType := c.Query("type")
 	keyword := c.Query("keyword")
+	tags, matchAll, ok := parseTagFilterQuery(c)
+	if !ok {
+		return
+	}
 
 	// 分页参数
 	page, _ := strconv.Atoi(c.Query("page"))
@@ -27,7 +31,7 @@ func GetSpecialQuestionsByFilter(c *gin.Context) {
 	}
 
 	// 调用Service层获取题目列表
-	questions, total, err := service.GetQuestionsByFilterService(tag, secondTag, questionType, keyword, page, size)
+	questions, total, err := service.GetQuestionsByFilterService(tag, secondTag, tags, matchAll, questionType, keyword, page, size)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"msg":  "获取专项题目列表失败：" + err.Error(),
@@ -6,34 +6,41 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/vaynedu/exam_system/config"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
 	"github.com/vaynedu/exam_system/model"
+	"github.com/vaynedu/exam_system/service"
 )
 
-// GetStatistics 获取系统统计信息
+// GetStatistics 获取系统统计信息（只统计已通过审核的题目）
 func GetStatistics(c *gin.Context) {
 	// 获取总题目数
 	var totalQuestions int64
-	config.DB.Model(&model.ExamQuestion{}).Count(&totalQuestions)
+	config.DB.Model(&model.ExamQuestion{}).Scopes(dao.ApprovedQuestions).Count(&totalQuestions)
 
-	// 获取各题型数量
-	var choiceQuestions int64
-	config.DB.Model(&model.ExamQuestion{}).Where("question_type = ?", 0).Count(&choiceQuestions)
-
-	var fillQuestions int64
-	config.DB.Model(&model.ExamQuestion{}).Where("question_type = ?", 1).Count(&fillQuestions)
-
-	var essayQuestions int64
-	config.DB.Model(&model.ExamQuestion{}).Where("question_type = ?", 2).Count(&essayQuestions)
+	// 各题型数量（含多选、判断、排序、连线等全部题型）
+	typeStats, err := service.GetQuestionTypeCountsService()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 500,
+			"msg":  "获取题型统计失败：" + err.Error(),
+		})
+		return
+	}
+	typeCountMap := make(map[int]int64, len(typeStats))
+	for _, stat := range typeStats {
+		typeCountMap[stat.QuestionType] = stat.Count
+	}
 
 	// 获取分类统计
 	var tags []string
-	config.DB.Model(&model.ExamQuestion{}).Distinct().Pluck("tag", &tags)
+	config.DB.Model(&model.ExamQuestion{}).Scopes(dao.ApprovedQuestions).Distinct().Pluck("tag", &tags)
 
 	tagStats := make(map[string]int)
 	for _, tag := range tags {
 		if tag != "" {
 			var count int64
-			config.DB.Model(&model.ExamQuestion{}).Where("tag = ?", tag).Count(&count)
+			config.DB.Model(&model.ExamQuestion{}).Scopes(dao.ApprovedQuestions).Where("tag = ?", tag).Count(&count)
 			tagStats[tag] = int(count)
 		}
 	}
@@ -41,11 +48,16 @@ func GetStatistics(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"data": gin.H{
-			"total_questions":  totalQuestions,
-			"choice_questions": choiceQuestions,
-			"fill_questions":   fillQuestions,
-			"essay_questions":  essayQuestions,
-			"tag_statistics":   tagStats,
+			"total_questions":           totalQuestions,
+			"choice_questions":          typeCountMap[consts.QuestionTypeChoice],
+			"fill_questions":            typeCountMap[consts.QuestionTypeFillInTheBlank],
+			"essay_questions":           typeCountMap[consts.QuestionTypeShortAnswer],
+			"multiple_choice_questions": typeCountMap[consts.QuestionTypeMultipleChoice],
+			"true_false_questions":      typeCountMap[consts.QuestionTypeTrueFalse],
+			"ordering_questions":        typeCountMap[consts.QuestionTypeOrdering],
+			"matching_questions":        typeCountMap[consts.QuestionTypeMatching],
+			"type_statistics":           typeStats,
+			"tag_statistics":            tagStats,
 		},
 	})
 }
@@ -59,9 +71,9 @@ func GetQuestionTypeCount(c *gin.Context) {
 	}
 
 	var count int64
-	query := config.DB.Model(&model.ExamQuestion{})
+	query := config.DB.Model(&model.ExamQuestion{}).Scopes(dao.ApprovedQuestions)
 
-	if questionType >= 0 && questionType <= 2 {
+	if consts.CheckQuestionType(questionType) {
 		query = query.Where("question_type = ?", questionType)
 	}
 
@@ -79,13 +91,13 @@ func GetQuestionTypeCount(c *gin.Context) {
 // GetTagStatistics 获取分类统计
 func GetTagStatistics(c *gin.Context) {
 	var tags []string
-	config.DB.Model(&model.ExamQuestion{}).Distinct().Pluck("tag", &tags)
+	config.DB.Model(&model.ExamQuestion{}).Scopes(dao.ApprovedQuestions).Distinct().Pluck("tag", &tags)
 
 	tagStats := make(map[string]int)
 	for _, tag := range tags {
 		if tag != "" {
 			var count int64
-			config.DB.Model(&model.ExamQuestion{}).Where("tag = ?", tag).Count(&count)
+			config.DB.Model(&model.ExamQuestion{}).Scopes(dao.ApprovedQuestions).Where("tag = ?", tag).Count(&count)
 			tagStats[tag] = int(count)
 		}
 	}
@@ -95,3 +107,20 @@ func GetTagStatistics(c *gin.Context) {
 		"data": tagStats,
 	})
 }
+
+// GetUserStatistics 获取当前用户的学习统计
+func GetUserStatistics(c *gin.Context) {
+	stats, err := service.GetUserStatisticsService(c.GetUint(consts.ContextKeyUserID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 500,
+			"msg":  "获取学习统计失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": stats,
+	})
+}
@@ -3,8 +3,10 @@ package handler
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/service"
 
 	"net/http"
+	"strconv"
 )
 
 // GetTagTree 查询完整的Tag知识树（一级+二级分类）
@@ -16,10 +18,337 @@ import (
 // @Success 200 {object} gin.H{ "code":200, "msg":"查询成功", "data":[]util.PrimaryTag }
 // @Router /api/tag/tree [get]
 func GetTagTree(c *gin.Context) {
-	// 直接返回全局知识树数据
+	// 返回内存缓存中的知识树（仅包含启用的分类）
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "查询Tag知识树成功",
-		"data": consts.KnowledgeTree,
+		"data": consts.GetKnowledgeTree(),
+	})
+}
+
+// GetAllTags 获取全部分类（含停用的分类及排序、描述等管理信息）
+func GetAllTags(c *gin.Context) {
+	tags, err := service.GetAllTagsService()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 500,
+			"msg":  "获取分类失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": tags,
+	})
+}
+
+// CreatePrimaryTag 新增一级分类
+func CreatePrimaryTag(c *gin.Context) {
+	var req service.CreatePrimaryTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	tag, err := service.CreatePrimaryTagService(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "新增一级分类失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "新增一级分类成功",
+		"data": tag,
+	})
+}
+
+// UpdatePrimaryTag 更新一级分类（排序、描述、启用状态）
+func UpdatePrimaryTag(c *gin.Context) {
+	id, ok := parseTagID(c)
+	if !ok {
+		return
+	}
+
+	var req service.UpdateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	tag, err := service.UpdatePrimaryTagService(id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "更新一级分类失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "更新一级分类成功",
+		"data": tag,
+	})
+}
+
+// DeletePrimaryTag 删除一级分类
+func DeletePrimaryTag(c *gin.Context) {
+	id, ok := parseTagID(c)
+	if !ok {
+		return
+	}
+
+	if err := service.DeletePrimaryTagService(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "删除一级分类失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "删除一级分类成功",
+	})
+}
+
+// CreateSecondaryTag 新增二级分类
+func CreateSecondaryTag(c *gin.Context) {
+	var req service.CreateSecondaryTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	tag, err := service.CreateSecondaryTagService(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "新增二级分类失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "新增二级分类成功",
+		"data": tag,
+	})
+}
+
+// UpdateSecondaryTag 更新二级分类（排序、描述、启用状态）
+func UpdateSecondaryTag(c *gin.Context) {
+	id, ok := parseTagID(c)
+	if !ok {
+		return
+	}
+
+	var req service.UpdateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	tag, err := service.UpdateSecondaryTagService(id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "更新二级分类失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "更新二级分类成功",
+		"data": tag,
+	})
+}
+
+// DeleteSecondaryTag 删除二级分类
+func DeleteSecondaryTag(c *gin.Context) {
+	id, ok := parseTagID(c)
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteSecondaryTagService(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "删除二级分类失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "删除二级分类成功",
+	})
+}
+
+// parseTagID 解析路径中的分类ID，失败时直接写入400响应
+func parseTagID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "分类ID格式错误",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// RenamePrimaryTag 重命名一级分类（同步更新题目与收藏，支持dry_run预览）
+func RenamePrimaryTag(c *gin.Context) {
+	id, ok := parseTagID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name   string `json:"name" binding:"required"`
+		DryRun bool   `json:"dry_run"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	result, err := service.RenamePrimaryTagService(id, req.Name, req.DryRun)
+	respondTagOperation(c, "重命名一级分类", result, err)
+}
+
+// MergePrimaryTag 合并一级分类到目标一级分类
+func MergePrimaryTag(c *gin.Context) {
+	id, ok := parseTagID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		TargetID uint `json:"target_id" binding:"required"`
+		DryRun   bool `json:"dry_run"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	result, err := service.MergePrimaryTagService(id, req.TargetID, req.DryRun)
+	respondTagOperation(c, "合并一级分类", result, err)
+}
+
+// RenameSecondaryTag 重命名二级分类
+func RenameSecondaryTag(c *gin.Context) {
+	id, ok := parseTagID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name   string `json:"name" binding:"required"`
+		DryRun bool   `json:"dry_run"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	result, err := service.RenameSecondaryTagService(id, req.Name, req.DryRun)
+	respondTagOperation(c, "重命名二级分类", result, err)
+}
+
+// MergeSecondaryTag 合并二级分类到目标二级分类
+func MergeSecondaryTag(c *gin.Context) {
+	id, ok := parseTagID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		TargetID uint `json:"target_id" binding:"required"`
+		DryRun   bool `json:"dry_run"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	result, err := service.MergeSecondaryTagService(id, req.TargetID, req.DryRun)
+	respondTagOperation(c, "合并二级分类", result, err)
+}
+
+// MoveSecondaryTag 移动二级分类（可移动到其他一级分类或调整上级分类）
+func MoveSecondaryTag(c *gin.Context) {
+	id, ok := parseTagID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		PrimaryID uint `json:"primary_id" binding:"required"`
+		ParentID  uint `json:"parent_id"` // 目标上级分类，为空表示直属于一级分类
+		DryRun    bool `json:"dry_run"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	result, err := service.MoveSecondaryTagService(id, req.PrimaryID, req.ParentID, req.DryRun)
+	respondTagOperation(c, "移动二级分类", result, err)
+}
+
+// respondTagOperation 输出分类变更结果
+func respondTagOperation(c *gin.Context, action string, result *service.TagOperationResult, err error) {
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  action + "失败：" + err.Error(),
+		})
+		return
+	}
+
+	msg := action + "成功"
+	if result.DryRun {
+		msg = action + "预览（未实际修改）"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  msg,
+		"data": result,
 	})
 }
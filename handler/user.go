@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/middleware"
+	"github.com/vaynedu/exam_system/service"
+)
+
+// Register 用户注册
+func Register(c *gin.Context) {
+	var req service.RegisterUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	user, err := service.RegisterUserService(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "注册失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "注册成功",
+		"data": user,
+	})
+}
+
+// Login 用户登录，token同时通过响应体和Cookie返回
+func Login(c *gin.Context) {
+	var req service.LoginUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	result, err := service.LoginUserService(&req)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code": 401,
+			"msg":  "登录失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(consts.UserTokenCookieName, result.Token, int(consts.UserTokenExpire/time.Second), "/", "", false, true)
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "登录成功",
+		"data": result,
+	})
+}
+
+// Logout 退出登录
+func Logout(c *gin.Context) {
+	if err := service.LogoutUserService(middleware.GetRequestToken(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 500,
+			"msg":  "退出登录失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.SetCookie(consts.UserTokenCookieName, "", -1, "/", "", false, true)
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "退出登录成功",
+	})
+}
+
+// GetCurrentUser 获取当前登录用户
+func GetCurrentUser(c *gin.Context) {
+	user, _ := c.Get(consts.ContextKeyUser)
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": user,
+	})
+}
+
+// GetUserList 获取用户列表（管理员）
+func GetUserList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > 100 {
+		size = 10
+	}
+
+	users, total, err := service.GetUserListService(page, size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 500,
+			"msg":  "获取用户列表失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": gin.H{
+			"users": users,
+			"total": total,
+			"page":  page,
+			"size":  size,
+		},
+	})
+}
+
+// UpdateUserRole 修改用户角色（管理员）
+func UpdateUserRole(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "用户ID格式错误",
+		})
+		return
+	}
+
+	var req struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	if err := service.UpdateUserRoleService(c.GetUint(consts.ContextKeyUserID), uint(userID), req.Role); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "修改用户角色失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "修改用户角色成功",
+	})
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/service"
+)
+
+// GetWrongQuestionList 获取错题列表
+func GetWrongQuestionList(c *gin.Context) {
+	// 解析请求参数
+	tag := c.Query("tag")
+	secondTag := c.Query("second_tag")
+	pageStr := c.DefaultQuery("page", "1")
+	sizeStr := c.DefaultQuery("size", "10")
+
+	page, _ := strconv.Atoi(pageStr)
+	size, _ := strconv.Atoi(sizeStr)
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > 100 {
+		size = 10
+	}
+
+	// 掌握状态筛选：0=未掌握 1=已掌握 不传=全部
+	var mastered *bool
+	if masteredStr := c.Query("mastered"); masteredStr != "" {
+		value := masteredStr == "1"
+		mastered = &value
+	}
+
+	// 调用服务获取错题列表
+	wrongs, total, err := service.GetWrongQuestionListService(c.GetUint(consts.ContextKeyUserID), tag, secondTag, mastered, page, size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 500,
+			"msg":  "获取错题列表失败：" + err.Error(),
+		})
+		return
+	}
+
+	// 返回结果
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "获取错题列表成功",
+		"data": gin.H{
+			"wrong_questions": wrongs,
+			"total":           total,
+			"page":            page,
+			"size":            size,
+		},
+	})
+}
+
+// MarkWrongQuestionMastered 标记错题为已掌握
+func MarkWrongQuestionMastered(c *gin.Context) {
+	var req struct {
+		QuestionID uint `json:"question_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "参数解析失败：" + err.Error(),
+		})
+		return
+	}
+
+	if err := service.MarkWrongQuestionMasteredService(c.GetUint(consts.ContextKeyUserID), req.QuestionID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "已标记为掌握",
+	})
+}
+
+// GetRandomWrongQuestions 错题练习：从错题本中随机获取10道题
+func GetRandomWrongQuestions(c *gin.Context) {
+	tag := c.Query("tag")
+	secondTag := c.Query("second_tag")
+
+	// 校验标签参数（与随机练习一致），参数错误返回400
+	if err := service.ValidateRandomTagParams(tag, secondTag); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "获取错题失败：" + err.Error(),
+		})
+		return
+	}
+
+	questions, err := service.GetRandomWrongQuestionsService(c.GetUint(consts.ContextKeyUserID), tag, secondTag, 10)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 500,
+			"msg":  "获取错题失败：" + err.Error(),
+		})
+		return
+	}
+
+	if len(questions) == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"code": 200,
+			"msg":  "错题本中暂无未掌握的题目",
+			"data": questions,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": questions,
+	})
+}
@@ -5,16 +5,39 @@ import (
 
 	"github.com/vaynedu/exam_system/config"
 	"github.com/vaynedu/exam_system/router"
+	"github.com/vaynedu/exam_system/service"
 )
 
 func main() {
 	// 1. 初始化数据库连接
 	config.InitDB()
 
-	// 2. 初始化路由
+	// 2. 加载知识树（首次启动时用默认知识体系初始化分类表）
+	if err := service.InitKnowledgeTreeService(); err != nil {
+		log.Fatal("知识树初始化失败：", err)
+	}
+
+	// 3. 初始化AI生成题目的默认提示词模板
+	if err := service.InitPromptTemplatesService(); err != nil {
+		log.Fatal("提示词模板初始化失败：", err)
+	}
+
+	// 4. 上次未执行完的AI生成任务无法继续，标记为失败
+	if err := service.RecoverAIGenerateJobsService(); err != nil {
+		log.Println("AI生成任务状态恢复失败：", err)
+	}
+
+	// 5. 为新增指纹列之前的题目补充题干指纹（查重按指纹索引查找完全重复的题目）
+	if count, err := service.BackfillTitleFingerprintsService(); err != nil {
+		log.Println("题干指纹补充失败：", err)
+	} else if count > 0 {
+		log.Printf("已为%d道题目补充题干指纹", count)
+	}
+
+	// 6. 初始化路由
 	r := router.InitRouter()
 
-	// 3. 启动服务（端口8080）
+	// 7. 启动服务（端口8080）
 	log.Println("服务启动成功：http://127.0.0.1:8080")
 	if err := r.Run(":8080"); err != nil {
 		log.Fatal("服务启动失败：", err)
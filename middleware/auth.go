@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+	"github.com/vaynedu/exam_system/service"
+)
+
+// AuthRequired 登录校验中间件：从Authorization请求头（Bearer）或Cookie中读取token，
+// 校验通过后将当前用户写入gin.Context
+func AuthRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := GetRequestToken(c)
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"code": 401,
+				"msg":  "请先登录",
+			})
+			return
+		}
+
+		user, err := service.GetUserByTokenService(token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"code": 401,
+				"msg":  err.Error(),
+			})
+			return
+		}
+
+		c.Set(consts.ContextKeyUserID, user.ID)
+		c.Set(consts.ContextKeyUser, user)
+		c.Next()
+	}
+}
+
+// GetRequestToken 获取请求中的登录态token，优先读取Authorization请求头
+func GetRequestToken(c *gin.Context) string {
+	if header := c.GetHeader(consts.UserTokenHeaderName); header != "" {
+		return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	}
+	token, _ := c.Cookie(consts.UserTokenCookieName)
+	return token
+}
+
+// RequirePermission 权限校验中间件，需在AuthRequired之后使用
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasPermission(c, permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"code": 403,
+				"msg":  "没有权限执行该操作",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// HasPermission 判断当前登录用户是否拥有某权限
+func HasPermission(c *gin.Context, permission string) bool {
+	value, ok := c.Get(consts.ContextKeyUser)
+	if !ok {
+		return false
+	}
+	user, ok := value.(*model.ExamUser)
+	if !ok {
+		return false
+	}
+	return consts.RoleHasPermission(user.Role, permission)
+}
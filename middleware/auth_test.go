@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+)
+
+// newPermissionTestRouter 创建测试路由：以指定用户身份（nil表示未写入用户）访问需要permission的接口
+func newPermissionTestRouter(user *model.ExamUser, permission string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/test", func(c *gin.Context) {
+		if user != nil {
+			c.Set(consts.ContextKeyUserID, user.ID)
+			c.Set(consts.ContextKeyUser, user)
+		}
+		c.Next()
+	}, RequirePermission(permission), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"code": 200})
+	})
+	return r
+}
+
+// 测试RequirePermission：编辑不能访问管理员专属接口，未写入用户时一律拒绝
+func TestRequirePermission(t *testing.T) {
+	admin := &model.ExamUser{ID: 1, Role: consts.UserRoleAdmin}
+	editor := &model.ExamUser{ID: 2, Role: consts.UserRoleEditor}
+	learner := &model.ExamUser{ID: 3, Role: consts.UserRoleLearner}
+
+	testCases := []struct {
+		name       string
+		user       *model.ExamUser
+		permission string
+		expected   int
+	}{
+		{"编辑删除题目", editor, consts.PermissionQuestionDelete, http.StatusForbidden},
+		{"编辑导出全部题库", editor, consts.PermissionQuestionExportAll, http.StatusForbidden},
+		{"编辑管理用户", editor, consts.PermissionUserManage, http.StatusForbidden},
+		{"编辑管理分类", editor, consts.PermissionTagManage, http.StatusForbidden},
+		{"编辑编辑题目", editor, consts.PermissionQuestionEdit, http.StatusOK},
+		{"管理员删除题目", admin, consts.PermissionQuestionDelete, http.StatusOK},
+		{"学员编辑题目", learner, consts.PermissionQuestionEdit, http.StatusForbidden},
+		{"学员练习", learner, consts.PermissionPractice, http.StatusOK},
+		{"未写入用户", nil, consts.PermissionPractice, http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			newPermissionTestRouter(tc.user, tc.permission).ServeHTTP(w, req)
+			assert.Equal(t, tc.expected, w.Code)
+		})
+	}
+}
+
+// 测试GetRequestToken：优先读取Bearer请求头，其次读取Cookie
+func TestGetRequestToken(t *testing.T) {
+	testCases := []struct {
+		name     string
+		header   string
+		cookie   string
+		expected string
+	}{
+		{"Bearer请求头", "Bearer abc", "", "abc"},
+		{"请求头优先于Cookie", "Bearer abc", "def", "abc"},
+		{"仅Cookie", "", "def", "def"},
+		{"未携带", "", "", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				req.Header.Set(consts.UserTokenHeaderName, tc.header)
+			}
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: consts.UserTokenCookieName, Value: tc.cookie})
+			}
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = req
+			assert.Equal(t, tc.expected, GetRequestToken(c))
+		})
+	}
+}
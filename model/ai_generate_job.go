@@ -0,0 +1,104 @@
+package model
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+// AIGenerateJob AI生成题目异步任务：按分类拆分为多批调用大模型，每批完成后更新进度与结果
+type AIGenerateJob struct {
+	ID             uint                 `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID         uint                 `gorm:"column:user_id;not null;index:idx_user_id" json:"user_id"` // 提交任务的用户ID
+	Status         int8                 `gorm:"column:status;not null;default:0" json:"status"`           // 0=排队中 1=生成中 2=已完成 3=已取消 4=失败
+	Params         AIGenerateJobParams  `gorm:"column:params;type:json" json:"params"`                    // 任务参数
+	TotalCount     int                  `gorm:"column:total_count;not null;default:0" json:"total_count"` // 要求生成的题目总数
+	TotalChunks    int                  `gorm:"column:total_chunks;not null;default:0" json:"total_chunks"`
+	FinishedChunks int                  `gorm:"column:finished_chunks;not null;default:0" json:"finished_chunks"` // 已处理（成功或重试后仍失败）的批次数
+	FailedChunks   int                  `gorm:"column:failed_chunks;not null;default:0" json:"failed_chunks"`
+	QuestionIDs    UintList             `gorm:"column:question_ids;type:json" json:"question_ids"` // 已入库（待审核）的题目ID
+	Rejections     AIGenerateRejections `gorm:"column:rejections;type:json" json:"rejections"`     // 未入库的题目及原因
+	ErrorMsg       string               `gorm:"column:error_msg;type:varchar(1000);default:''" json:"error_msg"`
+	StartedAt      *time.Time           `gorm:"column:started_at" json:"started_at"`
+	FinishedAt     *time.Time           `gorm:"column:finished_at" json:"finished_at"`
+	CreatedAt      time.Time            `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time            `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (AIGenerateJob) TableName() string {
+	return "exam_ai_generate_job"
+}
+
+// AIGenerateJobTarget 任务中单个分类要生成的题目数量
+type AIGenerateJobTarget struct {
+	Tag       string `json:"tag"`
+	SecondTag string `json:"second_tag"`
+	Count     int    `json:"count"`
+}
+
+// AIGenerateJobParams AI生成题目任务参数
+type AIGenerateJobParams struct {
+	QuestionType    int                   `json:"question_type"`
+	Targets         []AIGenerateJobTarget `json:"targets"`
+	Requirements    string                `json:"requirements"`
+	Provider        string                `json:"provider"`
+	Model           string                `json:"model"`
+	DuplicatePolicy string                `json:"duplicate_policy"`
+	// 提交时确定的提示词模板版本ID、难度描述及few-shot示例题目ID
+	PromptTemplateID   uint   `json:"prompt_template_id"`
+	Difficulty         string `json:"difficulty"`
+	ExampleQuestionIDs []uint `json:"example_question_ids"`
+}
+
+// Value 实现driver.Valuer
+func (p AIGenerateJobParams) Value() (driver.Value, error) {
+	return jsonValue(p)
+}
+
+// Scan 实现sql.Scanner
+func (p *AIGenerateJobParams) Scan(value interface{}) error {
+	return scanJSON(value, p)
+}
+
+// AIGenerateRejection AI生成的题目未入库的原因
+type AIGenerateRejection struct {
+	Chunk         int    `json:"chunk"` // 所在批次，从1开始
+	Index         int    `json:"index"` // 在该批次模型回复中的序号，从1开始；整批失败时为0
+	QuestionTitle string `json:"question_title"`
+	Reason        string `json:"reason"`
+	DuplicateOf   uint   `json:"duplicate_of,omitempty"`
+}
+
+// AIGenerateRejections 未入库的题目列表，以JSON数组存储
+type AIGenerateRejections []AIGenerateRejection
+
+// Value 实现driver.Valuer
+func (r AIGenerateRejections) Value() (driver.Value, error) {
+	if r == nil {
+		return "[]", nil
+	}
+	return jsonValue([]AIGenerateRejection(r))
+}
+
+// Scan 实现sql.Scanner
+func (r *AIGenerateRejections) Scan(value interface{}) error {
+	*r = nil
+	return scanJSON(value, (*[]AIGenerateRejection)(r))
+}
+
+// UintList ID列表，以JSON数组存储
+type UintList []uint
+
+// Value 实现driver.Valuer
+func (l UintList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	return jsonValue([]uint(l))
+}
+
+// Scan 实现sql.Scanner
+func (l *UintList) Scan(value interface{}) error {
+	*l = nil
+	return scanJSON(value, (*[]uint)(l))
+}
@@ -0,0 +1,21 @@
+-- AI生成题目异步任务表
+CREATE TABLE IF NOT EXISTS `exam_ai_generate_job` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT COMMENT '任务ID',
+  `user_id` int(11) unsigned NOT NULL DEFAULT 0 COMMENT '提交任务的用户ID',
+  `status` tinyint NOT NULL DEFAULT 0 COMMENT '任务状态：0=排队中 1=生成中 2=已完成 3=已取消 4=失败',
+  `params` json DEFAULT NULL COMMENT '任务参数（题型、各分类数量、要求描述、大模型等）',
+  `total_count` int(11) NOT NULL DEFAULT 0 COMMENT '要求生成的题目总数',
+  `total_chunks` int(11) NOT NULL DEFAULT 0 COMMENT '总批次数',
+  `finished_chunks` int(11) NOT NULL DEFAULT 0 COMMENT '已处理批次数',
+  `failed_chunks` int(11) NOT NULL DEFAULT 0 COMMENT '重试后仍失败的批次数',
+  `question_ids` json DEFAULT NULL COMMENT '已入库（待审核）的题目ID',
+  `rejections` json DEFAULT NULL COMMENT '未入库的题目及原因',
+  `error_msg` varchar(1000) DEFAULT '' COMMENT '任务失败原因',
+  `started_at` datetime DEFAULT NULL COMMENT '开始执行时间',
+  `finished_at` datetime DEFAULT NULL COMMENT '结束时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='AI生成题目异步任务表';
@@ -7,7 +7,8 @@ import (
 // ExamQuestionCollection 收藏题目模型
 type ExamQuestionCollection struct {
 	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
-	QuestionID uint      `json:"question_id" gorm:"not null;uniqueIndex:uk_question_id"`
+	UserID     uint      `json:"user_id" gorm:"not null;uniqueIndex:uk_user_question"`
+	QuestionID uint      `json:"question_id" gorm:"not null;uniqueIndex:uk_user_question"`
 	Tag        string    `json:"tag" gorm:"not null;index:idx_tag"`
 	SecondTag  string    `json:"second_tag" gorm:"not null;index:idx_second_tag"`
 	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
@@ -1,12 +1,19 @@
 -- 收藏题目表
 CREATE TABLE IF NOT EXISTS `exam_question_collection` (
   `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '收藏ID',
+  `user_id` int(11) unsigned NOT NULL DEFAULT 0 COMMENT '用户ID',
   `question_id` int(11) NOT NULL COMMENT '题目ID',
   `tag` varchar(50) NOT NULL COMMENT '一级分类',
   `second_tag` varchar(100) NOT NULL COMMENT '二级分类',
   `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '收藏时间',
   PRIMARY KEY (`id`),
-  UNIQUE KEY `uk_question_id` (`question_id`),
+  UNIQUE KEY `uk_user_question` (`user_id`, `question_id`),
   KEY `idx_tag` (`tag`),
   KEY `idx_second_tag` (`second_tag`)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='收藏题目表';
\ No newline at end of file
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='收藏题目表';
+
+-- 收藏按用户隔离：新增user_id，唯一索引改为(user_id, question_id)
+ALTER TABLE `exam_question_collection`
+    ADD COLUMN `user_id` int(11) unsigned NOT NULL DEFAULT 0 COMMENT '用户ID' AFTER `id`,
+    DROP INDEX `uk_question_id`,
+    ADD UNIQUE KEY `uk_user_question` (`user_id`, `question_id`);
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+// ExamSession 考试会话模型（一次练习/考试）
+type ExamSession struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID        uint       `gorm:"column:user_id;not null;index:idx_user_id" json:"user_id"`
+	Tag           string     `gorm:"column:tag;type:varchar(50);default:''" json:"tag"`
+	SecondTag     string     `gorm:"column:second_tag;type:varchar(100);default:''" json:"second_tag"`
+	QuestionType  int8       `gorm:"column:question_type;not null;default:-1" json:"question_type"` // -1=不限题型
+	QuestionCount int        `gorm:"column:question_count;not null" json:"question_count"`
+	Status        int8       `gorm:"column:status;not null;default:0" json:"status"` // 0=进行中 1=已交卷
+	CorrectCount  int        `gorm:"column:correct_count;not null;default:0" json:"correct_count"`
+	WrongCount    int        `gorm:"column:wrong_count;not null;default:0" json:"wrong_count"`
+	PendingCount  int        `gorm:"column:pending_count;not null;default:0" json:"pending_count"` // 待评阅（问答题）数量
+	Score         int        `gorm:"column:score;not null;default:0" json:"score"`                 // 得分（百分制，仅统计可自动判分题目）
+	FinishedAt    *time.Time `gorm:"column:finished_at" json:"finished_at"`
+	CreatedAt     time.Time  `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ExamSession) TableName() string {
+	return "exam_session"
+}
+
+// ExamSessionAnswer 考试会话中的单题作答记录
+type ExamSessionAnswer struct {
+	ID           uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	SessionID    uint           `gorm:"column:session_id;not null;uniqueIndex:uk_session_question" json:"session_id"`
+	QuestionID   uint           `gorm:"column:question_id;not null;uniqueIndex:uk_session_question" json:"question_id"`
+	Seq          int            `gorm:"column:seq;not null" json:"seq"` // 题目在会话中的顺序，从1开始
+	UserAnswer   string         `gorm:"column:user_answer;type:varchar(2000);default:''" json:"user_answer"`
+	Result       int8           `gorm:"column:result;not null;default:0" json:"result"`                // 0=未作答 1=正确 2=错误 3=待评阅
+	BlankResults BlankResults   `gorm:"column:blank_results;type:json" json:"blank_results,omitempty"` // 填空题逐空判分结果
+	AIGrade      *AIGradeResult `gorm:"column:ai_grade;type:json" json:"ai_grade,omitempty"`           // 问答题AI评分结果（仅供参考，不改变判分结果）
+	AIGradedAt   *time.Time     `gorm:"column:ai_graded_at" json:"ai_graded_at,omitempty"`
+	AnsweredAt   *time.Time     `gorm:"column:answered_at" json:"answered_at"`
+	CreatedAt    time.Time      `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time      `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+
+	// 关联关系
+	Question *ExamQuestion `json:"question,omitempty" gorm:"foreignKey:QuestionID"`
+}
+
+// TableName 指定表名
+func (ExamSessionAnswer) TableName() string {
+	return "exam_session_answer"
+}
+
+// BlankResult 填空题单个空的判分结果
+type BlankResult struct {
+	Index      int    `json:"index"` // 第几个空，从1开始
+	UserAnswer string `json:"user_answer"`
+	Correct    bool   `json:"correct"`
+}
+
+// BlankResults 填空题逐空判分结果，以JSON数组存储
+type BlankResults []BlankResult
+
+// Value 实现driver.Valuer
+func (r BlankResults) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return jsonValue([]BlankResult(r))
+}
+
+// Scan 实现sql.Scanner
+func (r *BlankResults) Scan(value interface{}) error {
+	*r = nil
+	return scanJSON(value, (*[]BlankResult)(r))
+}
+
+// AIGradeResult 问答题AI评分结果
+type AIGradeResult struct {
+	Score         int      `json:"score"`          // 0-100分
+	MatchedPoints []string `json:"matched_points"` // 答到的要点
+	MissedPoints  []string `json:"missed_points"`  // 遗漏的要点
+	Feedback      string   `json:"feedback"`       // 评语与改进建议
+	Model         string   `json:"model"`          // 评分使用的模型
+}
+
+// Value 实现driver.Valuer
+func (r AIGradeResult) Value() (driver.Value, error) {
+	return jsonValue(r)
+}
+
+// Scan 实现sql.Scanner
+func (r *AIGradeResult) Scan(value interface{}) error {
+	return scanJSON(value, r)
+}
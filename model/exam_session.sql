@@ -0,0 +1,50 @@
+-- 考试会话表
+CREATE TABLE IF NOT EXISTS `exam_session` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT COMMENT '会话ID',
+  `user_id` int(11) unsigned NOT NULL DEFAULT 0 COMMENT '用户ID',
+  `tag` varchar(50) DEFAULT '' COMMENT '一级分类（为空表示不限）',
+  `second_tag` varchar(100) DEFAULT '' COMMENT '二级分类（为空表示不限）',
+  `question_type` tinyint NOT NULL DEFAULT -1 COMMENT '题型：-1=不限 0=选择题 1=填空题 2=问答题',
+  `question_count` int(11) NOT NULL COMMENT '题目数量',
+  `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态：0=进行中 1=已交卷',
+  `correct_count` int(11) NOT NULL DEFAULT 0 COMMENT '答对数量',
+  `wrong_count` int(11) NOT NULL DEFAULT 0 COMMENT '答错数量（含未作答）',
+  `pending_count` int(11) NOT NULL DEFAULT 0 COMMENT '待评阅数量（问答题）',
+  `score` int(11) NOT NULL DEFAULT 0 COMMENT '得分（百分制）',
+  `finished_at` datetime DEFAULT NULL COMMENT '交卷时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='考试会话表';
+
+-- 考试会话作答表
+CREATE TABLE IF NOT EXISTS `exam_session_answer` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT COMMENT '作答记录ID',
+  `session_id` int(11) unsigned NOT NULL COMMENT '会话ID',
+  `question_id` int(11) unsigned NOT NULL COMMENT '题目ID',
+  `seq` int(11) NOT NULL COMMENT '题目顺序（从1开始）',
+  `user_answer` varchar(2000) DEFAULT '' COMMENT '用户答案',
+  `result` tinyint NOT NULL DEFAULT 0 COMMENT '作答结果：0=未作答 1=正确 2=错误 3=待评阅',
+  `answered_at` datetime DEFAULT NULL COMMENT '作答时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_session_question` (`session_id`, `question_id`),
+  KEY `idx_question_id` (`question_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='考试会话作答表';
+
+-- 考试会话按用户隔离
+ALTER TABLE `exam_session`
+    ADD COLUMN `user_id` int(11) unsigned NOT NULL DEFAULT 0 COMMENT '用户ID' AFTER `id`,
+    ADD KEY `idx_user_id` (`user_id`);
+
+-- 填空题逐空判分结果
+ALTER TABLE `exam_session_answer`
+    ADD COLUMN `blank_results` json DEFAULT NULL COMMENT '填空题逐空判分结果' AFTER `result`;
+
+-- 问答题AI评分结果
+ALTER TABLE `exam_session_answer`
+    ADD COLUMN `ai_grade` json DEFAULT NULL COMMENT '问答题AI评分结果（分数、要点、评语）' AFTER `blank_results`,
+    ADD COLUMN `ai_graded_at` datetime DEFAULT NULL COMMENT 'AI评分时间' AFTER `ai_grade`;
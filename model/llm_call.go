@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// ExamLLMCall 大模型调用记录：用于统计token用量、耗时与费用，以及审计调用结果
+type ExamLLMCall struct {
+	ID               uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID           uint      `gorm:"column:user_id;not null;default:0" json:"user_id"`      // 发起调用的用户ID，0表示系统
+	Scene            string    `gorm:"column:scene;type:varchar(32);default:''" json:"scene"` // 调用场景：generate/job/grade/enrich
+	Provider         string    `gorm:"column:provider;type:varchar(32);not null;index:idx_provider" json:"provider"`
+	Model            string    `gorm:"column:model;type:varchar(100);default:''" json:"model"`
+	PromptHash       string    `gorm:"column:prompt_hash;type:char(64);default:''" json:"prompt_hash"` // 提示词SHA-256，不保存原文
+	PromptTokens     int       `gorm:"column:prompt_tokens;not null;default:0" json:"prompt_tokens"`
+	CompletionTokens int       `gorm:"column:completion_tokens;not null;default:0" json:"completion_tokens"`
+	Cost             float64   `gorm:"column:cost;type:decimal(12,6);not null;default:0" json:"cost"` // 按配置单价估算的费用（元）
+	LatencyMs        int64     `gorm:"column:latency_ms;not null;default:0" json:"latency_ms"`
+	Status           int8      `gorm:"column:status;not null;default:0" json:"status"` // 1=成功 2=失败
+	ErrorMsg         string    `gorm:"column:error_msg;type:varchar(500);default:''" json:"error_msg"`
+	QuestionIDs      UintList  `gorm:"column:question_ids;type:json" json:"question_ids"` // 本次调用产生或处理的题目ID
+	CreatedAt        time.Time `gorm:"column:created_at;autoCreateTime;index:idx_created_at" json:"created_at"`
+}
+
+// TableName 指定表名
+func (ExamLLMCall) TableName() string {
+	return "exam_llm_call"
+}
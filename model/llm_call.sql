@@ -0,0 +1,20 @@
+-- 大模型调用记录表
+CREATE TABLE IF NOT EXISTS `exam_llm_call` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+  `user_id` int(11) unsigned NOT NULL DEFAULT 0 COMMENT '发起调用的用户ID，0表示系统',
+  `scene` varchar(32) DEFAULT '' COMMENT '调用场景：generate=生成题目 job=异步生成任务 grade=AI评分 enrich=补充解析',
+  `provider` varchar(32) NOT NULL DEFAULT '' COMMENT '大模型服务提供方',
+  `model` varchar(100) DEFAULT '' COMMENT '模型名称',
+  `prompt_hash` char(64) DEFAULT '' COMMENT '提示词SHA-256',
+  `prompt_tokens` int(11) NOT NULL DEFAULT 0 COMMENT '输入token数',
+  `completion_tokens` int(11) NOT NULL DEFAULT 0 COMMENT '输出token数',
+  `cost` decimal(12,6) NOT NULL DEFAULT 0 COMMENT '估算费用（元）',
+  `latency_ms` bigint NOT NULL DEFAULT 0 COMMENT '耗时（毫秒）',
+  `status` tinyint NOT NULL DEFAULT 0 COMMENT '调用结果：1=成功 2=失败',
+  `error_msg` varchar(500) DEFAULT '' COMMENT '失败原因',
+  `question_ids` json DEFAULT NULL COMMENT '本次调用产生或处理的题目ID',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '调用时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_provider` (`provider`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='大模型调用记录表';
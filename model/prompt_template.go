@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// ExamPromptTemplate AI生成题目的提示词模板（Go text/template语法），每次修改新增一个版本，已有版本不可修改
+type ExamPromptTemplate struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string    `gorm:"column:name;type:varchar(64);not null;uniqueIndex:uk_name_version" json:"name"`
+	Version     int       `gorm:"column:version;not null;uniqueIndex:uk_name_version" json:"version"` // 版本号，从1开始递增
+	Content     string    `gorm:"column:content;type:text;not null" json:"content"`
+	Description string    `gorm:"column:description;type:varchar(500);default:''" json:"description"`
+	Enabled     bool      `gorm:"column:enabled;not null;default:1" json:"enabled"` // 是否启用，删除模板时停用全部版本（保留历史以便追溯题目）
+	CreatedBy   uint      `gorm:"column:created_by;not null;default:0" json:"created_by"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (ExamPromptTemplate) TableName() string {
+	return "exam_prompt_template"
+}
@@ -0,0 +1,13 @@
+-- AI生成题目提示词模板表（每次修改新增一个版本）
+CREATE TABLE IF NOT EXISTS `exam_prompt_template` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT COMMENT '模板版本ID',
+  `name` varchar(64) NOT NULL COMMENT '模板名称',
+  `version` int(11) NOT NULL COMMENT '版本号，从1开始递增',
+  `content` text NOT NULL COMMENT '模板内容（Go text/template语法）',
+  `description` varchar(500) DEFAULT '' COMMENT '模板说明',
+  `enabled` tinyint(1) NOT NULL DEFAULT 1 COMMENT '是否启用：1=启用 0=已删除（保留历史版本）',
+  `created_by` int(11) unsigned NOT NULL DEFAULT 0 COMMENT '创建该版本的用户ID',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_name_version` (`name`, `version`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='AI生成题目提示词模板表';
@@ -1,29 +1,123 @@
 package model
 
-import "time"
+import (
+	"database/sql/driver"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 const ExamQuestionsTableName = "exam_questions"
 
 // ExamQuestion 题目模型（适配GORM）
 type ExamQuestion struct {
-	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	QuestionType   int8      `gorm:"column:question_type;not null" json:"question_type"` // tinyint对应int8
-	QuestionTitle  string    `gorm:"column:question_title;type:varchar(500);not null" json:"question_title"`
-	OptionA        string    `gorm:"column:option_a;type:varchar(200);default:''" json:"option_a"`
-	OptionB        string    `gorm:"column:option_b;type:varchar(200);default:''" json:"option_b"`
-	OptionC        string    `gorm:"column:option_c;type:varchar(200);default:''" json:"option_c"`
-	OptionD        string    `gorm:"column:option_d;type:varchar(200);default:''" json:"option_d"`
-	CorrectAnswer  string    `gorm:"column:correct_answer;type:varchar(1000);not null" json:"correct_answer"`
-	AnswerAnalysis string    `gorm:"column:answer_analysis;type:varchar(2000);default:''" json:"answer_analysis"`
-	QuestionRemark string    `gorm:"column:question_remark;type:varchar(500);default:''" json:"question_remark"`
-	CreatedAt      time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`               // 自动生成创建时间
-	Tag            string    `gorm:"column:tag;type:varchar(50);default:''" json:"tag"`                // 对应一级分类（KnowledgeTree.Name）
-	SecondTag      string    `gorm:"column:second_tag;type:varchar(100);default:''" json:"second_tag"` // 对应二级分类（KnowledgeTree.SecondTag）
-	UpdatedAt      time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
-	UploadType     int8      `gorm:"column:upload_type;not null" json:"upload_type"` // 题目录入方式，默认0=手动 1=excel表格 2=豆包AI 3=阿里AI 4=云雾AI
+	ID               uint            `gorm:"primaryKey;autoIncrement" json:"id"`
+	QuestionType     int8            `gorm:"column:question_type;not null" json:"question_type"` // tinyint对应int8
+	QuestionTitle    string          `gorm:"column:question_title;type:varchar(500);not null" json:"question_title"`
+	Options          QuestionOptions `gorm:"column:options;type:json" json:"options"` // 按顺序排列的选项（A、B、C...），2-8个
+	Blanks           FillBlanks      `gorm:"column:blanks;type:json" json:"blanks"`   // 填空题各空的可接受答案
+	CorrectAnswer    string          `gorm:"column:correct_answer;type:varchar(1000);not null" json:"correct_answer"`
+	AnswerAnalysis   string          `gorm:"column:answer_analysis;type:varchar(2000);default:''" json:"answer_analysis"`
+	QuestionRemark   string          `gorm:"column:question_remark;type:varchar(500);default:''" json:"question_remark"`
+	CreatedAt        time.Time       `gorm:"column:created_at;autoCreateTime" json:"created_at"`               // 自动生成创建时间
+	Tag              string          `gorm:"column:tag;type:varchar(50);default:''" json:"tag"`                // 对应一级分类（KnowledgeTree.Name）
+	SecondTag        string          `gorm:"column:second_tag;type:varchar(100);default:''" json:"second_tag"` // 对应二级分类（KnowledgeTree.SecondTag）
+	UpdatedAt        time.Time       `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+	Status           int8            `gorm:"column:status;not null;default:0" json:"status"`           // 审核状态：0=已通过 1=待审核 2=已驳回
+	ReviewedBy       uint            `gorm:"column:reviewed_by;not null;default:0" json:"reviewed_by"` // 审核人用户ID
+	ReviewedAt       *time.Time      `gorm:"column:reviewed_at" json:"reviewed_at"`
+	RejectReason     string          `gorm:"column:reject_reason;type:varchar(500);default:''" json:"reject_reason"`
+	UploadType       int8            `gorm:"column:upload_type;not null" json:"upload_type"`                             // 题目录入方式，默认0=手动 1=excel表格 2=豆包AI 3=阿里AI 4=云雾AI 5=OpenAI兼容
+	DuplicateOf      uint            `gorm:"column:duplicate_of;not null;default:0" json:"duplicate_of"`                 // 疑似重复的已有题目ID，0表示未发现重复
+	PromptTemplateID uint            `gorm:"column:prompt_template_id;not null;default:0" json:"prompt_template_id"`     // AI生成时使用的提示词模板版本ID，0表示非AI生成
+	ExternalID       string          `gorm:"column:external_id;type:varchar(64);not null;default:''" json:"external_id"` // 外部题目编号（如Excel中的题目编号），重复导入时按编号更新已有题目
+	TitleFingerprint string          `gorm:"column:title_fingerprint;type:char(32);not null;default:''" json:"-"`        // 归一化题干的MD5指纹，查重时按索引查找完全重复的题目
+
+	// 关联关系
+	Tags []*ExamQuestionTag `json:"tags,omitempty" gorm:"foreignKey:QuestionID"` // 题目所属的全部分类（含主分类Tag/SecondTag）
+
+	// 兼容旧接口的固定选项字段（不落库）：读取时由Options的前四项填充，写入时Options为空则由其生成Options
+	OptionA string `gorm:"-" json:"option_a"`
+	OptionB string `gorm:"-" json:"option_b"`
+	OptionC string `gorm:"-" json:"option_c"`
+	OptionD string `gorm:"-" json:"option_d"`
 }
 
 // TableName 指定表名（GORM默认复数，需显式指定）
 func (ExamQuestion) TableName() string {
 	return "exam_questions"
 }
+
+// QuestionOptions 题目选项列表，以JSON数组存储，下标0对应选项A
+type QuestionOptions []string
+
+// Value 实现driver.Valuer
+func (o QuestionOptions) Value() (driver.Value, error) {
+	if o == nil {
+		return "[]", nil
+	}
+	return jsonValue([]string(o))
+}
+
+// Scan 实现sql.Scanner
+func (o *QuestionOptions) Scan(value interface{}) error {
+	*o = nil
+	return scanJSON(value, (*[]string)(o))
+}
+
+// FillBlank 填空题单个空的判分规则：命中任一可接受答案（归一化后比较）或正则表达式即判对
+type FillBlank struct {
+	Answers []string `json:"answers"`         // 可接受的答案（同义词）
+	Regex   string   `json:"regex,omitempty"` // 可选的正则表达式（忽略大小写）
+}
+
+// FillBlanks 填空题全部空，以JSON数组存储
+type FillBlanks []FillBlank
+
+// Value 实现driver.Valuer
+func (b FillBlanks) Value() (driver.Value, error) {
+	if b == nil {
+		return "[]", nil
+	}
+	return jsonValue([]FillBlank(b))
+}
+
+// Scan 实现sql.Scanner
+func (b *FillBlanks) Scan(value interface{}) error {
+	*b = nil
+	return scanJSON(value, (*[]FillBlank)(b))
+}
+
+// OptionLetter 返回第index个选项的字母（0→A）
+func OptionLetter(index int) string {
+	return string(rune('A' + index))
+}
+
+// OptionAt 返回第index个选项内容，不存在时返回空串
+func (q *ExamQuestion) OptionAt(index int) string {
+	if index < 0 || index >= len(q.Options) {
+		return ""
+	}
+	return q.Options[index]
+}
+
+// SyncOptions 同步选项列表与兼容字段：Options为空时由OptionA-D生成（去掉末尾空选项），再用Options回填OptionA-D
+func (q *ExamQuestion) SyncOptions() {
+	if len(q.Options) == 0 {
+		legacy := []string{q.OptionA, q.OptionB, q.OptionC, q.OptionD}
+		for len(legacy) > 0 && strings.TrimSpace(legacy[len(legacy)-1]) == "" {
+			legacy = legacy[:len(legacy)-1]
+		}
+		if len(legacy) > 0 {
+			q.Options = legacy
+		}
+	}
+	q.OptionA, q.OptionB, q.OptionC, q.OptionD = q.OptionAt(0), q.OptionAt(1), q.OptionAt(2), q.OptionAt(3)
+}
+
+// AfterFind 查询后回填兼容字段OptionA-D
+func (q *ExamQuestion) AfterFind(tx *gorm.DB) error {
+	q.SyncOptions()
+	return nil
+}
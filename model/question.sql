@@ -48,4 +48,67 @@ select * from exam_questions where id = 11;
 
 update exam_questions
 set tag='数据存储', second_tag='MySQL'
-where id > 0;
\ No newline at end of file
+where id > 0;
+
+-- 选项改为有序列表（JSON数组，2-8个），迁移原有的四个固定选项列
+ALTER TABLE exam_questions
+    ADD COLUMN options JSON NULL COMMENT '选项列表（JSON数组，按A、B、C...顺序，2-8个）' AFTER question_title;
+
+UPDATE exam_questions
+SET options = CASE
+    WHEN option_d <> '' THEN JSON_ARRAY(option_a, option_b, option_c, option_d)
+    WHEN option_c <> '' THEN JSON_ARRAY(option_a, option_b, option_c)
+    WHEN option_b <> '' THEN JSON_ARRAY(option_a, option_b)
+    WHEN option_a <> '' THEN JSON_ARRAY(option_a)
+    ELSE JSON_ARRAY()
+END
+WHERE id > 0;
+
+ALTER TABLE exam_questions
+    DROP COLUMN option_a,
+    DROP COLUMN option_b,
+    DROP COLUMN option_c,
+    DROP COLUMN option_d;
+
+
+-- 填空题支持多个空及每空多个可接受答案（JSON数组），为空的旧题目以correct_answer整体作为唯一答案判分（不按分隔符拆分）
+ALTER TABLE exam_questions
+    ADD COLUMN blanks JSON NULL COMMENT '填空题各空的可接受答案（JSON数组：[{"answers":[...],"regex":""}]）' AFTER correct_answer;
+
+
+ALTER TABLE exam_questions
+    MODIFY COLUMN upload_type TINYINT(1) NOT NULL COMMENT '题目录入方式，默认0=手动 1=excel表格 2=豆包AI 3=阿里AI 4=云雾AI 5=OpenAI兼容';
+
+
+-- 题目审核状态：AI生成的题目先进入待审核队列，审核通过后才进入正式题库
+ALTER TABLE exam_questions
+    ADD COLUMN status TINYINT NOT NULL DEFAULT 0 COMMENT '审核状态：0=已通过 1=待审核 2=已驳回' AFTER upload_type,
+    ADD COLUMN reviewed_by INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '审核人用户ID' AFTER status,
+    ADD COLUMN reviewed_at DATETIME DEFAULT NULL COMMENT '审核时间' AFTER reviewed_by,
+    ADD COLUMN reject_reason VARCHAR(500) DEFAULT '' COMMENT '驳回原因' AFTER reviewed_at,
+    ADD KEY idx_status (status);
+
+
+-- 题目查重：按“标记”策略入库的疑似重复题目，记录与之重复的已有题目ID
+ALTER TABLE exam_questions
+    ADD COLUMN duplicate_of INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '疑似重复的已有题目ID，0表示未发现重复' AFTER upload_type,
+    ADD KEY idx_duplicate_of (duplicate_of);
+
+
+-- 记录AI生成题目时使用的提示词模板版本
+ALTER TABLE exam_questions
+    ADD COLUMN prompt_template_id INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'AI生成时使用的提示词模板版本ID，0表示非AI生成' AFTER duplicate_of;
+
+
+-- 外部题目编号：Excel导入时按编号更新已有题目，避免重复导入
+ALTER TABLE exam_questions
+    ADD COLUMN external_id VARCHAR(64) NOT NULL DEFAULT '' COMMENT '外部题目编号（如Excel中的题目编号），为空表示无编号' AFTER prompt_template_id,
+    ADD KEY idx_external_id (external_id);
+
+
+-- 题干指纹：归一化题干的MD5，查重时按索引查找完全重复的题目（已有题目在服务启动时补充）；
+-- 近似重复只在同题型、同一级分类内比较
+ALTER TABLE exam_questions
+    ADD COLUMN title_fingerprint CHAR(32) NOT NULL DEFAULT '' COMMENT '归一化题干的MD5指纹，用于查重' AFTER question_title,
+    ADD KEY idx_type_fingerprint (question_type, title_fingerprint),
+    ADD KEY idx_type_tag (question_type, tag);
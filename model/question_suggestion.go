@@ -0,0 +1,47 @@
+package model
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+// ExamQuestionSuggestion AI为已有题目生成的补充建议（答案解析、难度、考察要点），编辑采纳后才写入题目
+type ExamQuestionSuggestion struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	QuestionID uint       `gorm:"column:question_id;not null;index:idx_question_id" json:"question_id"`
+	Status     int8       `gorm:"column:status;not null;default:0" json:"status"` // 0=待处理 1=已采纳 2=已忽略
+	Analysis   string     `gorm:"column:analysis;type:varchar(2000);default:''" json:"analysis"`
+	Difficulty string     `gorm:"column:difficulty;type:varchar(20);default:''" json:"difficulty"` // 简单/中等/困难
+	KeyPoints  StringList `gorm:"column:key_points;type:json" json:"key_points"`                   // 考察要点
+	Model      string     `gorm:"column:model;type:varchar(100);default:''" json:"model"`          // 生成建议的模型
+	CreatedBy  uint       `gorm:"column:created_by;not null;default:0" json:"created_by"`
+	ReviewedBy uint       `gorm:"column:reviewed_by;not null;default:0" json:"reviewed_by"` // 采纳或忽略的用户ID
+	ReviewedAt *time.Time `gorm:"column:reviewed_at" json:"reviewed_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+
+	// 关联关系
+	Question *ExamQuestion `json:"question,omitempty" gorm:"foreignKey:QuestionID"`
+}
+
+// TableName 指定表名
+func (ExamQuestionSuggestion) TableName() string {
+	return "exam_question_suggestion"
+}
+
+// StringList 字符串列表，以JSON数组存储
+type StringList []string
+
+// Value 实现driver.Valuer
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	return jsonValue([]string(l))
+}
+
+// Scan 实现sql.Scanner
+func (l *StringList) Scan(value interface{}) error {
+	*l = nil
+	return scanJSON(value, (*[]string)(l))
+}
@@ -0,0 +1,18 @@
+-- AI补充题目解析建议表（编辑采纳后才写入题目）
+CREATE TABLE IF NOT EXISTS `exam_question_suggestion` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT COMMENT '建议ID',
+  `question_id` int(11) unsigned NOT NULL COMMENT '题目ID',
+  `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态：0=待处理 1=已采纳 2=已忽略',
+  `analysis` varchar(2000) DEFAULT '' COMMENT '建议的答案解析',
+  `difficulty` varchar(20) DEFAULT '' COMMENT '建议的难度：简单/中等/困难',
+  `key_points` json DEFAULT NULL COMMENT '建议的考察要点（JSON数组）',
+  `model` varchar(100) DEFAULT '' COMMENT '生成建议的模型',
+  `created_by` int(11) unsigned NOT NULL DEFAULT 0 COMMENT '发起补充的用户ID',
+  `reviewed_by` int(11) unsigned NOT NULL DEFAULT 0 COMMENT '采纳或忽略的用户ID',
+  `reviewed_at` datetime DEFAULT NULL COMMENT '采纳或忽略时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_question_id` (`question_id`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='AI补充题目解析建议表';
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// ExamQuestionTag 题目-分类关联模型（一道题可属于多个分类，包含题目自身的主分类）
+type ExamQuestionTag struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	QuestionID uint      `gorm:"column:question_id;not null;uniqueIndex:uk_question_tag" json:"question_id"`
+	Tag        string    `gorm:"column:tag;type:varchar(50);not null;uniqueIndex:uk_question_tag;index:idx_tag" json:"tag"`
+	SecondTag  string    `gorm:"column:second_tag;type:varchar(100);not null;uniqueIndex:uk_question_tag;index:idx_tag" json:"second_tag"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (ExamQuestionTag) TableName() string {
+	return "exam_question_tag"
+}
@@ -0,0 +1,15 @@
+-- 题目-分类关联表（多对多，包含题目自身的主分类）
+CREATE TABLE IF NOT EXISTS `exam_question_tag` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT COMMENT '关联ID',
+  `question_id` int(11) unsigned NOT NULL COMMENT '题目ID',
+  `tag` varchar(50) NOT NULL COMMENT '一级分类',
+  `second_tag` varchar(100) NOT NULL COMMENT '二级（或更深层级）分类',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_question_tag` (`question_id`, `tag`, `second_tag`),
+  KEY `idx_tag` (`tag`, `second_tag`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='题目-分类关联表';
+
+-- 用已有题目的主分类初始化关联表
+INSERT IGNORE INTO `exam_question_tag` (`question_id`, `tag`, `second_tag`)
+SELECT `id`, `tag`, `second_tag` FROM `exam_questions` WHERE `tag` <> '';
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// ExamReviewCard 间隔复习卡片模型（SM-2），每个用户每道题一张卡片
+type ExamReviewCard struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID         uint       `gorm:"column:user_id;not null;uniqueIndex:uk_user_question" json:"user_id"`
+	QuestionID     uint       `gorm:"column:question_id;not null;uniqueIndex:uk_user_question" json:"question_id"`
+	Tag            string     `gorm:"column:tag;type:varchar(50);default:''" json:"tag"`
+	SecondTag      string     `gorm:"column:second_tag;type:varchar(100);default:''" json:"second_tag"`
+	EaseFactor     float64    `gorm:"column:ease_factor;not null;default:2.5" json:"ease_factor"`   // 难度系数
+	IntervalDays   int        `gorm:"column:interval_days;not null;default:0" json:"interval_days"` // 当前复习间隔（天）
+	Repetitions    int        `gorm:"column:repetitions;not null;default:0" json:"repetitions"`     // 连续答对次数
+	LapseCount     int        `gorm:"column:lapse_count;not null;default:0" json:"lapse_count"`     // 遗忘次数
+	ReviewCount    int        `gorm:"column:review_count;not null;default:0" json:"review_count"`   // 累计复习次数
+	LastRating     int8       `gorm:"column:last_rating;not null;default:0" json:"last_rating"`     // 最近一次自评等级
+	DueDate        time.Time  `gorm:"column:due_date;not null" json:"due_date"`                     // 下次复习日期
+	LastReviewedAt *time.Time `gorm:"column:last_reviewed_at" json:"last_reviewed_at"`
+	CreatedAt      time.Time  `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+
+	// 关联关系
+	Question *ExamQuestion `json:"question,omitempty" gorm:"foreignKey:QuestionID"`
+}
+
+// TableName 指定表名
+func (ExamReviewCard) TableName() string {
+	return "exam_review_card"
+}
+
+// ExamReviewSetting 复习计划设置（每个用户一行）
+type ExamReviewSetting struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     uint      `gorm:"column:user_id;not null;uniqueIndex:uk_user_id" json:"user_id"`
+	DailyLimit int       `gorm:"column:daily_limit;not null" json:"daily_limit"` // 每日复习上限
+	UpdatedAt  time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ExamReviewSetting) TableName() string {
+	return "exam_review_setting"
+}
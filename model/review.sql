@@ -0,0 +1,42 @@
+-- 间隔复习卡片表（SM-2）
+CREATE TABLE IF NOT EXISTS `exam_review_card` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT COMMENT '卡片ID',
+  `user_id` int(11) unsigned NOT NULL DEFAULT 0 COMMENT '用户ID',
+  `question_id` int(11) unsigned NOT NULL COMMENT '题目ID',
+  `tag` varchar(50) DEFAULT '' COMMENT '一级分类',
+  `second_tag` varchar(100) DEFAULT '' COMMENT '二级分类',
+  `ease_factor` double NOT NULL DEFAULT 2.5 COMMENT '难度系数',
+  `interval_days` int(11) NOT NULL DEFAULT 0 COMMENT '当前复习间隔（天）',
+  `repetitions` int(11) NOT NULL DEFAULT 0 COMMENT '连续答对次数',
+  `lapse_count` int(11) NOT NULL DEFAULT 0 COMMENT '遗忘次数',
+  `review_count` int(11) NOT NULL DEFAULT 0 COMMENT '累计复习次数',
+  `last_rating` tinyint NOT NULL DEFAULT 0 COMMENT '最近一次自评：0=忘记 1=困难 2=良好 3=简单',
+  `due_date` datetime NOT NULL COMMENT '下次复习日期',
+  `last_reviewed_at` datetime DEFAULT NULL COMMENT '最近复习时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_question` (`user_id`, `question_id`),
+  KEY `idx_due_date` (`due_date`),
+  KEY `idx_tag` (`tag`, `second_tag`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='间隔复习卡片表';
+
+-- 复习计划设置表（每个用户一行）
+CREATE TABLE IF NOT EXISTS `exam_review_setting` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT COMMENT '设置ID',
+  `user_id` int(11) unsigned NOT NULL DEFAULT 0 COMMENT '用户ID',
+  `daily_limit` int(11) NOT NULL COMMENT '每日复习上限',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='复习计划设置表';
+
+-- 间隔复习按用户隔离
+ALTER TABLE `exam_review_card`
+    ADD COLUMN `user_id` int(11) unsigned NOT NULL DEFAULT 0 COMMENT '用户ID' AFTER `id`,
+    DROP INDEX `uk_question_id`,
+    ADD UNIQUE KEY `uk_user_question` (`user_id`, `question_id`);
+
+ALTER TABLE `exam_review_setting`
+    ADD COLUMN `user_id` int(11) unsigned NOT NULL DEFAULT 0 COMMENT '用户ID' AFTER `id`,
+    ADD UNIQUE KEY `uk_user_id` (`user_id`);
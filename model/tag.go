@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// ExamTagPrimary 一级分类模型
+type ExamTagPrimary struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string    `gorm:"column:name;type:varchar(50);not null;uniqueIndex:uk_name" json:"name"`
+	SortOrder   int       `gorm:"column:sort_order;not null;default:0" json:"sort_order"` // 排序，越小越靠前
+	Description string    `gorm:"column:description;type:varchar(500);default:''" json:"description"`
+	Enabled     bool      `gorm:"column:enabled;not null;default:1" json:"enabled"` // 是否启用，停用后不出现在知识树中
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+
+	// 关联关系
+	Secondaries []*ExamTagSecondary `json:"secondaries,omitempty" gorm:"foreignKey:PrimaryID"`
+}
+
+// TableName 指定表名
+func (ExamTagPrimary) TableName() string {
+	return "exam_tag_primary"
+}
+
+// ExamTagSecondary 二级分类模型（通过ParentID可继续嵌套下级分类，名称在同一一级分类内唯一）
+type ExamTagSecondary struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	PrimaryID   uint      `gorm:"column:primary_id;not null;uniqueIndex:uk_primary_name" json:"primary_id"`
+	ParentID    uint      `gorm:"column:parent_id;not null;default:0;index:idx_parent_id" json:"parent_id"` // 上级分类ID，0表示直属于一级分类
+	Name        string    `gorm:"column:name;type:varchar(100);not null;uniqueIndex:uk_primary_name" json:"name"`
+	SortOrder   int       `gorm:"column:sort_order;not null;default:0" json:"sort_order"`
+	Description string    `gorm:"column:description;type:varchar(500);default:''" json:"description"`
+	Enabled     bool      `gorm:"column:enabled;not null;default:1" json:"enabled"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ExamTagSecondary) TableName() string {
+	return "exam_tag_secondary"
+}
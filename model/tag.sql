@@ -0,0 +1,33 @@
+-- 一级分类表
+CREATE TABLE IF NOT EXISTS `exam_tag_primary` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT COMMENT '一级分类ID',
+  `name` varchar(50) NOT NULL COMMENT '分类名称',
+  `sort_order` int(11) NOT NULL DEFAULT 0 COMMENT '排序（越小越靠前）',
+  `description` varchar(500) DEFAULT '' COMMENT '分类描述',
+  `enabled` tinyint(1) NOT NULL DEFAULT 1 COMMENT '是否启用：0=停用 1=启用',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='一级分类表';
+
+-- 二级分类表
+CREATE TABLE IF NOT EXISTS `exam_tag_secondary` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT COMMENT '二级分类ID',
+  `primary_id` int(11) unsigned NOT NULL COMMENT '所属一级分类ID',
+  `parent_id` int(11) unsigned NOT NULL DEFAULT 0 COMMENT '上级分类ID（0表示直属于一级分类）',
+  `name` varchar(100) NOT NULL COMMENT '分类名称',
+  `sort_order` int(11) NOT NULL DEFAULT 0 COMMENT '排序（越小越靠前）',
+  `description` varchar(500) DEFAULT '' COMMENT '分类描述',
+  `enabled` tinyint(1) NOT NULL DEFAULT 1 COMMENT '是否启用：0=停用 1=启用',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_primary_name` (`primary_id`, `name`),
+  KEY `idx_parent_id` (`parent_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='二级分类表';
+
+-- 支持多层级分类：二级分类可通过parent_id继续嵌套
+ALTER TABLE `exam_tag_secondary`
+    ADD COLUMN `parent_id` int(11) unsigned NOT NULL DEFAULT 0 COMMENT '上级分类ID（0表示直属于一级分类）' AFTER `primary_id`,
+    ADD KEY `idx_parent_id` (`parent_id`);
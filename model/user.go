@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// ExamUser 用户模型
+type ExamUser struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Username     string    `gorm:"column:username;type:varchar(32);not null;uniqueIndex:uk_username" json:"username"`
+	PasswordHash string    `gorm:"column:password_hash;type:varchar(100);not null" json:"-"` // bcrypt哈希，禁止返回给前端
+	Nickname     string    `gorm:"column:nickname;type:varchar(50);default:''" json:"nickname"`
+	Role         string    `gorm:"column:role;type:varchar(20);not null;default:'learner'" json:"role"` // 角色：admin/editor/learner
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ExamUser) TableName() string {
+	return "exam_user"
+}
+
+// ExamUserToken 用户登录态（只保存token的SHA-256摘要）
+type ExamUserToken struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint      `gorm:"column:user_id;not null;index:idx_user_id" json:"user_id"`
+	TokenHash string    `gorm:"column:token_hash;type:char(64);not null;uniqueIndex:uk_token_hash" json:"-"`
+	ExpiresAt time.Time `gorm:"column:expires_at;not null" json:"expires_at"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (ExamUserToken) TableName() string {
+	return "exam_user_token"
+}
@@ -0,0 +1,28 @@
+-- 用户表
+CREATE TABLE IF NOT EXISTS `exam_user` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT COMMENT '用户ID',
+  `username` varchar(32) NOT NULL COMMENT '用户名',
+  `password_hash` varchar(100) NOT NULL COMMENT '密码哈希（bcrypt）',
+  `nickname` varchar(50) DEFAULT '' COMMENT '昵称',
+  `role` varchar(20) NOT NULL DEFAULT 'learner' COMMENT '角色：admin=管理员 editor=编辑 learner=学员',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_username` (`username`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户表';
+
+-- 用户登录态表
+CREATE TABLE IF NOT EXISTS `exam_user_token` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT COMMENT '登录态ID',
+  `user_id` int(11) unsigned NOT NULL COMMENT '用户ID',
+  `token_hash` char(64) NOT NULL COMMENT 'token的SHA-256摘要',
+  `expires_at` datetime NOT NULL COMMENT '过期时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_token_hash` (`token_hash`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户登录态表';
+
+-- 新增用户角色
+ALTER TABLE `exam_user`
+    ADD COLUMN `role` varchar(20) NOT NULL DEFAULT 'learner' COMMENT '角色：admin=管理员 editor=编辑 learner=学员' AFTER `nickname`;
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// ExamWrongQuestion 错题本模型
+type ExamWrongQuestion struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      uint       `gorm:"column:user_id;not null;uniqueIndex:uk_user_question" json:"user_id"`
+	QuestionID  uint       `gorm:"column:question_id;not null;uniqueIndex:uk_user_question" json:"question_id"`
+	Tag         string     `gorm:"column:tag;type:varchar(50);default:''" json:"tag"`
+	SecondTag   string     `gorm:"column:second_tag;type:varchar(100);default:''" json:"second_tag"`
+	WrongCount  int        `gorm:"column:wrong_count;not null;default:1" json:"wrong_count"` // 累计答错次数
+	LastAnswer  string     `gorm:"column:last_answer;type:varchar(2000);default:''" json:"last_answer"`
+	LastWrongAt time.Time  `gorm:"column:last_wrong_at" json:"last_wrong_at"`
+	IsMastered  bool       `gorm:"column:is_mastered;not null;default:0" json:"is_mastered"` // 是否已掌握（已掌握的题目不参与错题练习）
+	MasteredAt  *time.Time `gorm:"column:mastered_at" json:"mastered_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+
+	// 关联关系
+	Question *ExamQuestion `json:"question,omitempty" gorm:"foreignKey:QuestionID"`
+}
+
+// TableName 指定表名
+func (ExamWrongQuestion) TableName() string {
+	return "exam_wrong_question"
+}
@@ -0,0 +1,25 @@
+-- 错题本表
+CREATE TABLE IF NOT EXISTS `exam_wrong_question` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT COMMENT '错题ID',
+  `user_id` int(11) unsigned NOT NULL DEFAULT 0 COMMENT '用户ID',
+  `question_id` int(11) unsigned NOT NULL COMMENT '题目ID',
+  `tag` varchar(50) DEFAULT '' COMMENT '一级分类',
+  `second_tag` varchar(100) DEFAULT '' COMMENT '二级分类',
+  `wrong_count` int(11) NOT NULL DEFAULT 1 COMMENT '累计答错次数',
+  `last_answer` varchar(2000) DEFAULT '' COMMENT '最近一次的错误答案',
+  `last_wrong_at` datetime DEFAULT NULL COMMENT '最近一次答错时间',
+  `is_mastered` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否已掌握：0=否 1=是',
+  `mastered_at` datetime DEFAULT NULL COMMENT '标记掌握时间',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_question` (`user_id`, `question_id`),
+  KEY `idx_tag` (`tag`),
+  KEY `idx_second_tag` (`second_tag`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='错题本表';
+
+-- 错题本按用户隔离：新增user_id，唯一索引改为(user_id, question_id)
+ALTER TABLE `exam_wrong_question`
+    ADD COLUMN `user_id` int(11) unsigned NOT NULL DEFAULT 0 COMMENT '用户ID' AFTER `id`,
+    DROP INDEX `uk_question_id`,
+    ADD UNIQUE KEY `uk_user_question` (`user_id`, `question_id`);
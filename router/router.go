@@ -5,7 +5,9 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/exam_system/consts"
 	"github.com/vaynedu/exam_system/handler"
+	"github.com/vaynedu/exam_system/middleware"
 )
 
 // InitRouter 初始化Gin路由
@@ -16,7 +18,7 @@ func InitRouter() *gin.Engine {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"}, // 允许所有来源（开发环境）
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -25,12 +27,8 @@ func InitRouter() *gin.Engine {
 	// API路由分组
 	api := r.Group("/api")
 	{
-		api.POST("/addQuestion", handler.AddQuestion)                 // 新增题目
-		api.POST("/importExcelQuestion", handler.ImportExcelQuestion) // Excel导入
-		api.POST("/exportExcelQuestion", handler.ExportExcelQuestion) // Excel导出
-		api.GET("/getRandom10", handler.GetRandom10Questions)         // 随机抽10题
-		api.GET("/tag/tree", handler.GetTagTree)                      // 获取标签树
-		api.POST("/generateAIQuestion", handler.GenerateAIQuestion)   // AI生成题目
+		api.GET("/getRandom10", handler.GetRandom10Questions) // 随机抽10题
+		api.GET("/tag/tree", handler.GetTagTree)              // 获取标签树
 		// 专项练习相关接口
 		r.GET("/api/specialQuestions", handler.GetSpecialQuestionsByFilter)
 
@@ -42,15 +40,110 @@ func InitRouter() *gin.Engine {
 		// 题库管理相关路由
 		api.GET("/questions", handler.GetQuestionsByFilter) // 获取题目列表（带筛选）
 		api.GET("/question/:id", handler.GetQuestionByID)   // 获取题目详情
-		api.PUT("/question/:id", handler.UpdateQuestion)    // 更新题目
-		api.DELETE("/question/:id", handler.DeleteQuestion) // 删除题目
-		
+
+		// 用户相关路由（无需登录）
+		api.POST("/user/register", handler.Register) // 用户注册
+		api.POST("/user/login", handler.Login)       // 用户登录
+	}
+
+	// 需要登录的API路由分组（按用户隔离数据）
+	auth := r.Group("/api", middleware.AuthRequired())
+	{
+		auth.POST("/user/logout", handler.Logout)              // 退出登录
+		auth.GET("/user/me", handler.GetCurrentUser)           // 获取当前用户
+		auth.GET("/userStatistics", handler.GetUserStatistics) // 当前用户学习统计
+
+		// 题库编辑相关路由（编辑及以上角色；删除、导出全部仅限管理员）
+		questionEdit := middleware.RequirePermission(consts.PermissionQuestionEdit)
+		questionDelete := middleware.RequirePermission(consts.PermissionQuestionDelete)
+		auth.POST("/addQuestion", questionEdit, handler.AddQuestion)                                // 新增题目
+		auth.POST("/importExcelQuestion", questionEdit, handler.ImportExcelQuestion)                // Excel导入
+		auth.POST("/importExcelQuestion/preview", questionEdit, handler.PreviewExcelQuestionImport) // Excel导入预览（report=true下载校验报告）
+		auth.GET("/importTemplate", questionEdit, handler.DownloadImportTemplate)                   // 下载Excel导入模板
+		auth.POST("/exportExcelQuestion", questionEdit, handler.ExportExcelQuestion)                // Excel导出（export_all需管理员）
+		auth.POST("/generateAIQuestion", questionEdit, handler.GenerateAIQuestion)                  // AI生成题目
+		auth.POST("/aiGenerateJob", questionEdit, handler.SubmitAIGenerateJob)                      // 提交AI生成题目异步任务
+		auth.GET("/aiGenerateJobs", questionEdit, handler.GetAIGenerateJobs)                        // 我的AI生成任务列表
+		auth.GET("/aiGenerateJob/:id", questionEdit, handler.GetAIGenerateJob)                      // 任务状态、进度及已生成的题目
+		auth.POST("/aiGenerateJob/:id/cancel", questionEdit, handler.CancelAIGenerateJob)           // 取消任务
+		auth.PUT("/question/:id", questionEdit, handler.UpdateQuestion)                             // 更新题目
+		auth.GET("/duplicateQuestions", questionEdit, handler.GetDuplicateQuestionClusters)         // 疑似重复题目聚类
+		auth.POST("/questionEnrichments", questionEdit, handler.EnrichQuestions)                    // 为已有题目生成AI补充建议
+		auth.GET("/questionSuggestions", questionEdit, handler.GetQuestionSuggestions)              // AI建议列表（默认待处理）
+		auth.POST("/questionSuggestion/:id/accept", questionEdit, handler.AcceptQuestionSuggestion) // 采纳建议
+		auth.POST("/questionSuggestion/:id/ignore", questionEdit, handler.IgnoreQuestionSuggestion) // 忽略建议
+		auth.DELETE("/question/:id", questionDelete, handler.DeleteQuestion)                        // 删除题目
+
+		// AI生成题目的提示词模板（编辑可查看，管理员可修改）
+		promptManage := middleware.RequirePermission(consts.PermissionPromptManage)
+		auth.GET("/promptTemplates", questionEdit, handler.GetPromptTemplates)             // 全部模板（最新版本）
+		auth.GET("/promptTemplate/:name", questionEdit, handler.GetPromptTemplateVersions) // 模板的全部版本
+		auth.POST("/promptTemplate", promptManage, handler.CreatePromptTemplate)           // 新增模板
+		auth.PUT("/promptTemplate/:name", promptManage, handler.UpdatePromptTemplate)      // 修改模板（保存为新版本）
+		auth.DELETE("/promptTemplate/:name", promptManage, handler.DeletePromptTemplate)   // 删除模板
+
+		// 大模型调用用量统计（管理员）
+		llmUsage := middleware.RequirePermission(consts.PermissionLLMUsageView)
+		auth.GET("/llmCallStats", llmUsage, handler.GetLLMCallStatistics) // 按天、按服务的token用量、耗时与费用
+
+		// AI生成题目审核队列（编辑及以上角色）
+		questionReview := middleware.RequirePermission(consts.PermissionQuestionReview)
+		auth.GET("/reviewQuestions", questionReview, handler.GetReviewQuestions)                   // 审核队列（默认待审核）
+		auth.PUT("/reviewQuestion/:id", questionReview, handler.UpdateReviewQuestion)              // 编辑待审核题目
+		auth.POST("/reviewQuestion/:id/approve", questionReview, handler.ApproveReviewQuestion)    // 审核通过
+		auth.POST("/reviewQuestion/:id/reject", questionReview, handler.RejectReviewQuestion)      // 驳回
+		auth.POST("/reviewQuestions/approve", questionReview, handler.BatchApproveReviewQuestions) // 批量审核通过
+		auth.POST("/reviewQuestions/reject", questionReview, handler.BatchRejectReviewQuestions)   // 批量驳回
+
+		// 用户管理相关路由（管理员）
+		userManage := middleware.RequirePermission(consts.PermissionUserManage)
+		auth.GET("/users", userManage, handler.GetUserList)            // 用户列表
+		auth.PUT("/user/:id/role", userManage, handler.UpdateUserRole) // 修改用户角色
+
+		// 分类管理相关路由（管理员）
+		tagManage := middleware.RequirePermission(consts.PermissionTagManage)
+		auth.GET("/tags", tagManage, handler.GetAllTags)                              // 全部分类（含停用）
+		auth.POST("/tag/primary", tagManage, handler.CreatePrimaryTag)                // 新增一级分类
+		auth.PUT("/tag/primary/:id", tagManage, handler.UpdatePrimaryTag)             // 更新一级分类
+		auth.DELETE("/tag/primary/:id", tagManage, handler.DeletePrimaryTag)          // 删除一级分类
+		auth.POST("/tag/secondary", tagManage, handler.CreateSecondaryTag)            // 新增二级分类
+		auth.PUT("/tag/secondary/:id", tagManage, handler.UpdateSecondaryTag)         // 更新二级分类
+		auth.DELETE("/tag/secondary/:id", tagManage, handler.DeleteSecondaryTag)      // 删除二级分类
+		auth.POST("/tag/primary/:id/rename", tagManage, handler.RenamePrimaryTag)     // 重命名一级分类（支持dry_run）
+		auth.POST("/tag/primary/:id/merge", tagManage, handler.MergePrimaryTag)       // 合并一级分类
+		auth.POST("/tag/secondary/:id/rename", tagManage, handler.RenameSecondaryTag) // 重命名二级分类
+		auth.POST("/tag/secondary/:id/merge", tagManage, handler.MergeSecondaryTag)   // 合并二级分类
+		auth.POST("/tag/secondary/:id/move", tagManage, handler.MoveSecondaryTag)     // 移动二级分类（跨一级分类或调整层级）
+
 		// 收藏相关路由
-		api.POST("/collection", handler.CreateCollection)                // 创建收藏
-		api.DELETE("/collection", handler.DeleteCollection)              // 删除收藏
-		api.GET("/collection/status", handler.GetCollectionStatus)        // 获取收藏状态
-		api.GET("/collection/batch/status", handler.BatchGetCollectionStatus) // 批量获取收藏状态
-		api.GET("/collections", handler.GetCollectionList)               // 获取收藏列表
+		auth.POST("/collection", handler.CreateCollection)                     // 创建收藏
+		auth.DELETE("/collection", handler.DeleteCollection)                   // 删除收藏
+		auth.GET("/collection/status", handler.GetCollectionStatus)            // 获取收藏状态
+		auth.GET("/collection/batch/status", handler.BatchGetCollectionStatus) // 批量获取收藏状态
+		auth.GET("/collections", handler.GetCollectionList)                    // 获取收藏列表
+
+		// 考试会话相关路由
+		auth.POST("/exam/session", handler.CreateExamSession)                    // 创建考试（随机抽题）
+		auth.GET("/exam/session/:id/questions", handler.GetExamSessionQuestions) // 获取考试题目（不含答案）
+		auth.POST("/exam/session/:id/answer", handler.SubmitExamAnswer)          // 提交单题答案
+		auth.POST("/exam/session/:id/answers", handler.BatchSubmitExamAnswers)   // 批量提交答案
+		auth.POST("/exam/session/:id/finish", handler.FinishExamSession)         // 交卷并判分
+		auth.GET("/exam/session/:id/result", handler.GetExamSessionResult)       // 获取考试结果
+		auth.POST("/exam/session/:id/selfReview", handler.SelfReviewExamAnswer)  // 问答题自评
+		auth.POST("/exam/session/:id/aiGrade", handler.AIGradeExamAnswer)        // 问答题AI评分
+		auth.GET("/exam/sessions", handler.GetExamSessionList)                   // 练习记录
+
+		// 错题本相关路由
+		auth.GET("/wrongQuestions", handler.GetWrongQuestionList)               // 获取错题列表
+		auth.POST("/wrongQuestion/mastered", handler.MarkWrongQuestionMastered) // 标记错题已掌握
+		auth.GET("/wrongQuestions/random", handler.GetRandomWrongQuestions)     // 错题随机练习
+
+		// 间隔复习相关路由
+		auth.GET("/review/due", handler.GetDueReviewQuestions)   // 今日待复习题目
+		auth.POST("/review/rate", handler.RateReview)            // 复习自评
+		auth.GET("/review/forecast", handler.GetReviewForecast)  // 未来待复习数量预测
+		auth.GET("/review/setting", handler.GetReviewSetting)    // 获取复习设置
+		auth.PUT("/review/setting", handler.UpdateReviewSetting) // 更新复习设置
 	}
 
 	return r
@@ -2,13 +2,13 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"strconv"
+	"sort"
 	"strings"
 
-	"github.com/vaynedu/exam_system/config"
 	"github.com/vaynedu/exam_system/consts"
-	"github.com/vaynedu/exam_system/dao"
 	"github.com/vaynedu/exam_system/model"
 	"github.com/vaynedu/exam_system/third_part"
 )
@@ -20,6 +20,19 @@ type GenerateAIQuestionRequest struct {
 	SecondTag    string `json:"second_tag"`
 	Count        int    `json:"count"`
 	Requirements string `json:"requirements"`
+	Provider     string `json:"provider"` // 大模型服务：doubao/ali/yunwu/openai，为空使用默认
+	Model        string `json:"model"`    // 模型名称，为空使用该服务的默认模型
+	// 与题库或同批次题目重复时的处理策略：skip（默认）/flag/merge
+	DuplicatePolicy string `json:"duplicate_policy"`
+	// 提示词模板名称及版本，为空使用默认模板、版本为0使用最新版本
+	PromptTemplate string `json:"prompt_template"`
+	PromptVersion  int    `json:"prompt_version"`
+	// 难度描述（如“中等”）及作为few-shot示例的题库题目ID，可选
+	Difficulty         string `json:"difficulty"`
+	ExampleQuestionIDs []uint `json:"example_question_ids"`
+
+	// 本次需要避免重复的已生成题干（异步任务中同一分类的后续批次使用）
+	ExcludeTitles []string `json:"-"`
 }
 
 func ValidateGenerateAIQuestionRequest(req *GenerateAIQuestionRequest) error {
@@ -37,8 +50,8 @@ func ValidateGenerateAIQuestionRequest(req *GenerateAIQuestionRequest) error {
 	if req.Count <= 0 {
 		return fmt.Errorf("无效的题目数量：%d", req.Count)
 	}
-	if req.Count > 10 {
-		return fmt.Errorf("题目数量不能超过10:%d", req.Count)
+	if req.Count > consts.AIGenerateSyncMaxCount {
+		return fmt.Errorf("题目数量不能超过%d:%d，更多题目请提交异步生成任务", consts.AIGenerateSyncMaxCount, req.Count)
 	}
 
 	// 4.题目描述不能很长
@@ -46,111 +59,259 @@ func ValidateGenerateAIQuestionRequest(req *GenerateAIQuestionRequest) error {
 		return fmt.Errorf("题目描述不能超过500个字符")
 	}
 
+	// 5. 大模型服务校验
+	if err := validateLLMProvider(req.Provider); err != nil {
+		return err
+	}
+
+	// 6. 重复题目处理策略校验
+	policy, err := validateDuplicatePolicy(req.DuplicatePolicy)
+	if err != nil {
+		return err
+	}
+	req.DuplicatePolicy = policy
+
+	// 7. 提示词模板、难度与示例题目校验
+	if err := validatePromptOptions(req.PromptTemplate, req.PromptVersion, req.Difficulty, req.ExampleQuestionIDs); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-// GenerateAIQuestionService 生成AI题目服务
-func GenerateAIQuestionService(ctx context.Context, questionType int, tag, secondTag string, count int, requirements string) ([]*model.ExamQuestion, error) {
+// AIQuestionRejection AI生成的题目未通过校验的原因
+type AIQuestionRejection struct {
+	Index         int    `json:"index"` // 在模型回复中的序号，从1开始
+	QuestionTitle string `json:"question_title"`
+	Reason        string `json:"reason"`
+	DuplicateOf   uint   `json:"duplicate_of,omitempty"` // 因与已有题目重复而未入库时，重复的题目ID
+}
 
-	// 这里可以创建让AI回答输出的模板
-	// 比如:  按照此格式Excel表头：题目类型、题干、选项A、选项B、选项C、选项D、正确答案、答案解析、题目备注、一级分类、二级分类; 其中题型取值：0=选择题、1=填空题、2=问答题
-	excelDesc := "按照此格式Excel表头:题目类型、题干、选项A、选项B、选项C、选项D、正确答案、答案解析、题目备注、一级分类、二级分类; 其中题型取值:0=选择题、1=填空题、2=问答题"
-	answerAnalysisDesc := "答案解析:针对正确答案做分析，务必要有此字段"
-	questionTypeDesc := fmt.Sprintf("生成题型是%s", consts.GetQuestionTypeName(questionType))
-	questionNumDesc := fmt.Sprintf("生成题目数量是%d", count)
-	QuestionRemarkDesc := "题目备注：来源、难度、考察点"
-	tagDesc := fmt.Sprintf("其中一级分类是%s,二级分类是%s", tag, secondTag)
-	requirementsDesc := fmt.Sprintf("题目描述是%s;%s;%s;%s;%s;%s;%s", requirements, excelDesc, answerAnalysisDesc, questionTypeDesc, questionNumDesc, QuestionRemarkDesc, tagDesc)
+// aiGeneratedQuestion 模型输出的单道题目
+type aiGeneratedQuestion struct {
+	QuestionType   *int     `json:"question_type"`
+	QuestionTitle  string   `json:"question_title"`
+	Options        []string `json:"options"`
+	CorrectAnswer  string   `json:"correct_answer"`
+	AnswerAnalysis string   `json:"answer_analysis"`
+	QuestionRemark string   `json:"question_remark"`
+	Tag            string   `json:"tag"`
+	SecondTag      string   `json:"second_tag"`
+}
 
-	// 调用第三方AI接口
-	generatedQuestionContent, err := third_part.NewDouBaoAiService().GetAiGenerateQuestion(ctx, requirementsDesc)
+// aiGenerateExcludeTitlesMax 提示词中最多列出的需要避免重复的题干数量
+const aiGenerateExcludeTitlesMax = 30
+
+// GenerateAIQuestionService 生成AI题目服务：按提示词模板要求模型以JSON输出题目，逐题按新增题目的规则校验，
+// 通过校验的题目以待审核状态入库（记录使用的模板版本），未通过的返回拒绝原因；今日token用量超出预算时拒绝生成
+func GenerateAIQuestionService(ctx context.Context, userID uint, req *GenerateAIQuestionRequest) ([]*model.ExamQuestion, []*AIQuestionRejection, error) {
+	llm, uploadType, err := resolveLLMProvider(req.Provider, req.Model)
 	if err != nil {
-		return nil, fmt.Errorf("调用AI接口失败:%w", err)
+		return nil, nil, err
 	}
-
-	// 将返回的markdown内容转化成模型
-	questions, err := parseMarkdownTable(generatedQuestionContent)
+	prompt, err := loadAIPromptContext(req.PromptTemplate, req.PromptVersion, req.ExampleQuestionIDs)
 	if err != nil {
-		return nil, fmt.Errorf("解析AI生成题目失败：%w", err)
+		return nil, nil, err
 	}
 
-	// 5. 批量插入数据库
-	if len(questions) > 0 {
-		if err = dao.NewQuestionDao(config.DB).CreateQuestionsInBatches(questions, 100); err != nil {
-			return nil, fmt.Errorf("保存AI生成题目失败：%w", err)
-		}
+	ctx, trace := withLLMCallTrace(ctx, consts.LLMCallSceneGenerate, req.Provider, userID)
+	questions, rejections, err := requestAIQuestions(ctx, llm, prompt, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	questions, rejections, err = saveAIGeneratedQuestions(questions, rejections, uploadType, req.DuplicatePolicy)
+	if err != nil {
+		return nil, nil, err
 	}
+	trace.linkQuestions(questionIDs(questions))
+	return questions, rejections, nil
+}
 
-	return questions, nil
+// questionIDs 题目ID列表
+func questionIDs(questions []*model.ExamQuestion) []uint {
+	ids := make([]uint, 0, len(questions))
+	for _, question := range questions {
+		ids = append(ids, question.ID)
+	}
+	return ids
 }
 
-func parseMarkdownTable(table string) ([]*model.ExamQuestion, error) {
-	lines := strings.Split(table, "\n")
-	var questions []*model.ExamQuestion
+// buildAIGeneratePrompt 渲染生成题目的提示词，并追加需要避免重复的已生成题干
+func buildAIGeneratePrompt(prompt *aiPromptContext, req *GenerateAIQuestionRequest) (string, error) {
+	content, err := renderPromptTemplate(prompt.compiled, &PromptTemplateData{
+		Tag:              req.Tag,
+		SecondTag:        req.SecondTag,
+		QuestionType:     req.QuestionType,
+		QuestionTypeName: consts.GetQuestionTypeName(req.QuestionType),
+		Count:            req.Count,
+		Difficulty:       req.Difficulty,
+		Requirements:     req.Requirements,
+		Examples:         prompt.examples,
+	})
+	if err != nil {
+		return "", fmt.Errorf("渲染提示词模板%s第%d版失败：%w", prompt.template.Name, prompt.template.Version, err)
+	}
 
-	// 跳过表头行和分隔行
-	for i := 2; i < len(lines); i++ {
-		line := strings.TrimSpace(lines[i])
-		if line == "" {
-			continue
+	excludes := req.ExcludeTitles
+	if len(excludes) > aiGenerateExcludeTitlesMax {
+		excludes = excludes[len(excludes)-aiGenerateExcludeTitlesMax:]
+	}
+	if len(excludes) > 0 {
+		var builder strings.Builder
+		builder.WriteString(content)
+		builder.WriteString("\n\n以下题目已经生成过，请不要出相同或相似的题目：")
+		for i, title := range excludes {
+			builder.WriteString(fmt.Sprintf("\n%d. %s", i+1, title))
 		}
+		content = builder.String()
+	}
+	return content, nil
+}
 
-		// 分割行内容
-		fields := strings.Split(line, "|")
-		if len(fields) < 11 {
-			continue // 跳过无效行
-		}
+// requestAIQuestions 调用大模型生成一批题目并逐题校验（不入库），题目记录使用的提示词模板版本
+func requestAIQuestions(ctx context.Context, llm third_part.LLMProvider, prompt *aiPromptContext, req *GenerateAIQuestionRequest) ([]*model.ExamQuestion, []*AIQuestionRejection, error) {
+	content, err := buildAIGeneratePrompt(prompt, req)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		// 提取字段（去除首尾空格）
-		trimFields := make([]string, 0, 11)
-		for j := 1; j < len(fields)-1; j++ { // 跳过首尾空字段
-			trimFields = append(trimFields, strings.TrimSpace(fields[j]))
-		}
+	// 调用第三方AI接口
+	content, err = chatJSON(ctx, llm, content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("调用AI接口失败:%w", err)
+	}
 
-		if len(trimFields) != 11 {
-			fmt.Println("字段数量不匹配", trimFields, len(trimFields), fields, len(fields))
-			continue // 跳过字段数量不匹配的行
+	// 将返回的JSON内容转化成模型并逐题校验
+	questions, rejections, err := parseAIGeneratedQuestions(content, req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("解析AI生成题目失败：%w", err)
+	}
+	for _, question := range questions {
+		question.PromptTemplateID = prompt.template.ID
+	}
+	return questions, rejections, nil
+}
+
+// saveAIGeneratedQuestions 将通过校验的AI题目以待审核状态查重入库，跳过或合并的重复题目计入未入库的题目
+func saveAIGeneratedQuestions(questions []*model.ExamQuestion, rejections []*AIQuestionRejection, uploadType int8, duplicatePolicy string) ([]*model.ExamQuestion, []*AIQuestionRejection, error) {
+	if len(questions) == 0 {
+		return questions, rejections, nil
+	}
+	for _, question := range questions {
+		question.UploadType = uploadType
+		question.Status = consts.QuestionStatusPending // 进入待审核队列，审核通过后才进入正式题库
+	}
+
+	saved, duplicates, err := saveQuestionsWithDedupe(questions, aiQuestionIndexes(len(questions), rejections), duplicatePolicy)
+	if err != nil {
+		return nil, nil, fmt.Errorf("保存AI生成题目失败：%w", err)
+	}
+	for _, duplicate := range duplicates {
+		if duplicate.Action == consts.DuplicatePolicyFlag {
+			continue
 		}
+		rejections = append(rejections, &AIQuestionRejection{
+			Index:         duplicate.Index,
+			QuestionTitle: duplicate.QuestionTitle,
+			Reason:        duplicate.ActionDescription(),
+			DuplicateOf:   duplicate.DuplicateOf,
+		})
+	}
+	sort.SliceStable(rejections, func(i, j int) bool { return rejections[i].Index < rejections[j].Index })
+	return saved, rejections, nil
+}
 
-		// 解析题目类型
-		qType := int8(0)
-		if t, err := strconv.ParseInt(trimFields[0], 10, 8); err == nil {
-			qType = int8(t)
+// aiQuestionIndexes 通过校验的题目在模型回复中的序号（回复中的每道题不是通过校验就是被拒绝）
+func aiQuestionIndexes(accepted int, rejections []*AIQuestionRejection) []int {
+	rejected := make(map[int]bool, len(rejections))
+	for _, rejection := range rejections {
+		rejected[rejection.Index] = true
+	}
+	indexes := make([]int, 0, accepted)
+	for i := 1; len(indexes) < accepted; i++ {
+		if !rejected[i] {
+			indexes = append(indexes, i)
 		}
+	}
+	return indexes
+}
 
-		// 创建问题对象
-		// |题目类型|题干|选项A|选项B|选项C|选项D|正确答案|答案解析|题目备注|一级分类|二级分类|
-		q := &model.ExamQuestion{
-			QuestionType:   qType,
-			QuestionTitle:  trimFields[1],
-			OptionA:        trimFields[2],
-			OptionB:        trimFields[3],
-			OptionC:        trimFields[4],
-			OptionD:        trimFields[5],
-			CorrectAnswer:  trimFields[6],
-			AnswerAnalysis: trimFields[7],
-			QuestionRemark: trimFields[8],
-			Tag:            trimFields[9],
-			SecondTag:      trimFields[10],
-			UploadType:     consts.QuestionImportTypeAiDouBao, // 默认为AI生成
+// parseAIGeneratedQuestions 解析模型输出的题目JSON（{"questions":[...]}或直接为数组），逐题校验：
+// 题型需与要求一致，未填写分类时使用要求的分类，超出要求数量的题目不入库；回复整体无法解析时返回错误
+func parseAIGeneratedQuestions(content string, req *GenerateAIQuestionRequest) ([]*model.ExamQuestion, []*AIQuestionRejection, error) {
+	payload, err := extractJSONPayload(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var items []json.RawMessage
+	if strings.HasPrefix(payload, "[") {
+		err = json.Unmarshal([]byte(payload), &items)
+	} else {
+		var wrapper struct {
+			Questions []json.RawMessage `json:"questions"`
 		}
+		err = json.Unmarshal([]byte(payload), &wrapper)
+		items = wrapper.Questions
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil, errors.New("回复中没有题目")
+	}
 
-		// 针对tag检查, 这里要使用log代替
-		if !IsValidPrimaryTag(q.Tag) {
-			fmt.Printf("无效的标签关系：%s-%s", q.Tag, q.SecondTag)
+	var questions []*model.ExamQuestion
+	var rejections []*AIQuestionRejection
+	for i, raw := range items {
+		var item aiGeneratedQuestion
+		if err := json.Unmarshal(raw, &item); err != nil {
+			rejections = append(rejections, &AIQuestionRejection{Index: i + 1, Reason: "题目格式错误：" + err.Error()})
 			continue
 		}
-		if !IsSecondaryOfPrimary(q.Tag, q.SecondTag) {
-			fmt.Printf("无效的标签关系：%s-%s", q.Tag, q.SecondTag)
-			continue
+		question, err := buildAIGeneratedQuestion(&item, req)
+		if err == nil && len(questions) >= req.Count {
+			err = fmt.Errorf("超出要求的题目数量%d", req.Count)
 		}
-		if err := validateTagRelation(q.Tag, q.SecondTag); err != nil {
-			fmt.Printf("无效的标签关系：%s-%s", q.Tag, q.SecondTag)
+		if err != nil {
+			rejections = append(rejections, &AIQuestionRejection{Index: i + 1, QuestionTitle: item.QuestionTitle, Reason: err.Error()})
 			continue
 		}
+		questions = append(questions, question)
+	}
+	return questions, rejections, nil
+}
 
-		questions = append(questions, q)
+// buildAIGeneratedQuestion 将模型输出的单道题目转为题目模型，并复用新增题目的校验规则
+func buildAIGeneratedQuestion(item *aiGeneratedQuestion, req *GenerateAIQuestionRequest) (*model.ExamQuestion, error) {
+	if item.QuestionType == nil {
+		return nil, errors.New("缺少题型question_type")
+	}
+	if *item.QuestionType != req.QuestionType {
+		return nil, fmt.Errorf("题型%d与要求的题型%d不一致", *item.QuestionType, req.QuestionType)
+	}
+	if strings.TrimSpace(item.AnswerAnalysis) == "" {
+		return nil, errors.New("缺少答案解析")
 	}
 
-	return questions, nil
+	question := &model.ExamQuestion{
+		QuestionType:   int8(*item.QuestionType),
+		QuestionTitle:  strings.TrimSpace(item.QuestionTitle),
+		Options:        item.Options,
+		CorrectAnswer:  item.CorrectAnswer,
+		AnswerAnalysis: strings.TrimSpace(item.AnswerAnalysis),
+		QuestionRemark: strings.TrimSpace(item.QuestionRemark),
+		Tag:            strings.TrimSpace(item.Tag),
+		SecondTag:      strings.TrimSpace(item.SecondTag),
+	}
+	if question.Tag == "" && question.SecondTag == "" {
+		question.Tag, question.SecondTag = req.Tag, req.SecondTag
+	}
+	if !consts.IsOptionQuestionType(*item.QuestionType) {
+		question.Options = nil
+	}
+	if err := validateQuestion(question); err != nil {
+		return nil, err
+	}
+	return question, nil
 }
@@ -0,0 +1,417 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/vaynedu/exam_system/config"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/model"
+	"github.com/vaynedu/exam_system/third_part"
+	"gorm.io/gorm"
+)
+
+// SubmitAIGenerateJobRequest 提交AI生成题目任务的请求，可一次为多个分类各生成指定数量的题目
+type SubmitAIGenerateJobRequest struct {
+	QuestionType    int                         `json:"question_type"`
+	Targets         []model.AIGenerateJobTarget `json:"targets"`
+	Requirements    string                      `json:"requirements"`
+	Provider        string                      `json:"provider"`         // 大模型服务，为空使用默认
+	Model           string                      `json:"model"`            // 模型名称，为空使用该服务的默认模型
+	DuplicatePolicy string                      `json:"duplicate_policy"` // 重复题目处理策略：skip（默认）/flag/merge
+	// 提示词模板名称及版本（为空使用默认模板、版本为0使用最新版本，提交时确定版本），难度描述及示例题目ID
+	PromptTemplate     string `json:"prompt_template"`
+	PromptVersion      int    `json:"prompt_version"`
+	Difficulty         string `json:"difficulty"`
+	ExampleQuestionIDs []uint `json:"example_question_ids"`
+}
+
+// AIGenerateJobDetail 任务详情：任务进度及已入库的题目
+type AIGenerateJobDetail struct {
+	*model.AIGenerateJob
+	StatusName string               `json:"status_name"`
+	Progress   int                  `json:"progress"` // 进度百分比（按已处理批次计算）
+	Questions  []model.ExamQuestion `json:"questions,omitempty"`
+}
+
+// aiGenerateRetryBackoff 单批调用失败后的首次重试等待时间，之后每次翻倍
+var aiGenerateRetryBackoff = 2 * time.Second
+
+// ValidateSubmitAIGenerateJobRequest 校验任务参数：题型、各分类及数量、要求描述、大模型服务与重复策略
+func ValidateSubmitAIGenerateJobRequest(req *SubmitAIGenerateJobRequest) error {
+	if req.QuestionType != consts.QuestionTypeShortAnswer {
+		return errors.New("当前仅支持生成问答题")
+	}
+	if len(req.Targets) == 0 {
+		return errors.New("请至少指定一个分类及题目数量")
+	}
+	if len(req.Targets) > consts.AIGenerateJobMaxTargets {
+		return fmt.Errorf("单个任务最多指定%d个分类", consts.AIGenerateJobMaxTargets)
+	}
+
+	total := 0
+	seen := make(map[dao.TagRef]bool, len(req.Targets))
+	for i := range req.Targets {
+		target := &req.Targets[i]
+		if err := validateTagRelation(target.Tag, target.SecondTag); err != nil {
+			return fmt.Errorf("无效的标签关系：%s-%s", target.Tag, target.SecondTag)
+		}
+		ref := dao.TagRef{Tag: target.Tag, SecondTag: target.SecondTag}
+		if seen[ref] {
+			return fmt.Errorf("分类重复：%s-%s", target.Tag, target.SecondTag)
+		}
+		seen[ref] = true
+		if target.Count <= 0 {
+			return fmt.Errorf("无效的题目数量：%s-%s %d", target.Tag, target.SecondTag, target.Count)
+		}
+		total += target.Count
+	}
+	if total > consts.AIGenerateJobMaxCount {
+		return fmt.Errorf("单个任务的题目总数不能超过%d:%d", consts.AIGenerateJobMaxCount, total)
+	}
+
+	if len(req.Requirements) > 500 {
+		return errors.New("题目描述不能超过500个字符")
+	}
+	if err := validateLLMProvider(req.Provider); err != nil {
+		return err
+	}
+	policy, err := validateDuplicatePolicy(req.DuplicatePolicy)
+	if err != nil {
+		return err
+	}
+	req.DuplicatePolicy = policy
+	return validatePromptOptions(req.PromptTemplate, req.PromptVersion, req.Difficulty, req.ExampleQuestionIDs)
+}
+
+// SubmitAIGenerateJobService 提交AI生成题目任务：保存任务后在后台按批生成，立即返回任务
+func SubmitAIGenerateJobService(userID uint, req *SubmitAIGenerateJobRequest) (*model.AIGenerateJob, error) {
+	if err := ValidateSubmitAIGenerateJobRequest(req); err != nil {
+		return nil, err
+	}
+
+	if err := checkLLMTokenBudget(llmProviderName(req.Provider)); err != nil {
+		return nil, err
+	}
+	prompt, err := loadAIPromptContext(req.PromptTemplate, req.PromptVersion, req.ExampleQuestionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, target := range req.Targets {
+		total += target.Count
+	}
+	job := &model.AIGenerateJob{
+		UserID: userID,
+		Status: consts.AIGenerateJobStatusQueued,
+		Params: model.AIGenerateJobParams{
+			QuestionType:       req.QuestionType,
+			Targets:            req.Targets,
+			Requirements:       req.Requirements,
+			Provider:           req.Provider,
+			Model:              req.Model,
+			DuplicatePolicy:    req.DuplicatePolicy,
+			PromptTemplateID:   prompt.template.ID,
+			Difficulty:         req.Difficulty,
+			ExampleQuestionIDs: req.ExampleQuestionIDs,
+		},
+		TotalCount:  total,
+		TotalChunks: len(splitAIGenerateChunks(req.Targets, consts.AIGenerateChunkSize)),
+		QuestionIDs: model.UintList{},
+		Rejections:  model.AIGenerateRejections{},
+	}
+	if err := dao.NewAIGenerateJobDao(config.DB).CreateJob(job); err != nil {
+		return nil, fmt.Errorf("创建任务失败：%w", err)
+	}
+
+	aiGenerateJobs.start(job)
+	return job, nil
+}
+
+// GetAIGenerateJobService 获取任务详情（只能查看自己提交的任务），包含已入库的题目
+func GetAIGenerateJobService(userID, jobID uint) (*AIGenerateJobDetail, error) {
+	job, err := getUserAIGenerateJob(userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	questions, err := dao.NewQuestionDao(config.DB).GetQuestionsByIDList(job.QuestionIDs)
+	if err != nil {
+		return nil, err
+	}
+	detail := newAIGenerateJobDetail(job)
+	detail.Questions = questions
+	return detail, nil
+}
+
+// GetAIGenerateJobsService 分页获取用户提交的任务（不含题目内容）
+func GetAIGenerateJobsService(userID uint, page, size int) ([]*AIGenerateJobDetail, int64, error) {
+	jobs, total, err := dao.NewAIGenerateJobDao(config.DB).GetJobsByUser(userID, page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	details := make([]*AIGenerateJobDetail, 0, len(jobs))
+	for _, job := range jobs {
+		details = append(details, newAIGenerateJobDetail(job))
+	}
+	return details, total, nil
+}
+
+// CancelAIGenerateJobService 取消排队中或生成中的任务，已入库的题目保留
+func CancelAIGenerateJobService(userID, jobID uint) error {
+	job, err := getUserAIGenerateJob(userID, jobID)
+	if err != nil {
+		return err
+	}
+	if consts.IsAIGenerateJobFinished(int(job.Status)) {
+		return fmt.Errorf("任务%s，无法取消", consts.GetAIGenerateJobStatusName(int(job.Status)))
+	}
+
+	canceled, err := dao.NewAIGenerateJobDao(config.DB).CancelJob(jobID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !canceled {
+		return errors.New("任务已结束，无法取消")
+	}
+	aiGenerateJobs.cancel(jobID)
+	return nil
+}
+
+// RecoverAIGenerateJobsService 服务启动时将上次未执行完的任务标记为失败（任务只在内存中执行，重启后无法继续）
+func RecoverAIGenerateJobsService() error {
+	count, err := dao.NewAIGenerateJobDao(config.DB).FailUnfinishedJobs("服务重启，任务中断（已生成的题目已保存）", time.Now())
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		log.Printf("已将%d个中断的AI生成题目任务标记为失败", count)
+	}
+	return nil
+}
+
+// getUserAIGenerateJob 获取用户自己提交的任务
+func getUserAIGenerateJob(userID, jobID uint) (*model.AIGenerateJob, error) {
+	job, err := dao.NewAIGenerateJobDao(config.DB).GetJobByID(jobID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("任务不存在")
+		}
+		return nil, err
+	}
+	if job.UserID != userID {
+		return nil, errors.New("任务不存在")
+	}
+	return job, nil
+}
+
+// newAIGenerateJobDetail 构建任务详情（状态名称与进度）
+func newAIGenerateJobDetail(job *model.AIGenerateJob) *AIGenerateJobDetail {
+	progress := 0
+	if job.TotalChunks > 0 {
+		progress = job.FinishedChunks * 100 / job.TotalChunks
+	}
+	if job.Status == consts.AIGenerateJobStatusSucceeded {
+		progress = 100
+	}
+	return &AIGenerateJobDetail{
+		AIGenerateJob: job,
+		StatusName:    consts.GetAIGenerateJobStatusName(int(job.Status)),
+		Progress:      progress,
+	}
+}
+
+// splitAIGenerateChunks 将各分类的题目数量按批次大小拆分，每批为同一分类的不超过size道题
+func splitAIGenerateChunks(targets []model.AIGenerateJobTarget, size int) []model.AIGenerateJobTarget {
+	var chunks []model.AIGenerateJobTarget
+	for _, target := range targets {
+		for remaining := target.Count; remaining > 0; remaining -= size {
+			chunk := target
+			chunk.Count = min(remaining, size)
+			chunks = append(chunks, chunk)
+		}
+	}
+	return chunks
+}
+
+// retryAIGenerate 执行fn，临时性错误（网络错误、超时、HTTP 429及5xx）按指数退避重试最多maxRetries次；
+// 其他错误（如回复无法解析、提示词渲染失败、超出token预算）及ctx取消时立即返回
+func retryAIGenerate(ctx context.Context, maxRetries int, fn func() error) error {
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(aiGenerateRetryBackoff << (attempt - 1)):
+			}
+		}
+		if err = fn(); err == nil || ctx.Err() != nil || !third_part.IsTransientError(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// aiGenerateJobRunner 在后台执行任务：限制同时执行的任务数，记录执行中任务的取消函数
+type aiGenerateJobRunner struct {
+	mu      sync.Mutex
+	cancels map[uint]context.CancelFunc
+	slots   chan struct{}
+}
+
+// aiGenerateJobs 全局任务执行器
+var aiGenerateJobs = newAIGenerateJobRunner(consts.AIGenerateJobMaxRunning)
+
+func newAIGenerateJobRunner(maxRunning int) *aiGenerateJobRunner {
+	return &aiGenerateJobRunner{
+		cancels: make(map[uint]context.CancelFunc),
+		slots:   make(chan struct{}, maxRunning),
+	}
+}
+
+// start 在后台执行任务，执行名额已满时排队等待
+func (r *aiGenerateJobRunner) start(job *model.AIGenerateJob) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.mu.Lock()
+	r.cancels[job.ID] = cancel
+	r.mu.Unlock()
+
+	go func() {
+		defer r.cancel(job.ID)
+		select {
+		case r.slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		defer func() { <-r.slots }()
+		runAIGenerateJob(ctx, job)
+	}()
+}
+
+// cancel 取消执行中的任务（不在本进程中执行时忽略）
+func (r *aiGenerateJobRunner) cancel(jobID uint) {
+	r.mu.Lock()
+	cancel, ok := r.cancels[jobID]
+	delete(r.cancels, jobID)
+	r.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+// runAIGenerateJob 执行任务：逐批调用大模型生成题目并入库，每批完成后保存进度；
+// 单批失败时重试，重试后仍失败的批次记录原因并继续后续批次，全部批次失败时任务失败
+func runAIGenerateJob(ctx context.Context, job *model.AIGenerateJob) {
+	jobDao := dao.NewAIGenerateJobDao(config.DB)
+	fail := func(msg string) {
+		if err := jobDao.FinishJob(job.ID, consts.AIGenerateJobStatusFailed, msg, time.Now()); err != nil {
+			log.Printf("AI生成题目任务%d更新失败状态失败：%v", job.ID, err)
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("AI生成题目任务%d异常：%v", job.ID, r)
+			fail(fmt.Sprintf("任务执行异常：%v", r))
+		}
+	}()
+
+	started, err := jobDao.StartJob(job.ID, time.Now())
+	if err != nil || !started {
+		return // 已被取消
+	}
+
+	params := job.Params
+	llm, uploadType, err := resolveLLMProvider(params.Provider, params.Model)
+	if err != nil {
+		fail(err.Error())
+		return
+	}
+	prompt, err := loadAIPromptContextByID(params.PromptTemplateID, params.ExampleQuestionIDs)
+	if err != nil {
+		fail(err.Error())
+		return
+	}
+
+	generatedTitles := make(map[dao.TagRef][]string)
+	for i, chunk := range splitAIGenerateChunks(params.Targets, consts.AIGenerateChunkSize) {
+		if ctx.Err() != nil {
+			return
+		}
+		ref := dao.TagRef{Tag: chunk.Tag, SecondTag: chunk.SecondTag}
+		req := &GenerateAIQuestionRequest{
+			QuestionType:    params.QuestionType,
+			Tag:             chunk.Tag,
+			SecondTag:       chunk.SecondTag,
+			Count:           chunk.Count,
+			Requirements:    params.Requirements,
+			DuplicatePolicy: params.DuplicatePolicy,
+			Difficulty:      params.Difficulty,
+			ExcludeTitles:   generatedTitles[ref],
+		}
+
+		var questions []*model.ExamQuestion
+		var rejections []*AIQuestionRejection
+		chunkCtx, trace := withLLMCallTrace(ctx, consts.LLMCallSceneJob, params.Provider, job.UserID)
+		err := retryAIGenerate(ctx, consts.AIGenerateChunkMaxRetries, func() error {
+			var err error
+			questions, rejections, err = requestAIQuestions(chunkCtx, llm, prompt, req)
+			return err
+		})
+		if err == nil {
+			questions, rejections, err = saveAIGeneratedQuestions(questions, rejections, uploadType, params.DuplicatePolicy)
+		}
+		if err == nil {
+			trace.linkQuestions(questionIDs(questions))
+		}
+		if err != nil && ctx.Err() != nil {
+			return // 取消导致本批未完成，本批没有题目入库
+		}
+
+		job.FinishedChunks++
+		if err != nil {
+			job.FailedChunks++
+			job.Rejections = append(job.Rejections, model.AIGenerateRejection{
+				Chunk:  i + 1,
+				Reason: fmt.Sprintf("%s-%s的%d道题生成失败：%v", chunk.Tag, chunk.SecondTag, chunk.Count, err),
+			})
+		}
+		for _, question := range questions {
+			job.QuestionIDs = append(job.QuestionIDs, question.ID)
+			generatedTitles[ref] = append(generatedTitles[ref], question.QuestionTitle)
+		}
+		for _, rejection := range rejections {
+			job.Rejections = append(job.Rejections, model.AIGenerateRejection{
+				Chunk:         i + 1,
+				Index:         rejection.Index,
+				QuestionTitle: rejection.QuestionTitle,
+				Reason:        rejection.Reason,
+				DuplicateOf:   rejection.DuplicateOf,
+			})
+		}
+
+		saved, err := jobDao.SaveJobProgress(job)
+		if err != nil {
+			log.Printf("AI生成题目任务%d保存进度失败：%v", job.ID, err)
+		} else if !saved {
+			// 已被取消：本批在取消前已入库的题目仍需关联到任务（取消时提示已生成的题目保留）
+			if err := jobDao.SaveCanceledJobProgress(job); err != nil {
+				log.Printf("AI生成题目任务%d保存进度失败：%v", job.ID, err)
+			}
+			return
+		}
+	}
+
+	if job.TotalChunks > 0 && job.FailedChunks == job.TotalChunks {
+		fail("全部批次生成失败，请查看失败原因")
+		return
+	}
+	if err := jobDao.FinishJob(job.ID, consts.AIGenerateJobStatusSucceeded, "", time.Now()); err != nil {
+		log.Printf("AI生成题目任务%d更新完成状态失败：%v", job.ID, err)
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+	"github.com/vaynedu/exam_system/third_part"
+	arkmodel "github.com/volcengine/volcengine-go-sdk/service/arkruntime/model"
+)
+
+func TestSplitAIGenerateChunks(t *testing.T) {
+	chunks := splitAIGenerateChunks([]model.AIGenerateJobTarget{
+		{Tag: "数据存储", SecondTag: "Redis", Count: 25},
+		{Tag: "数据存储", SecondTag: "MySQL", Count: 10},
+		{Tag: "系统设计", SecondTag: "分布式锁", Count: 3},
+	}, 10)
+
+	var counts []int
+	for _, chunk := range chunks {
+		counts = append(counts, chunk.Count)
+	}
+	assert.Equal(t, []int{10, 10, 5, 10, 3}, counts)
+	assert.Equal(t, "Redis", chunks[2].SecondTag)
+	assert.Equal(t, "分布式锁", chunks[4].SecondTag)
+}
+
+func TestRetryAIGenerate(t *testing.T) {
+	old := aiGenerateRetryBackoff
+	aiGenerateRetryBackoff = time.Millisecond
+	t.Cleanup(func() { aiGenerateRetryBackoff = old })
+
+	unavailable := &third_part.StatusError{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}
+	calls := 0
+	err := retryAIGenerate(context.Background(), 3, func() error {
+		calls++
+		if calls < 3 {
+			return unavailable
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = retryAIGenerate(context.Background(), 2, func() error {
+		calls++
+		return fmt.Errorf("调用AI接口失败:%w", unavailable)
+	})
+	assert.ErrorIs(t, err, unavailable)
+	assert.Equal(t, 3, calls)
+
+	// 回复无法解析等非临时性错误不重试
+	calls = 0
+	err = retryAIGenerate(context.Background(), 3, func() error {
+		calls++
+		return errors.New("解析AI生成题目失败：回复中没有JSON内容")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = 0
+	err = retryAIGenerate(ctx, 3, func() error {
+		calls++
+		cancel()
+		return context.Canceled
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+}
+
+// 测试大模型调用错误的分类：网络错误、超时、429及5xx可以重试
+func TestIsTransientLLMError(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{"超时", fmt.Errorf("调用AI接口失败:%w", context.DeadlineExceeded), true},
+		{"网络错误", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, true},
+		{"HTTP 503", &third_part.StatusError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"HTTP 429", &third_part.StatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{"HTTP 401", &third_part.StatusError{StatusCode: http.StatusUnauthorized}, false},
+		{"火山方舟 500", &arkmodel.APIError{HTTPStatusCode: http.StatusInternalServerError}, true},
+		{"火山方舟 400", &arkmodel.RequestError{HTTPStatusCode: http.StatusBadRequest, Err: errors.New("bad request")}, false},
+		{"解析失败", errors.New("解析AI生成题目失败"), false},
+		{"超出预算", errLLMBudgetExceeded, false},
+		{"无错误", nil, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.transient, third_part.IsTransientError(tc.err))
+		})
+	}
+
+	// OpenAI兼容接口返回的错误状态码保留在错误中
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte(`{"error": {"message": "upstream error"}}`))
+	}))
+	defer server.Close()
+	_, err := third_part.NewOpenAICompatibleService(server.URL, "key", "qwen-plus").Chat(context.Background(), "提示词")
+	var statusErr *third_part.StatusError
+	assert.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusBadGateway, statusErr.StatusCode)
+	assert.ErrorContains(t, err, "upstream error")
+	assert.True(t, third_part.IsTransientError(err))
+}
+
+func TestValidateSubmitAIGenerateJobRequest(t *testing.T) {
+	setTestKnowledgeTree(t, testKnowledgeTree)
+	newRequest := func() *SubmitAIGenerateJobRequest {
+		return &SubmitAIGenerateJobRequest{
+			QuestionType: consts.QuestionTypeShortAnswer,
+			Targets: []model.AIGenerateJobTarget{
+				{Tag: "数据存储", SecondTag: "Redis", Count: 60},
+				{Tag: "系统设计", SecondTag: "分布式锁", Count: 40},
+			},
+		}
+	}
+
+	req := newRequest()
+	assert.NoError(t, ValidateSubmitAIGenerateJobRequest(req))
+	assert.Equal(t, consts.DuplicatePolicySkip, req.DuplicatePolicy)
+
+	req = newRequest()
+	req.Targets[1].Count = consts.AIGenerateJobMaxCount
+	assert.ErrorContains(t, ValidateSubmitAIGenerateJobRequest(req), "题目总数")
+
+	req = newRequest()
+	req.Targets[1] = req.Targets[0]
+	assert.ErrorContains(t, ValidateSubmitAIGenerateJobRequest(req), "分类重复")
+
+	req = newRequest()
+	req.Targets[0].SecondTag = "分布式锁"
+	assert.ErrorContains(t, ValidateSubmitAIGenerateJobRequest(req), "无效的标签关系")
+
+	req = newRequest()
+	req.Targets = nil
+	assert.Error(t, ValidateSubmitAIGenerateJobRequest(req))
+
+	req = newRequest()
+	req.DuplicatePolicy = "replace"
+	assert.Error(t, ValidateSubmitAIGenerateJobRequest(req))
+}
+
+func TestBuildAIGeneratePrompt_ExcludeTitles(t *testing.T) {
+	prompt := newTestPromptContext(t, defaultAIGeneratePromptTemplate)
+	req := &GenerateAIQuestionRequest{QuestionType: consts.QuestionTypeShortAnswer, Tag: "数据存储", SecondTag: "Redis", Count: 5}
+	content, err := buildAIGeneratePrompt(prompt, req)
+	assert.NoError(t, err)
+	assert.NotContains(t, content, "已经生成过")
+
+	for i := 0; i < aiGenerateExcludeTitlesMax+5; i++ {
+		req.ExcludeTitles = append(req.ExcludeTitles, "题目"+string(rune('A'+i)))
+	}
+	content, err = buildAIGeneratePrompt(prompt, req)
+	assert.NoError(t, err)
+	assert.Contains(t, content, "已经生成过")
+	assert.NotContains(t, content, "题目A\n")
+	assert.Contains(t, content, req.ExcludeTitles[len(req.ExcludeTitles)-1])
+}
+
+func TestNewAIGenerateJobDetail(t *testing.T) {
+	detail := newAIGenerateJobDetail(&model.AIGenerateJob{Status: consts.AIGenerateJobStatusRunning, TotalChunks: 4, FinishedChunks: 1})
+	assert.Equal(t, 25, detail.Progress)
+	assert.Equal(t, "生成中", detail.StatusName)
+
+	detail = newAIGenerateJobDetail(&model.AIGenerateJob{Status: consts.AIGenerateJobStatusSucceeded})
+	assert.Equal(t, 100, detail.Progress)
+}
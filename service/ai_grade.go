@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vaynedu/exam_system/config"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/model"
+	"github.com/vaynedu/exam_system/third_part"
+)
+
+// aiGradePromptTemplate 问答题AI评分提示词：题干、参考答案、答案解析、用户答案
+const aiGradePromptTemplate = `你是一名严格但公正的技术面试官，请根据参考答案对考生的问答题作答评分。
+要求：
+1. 先从参考答案和答案解析中提炼出3-6个得分要点，再逐条判断考生是否答到（意思相近即可，不要求原文一致）；
+2. 按要点覆盖程度和表述准确性给出0-100的整数分数，答非所问或空洞回答不超过20分；
+3. 只输出一个JSON对象，不要输出其他内容，格式为：
+{"score": 85, "matched_points": ["答到的要点"], "missed_points": ["遗漏的要点"], "feedback": "简短评语与改进建议"}
+
+【题干】
+%s
+
+【参考答案】
+%s
+
+【答案解析】
+%s
+
+【考生答案】
+%s`
+
+// AIGradeRequest 问答题AI评分请求
+type AIGradeRequest struct {
+	QuestionID uint   `json:"question_id" binding:"required"`
+	Provider   string `json:"provider"` // 大模型服务，为空使用默认
+	Model      string `json:"model"`    // 模型名称，为空使用该服务的默认模型
+}
+
+// AIGradeExamAnswerService 使用AI为考试中的问答题作答评分，评分结果保存到作答记录（仅供参考，不改变判分结果）
+func AIGradeExamAnswerService(ctx context.Context, userID, sessionID uint, req *AIGradeRequest) (*model.ExamSessionAnswer, error) {
+	if err := validateLLMProvider(req.Provider); err != nil {
+		return nil, err
+	}
+	questionID := req.QuestionID
+	sessionDao := dao.NewExamSessionDao(config.DB)
+	session, err := getExamSession(sessionDao, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != consts.ExamSessionStatusFinished {
+		return nil, errors.New("请先交卷再进行AI评分")
+	}
+
+	answers, err := sessionDao.GetAnswersBySessionID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	var target *model.ExamSessionAnswer
+	for _, answer := range answers {
+		if answer.QuestionID == questionID {
+			target = answer
+			break
+		}
+	}
+	if target == nil || target.Question == nil {
+		return nil, fmt.Errorf("题目%d不属于该考试", questionID)
+	}
+	if int(target.Question.QuestionType) != consts.QuestionTypeShortAnswer {
+		return nil, errors.New("仅支持对问答题进行AI评分")
+	}
+	if strings.TrimSpace(target.UserAnswer) == "" {
+		return nil, errors.New("该题未作答，无法评分")
+	}
+
+	llm, _, err := resolveLLMProvider(req.Provider, req.Model)
+	if err != nil {
+		return nil, err
+	}
+	// 调用大模型耗时较长，不放在事务中
+	ctx, trace := withLLMCallTrace(ctx, consts.LLMCallSceneGrade, req.Provider, userID)
+	grade, err := gradeShortAnswerWithAI(ctx, llm, target.Question, target.UserAnswer)
+	if err != nil {
+		return nil, err
+	}
+	trace.linkQuestions([]uint{questionID})
+
+	now := time.Now()
+	target.AIGrade = grade
+	target.AIGradedAt = &now
+	if err := sessionDao.UpdateAnswerAIGrade(target); err != nil {
+		return nil, fmt.Errorf("保存AI评分失败：%w", err)
+	}
+	return target, nil
+}
+
+// gradeShortAnswerWithAI 调用大模型为问答题作答评分
+func gradeShortAnswerWithAI(ctx context.Context, llm third_part.LLMProvider, question *model.ExamQuestion, userAnswer string) (*model.AIGradeResult, error) {
+	prompt := fmt.Sprintf(aiGradePromptTemplate, question.QuestionTitle, question.CorrectAnswer,
+		question.AnswerAnalysis, strings.TrimSpace(userAnswer))
+	content, err := chatJSON(ctx, llm, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("调用AI接口失败：%w", err)
+	}
+
+	grade, err := parseAIGradeResult(content)
+	if err != nil {
+		return nil, fmt.Errorf("解析AI评分结果失败：%w", err)
+	}
+	grade.Model = llm.ModelName()
+	return grade, nil
+}
+
+// parseAIGradeResult 从模型回复中解析评分结果（兼容```json代码块及前后多余文字），分数限制在0-100
+func parseAIGradeResult(content string) (*model.AIGradeResult, error) {
+	payload, err := extractJSONPayload(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		Score         *float64 `json:"score"`
+		MatchedPoints []string `json:"matched_points"`
+		MissedPoints  []string `json:"missed_points"`
+		Feedback      string   `json:"feedback"`
+	}
+	if err := json.Unmarshal([]byte(payload), &raw); err != nil {
+		return nil, err
+	}
+	if raw.Score == nil {
+		return nil, errors.New("回复中缺少score字段")
+	}
+
+	score := int(*raw.Score + 0.5)
+	if score < 0 {
+		score = 0
+	}
+	if score > 100 {
+		score = 100
+	}
+	return &model.AIGradeResult{
+		Score:         score,
+		MatchedPoints: trimNonEmpty(raw.MatchedPoints),
+		MissedPoints:  trimNonEmpty(raw.MissedPoints),
+		Feedback:      strings.TrimSpace(raw.Feedback),
+	}, nil
+}
+
+// trimNonEmpty 去除首尾空白并过滤空字符串
+func trimNonEmpty(items []string) []string {
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+	"github.com/vaynedu/exam_system/third_part"
+)
+
+// newFakeLLMServer 启动本地假的大模型服务（兼容chat/completions接口），固定返回reply，并记录收到的提示词
+func newFakeLLMServer(t *testing.T, reply string, prompts *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.HasSuffix(r.URL.Path, "/chat/completions"))
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if prompts != nil && len(req.Messages) > 0 {
+			*prompts = append(*prompts, req.Messages[0].Content)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":     "fake",
+			"object": "chat.completion",
+			"choices": []map[string]interface{}{
+				{"index": 0, "finish_reason": "stop", "message": map[string]interface{}{"role": "assistant", "content": reply}},
+			},
+		})
+	}))
+}
+
+// 测试问答题AI评分：提示词包含题目与作答，回复解析为分数、要点与评语
+func TestGradeShortAnswerWithAI(t *testing.T) {
+	var prompts []string
+	server := newFakeLLMServer(t, "评分如下：\n```json\n{\"score\": 72.6, \"matched_points\": [\"互斥\", \" \"], \"missed_points\": [\"超时释放\"], \"feedback\": \" 要点基本覆盖 \"}\n```", &prompts)
+	defer server.Close()
+
+	client := &third_part.DouBaoAiService{ApiKey: "test", Model: "fake-model", BaseURL: server.URL}
+	question := &model.ExamQuestion{
+		QuestionType:  consts.QuestionTypeShortAnswer,
+		QuestionTitle: "如何用Redis实现分布式锁？",
+		CorrectAnswer: "SET NX PX保证互斥与超时释放，Lua脚本校验后删除",
+	}
+
+	grade, err := gradeShortAnswerWithAI(context.Background(), client, question, " 用SETNX加锁 ")
+	assert.NoError(t, err)
+	assert.Equal(t, &model.AIGradeResult{
+		Score:         73,
+		MatchedPoints: []string{"互斥"},
+		MissedPoints:  []string{"超时释放"},
+		Feedback:      "要点基本覆盖",
+		Model:         "fake-model",
+	}, grade)
+	if assert.Len(t, prompts, 1) {
+		assert.Contains(t, prompts[0], question.QuestionTitle)
+		assert.Contains(t, prompts[0], question.CorrectAnswer)
+		assert.Contains(t, prompts[0], "用SETNX加锁")
+	}
+}
+
+// 测试AI评分回复解析：分数越界截断，缺少分数或不是JSON时报错
+func TestParseAIGradeResult(t *testing.T) {
+	grade, err := parseAIGradeResult(`{"score": 120, "feedback": "很好"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, grade.Score)
+	assert.Empty(t, grade.MissedPoints)
+
+	grade, err = parseAIGradeResult(`{"score": -5}`)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, grade.Score)
+
+	_, err = parseAIGradeResult(`{"feedback": "缺少分数"}`)
+	assert.Error(t, err)
+	_, err = parseAIGradeResult("无法评分")
+	assert.Error(t, err)
+}
+
+// 测试OpenAI兼容接口的AI评分（本地假服务）
+func TestGradeShortAnswerWithAI_OpenAICompatible(t *testing.T) {
+	server := newFakeLLMServer(t, `{"score": 40, "matched_points": [], "missed_points": ["Lua脚本释放锁"], "feedback": "不完整"}`, nil)
+	defer server.Close()
+
+	llm := third_part.NewOpenAICompatibleService(server.URL+"/v1/", "key", "qwen-plus")
+	grade, err := gradeShortAnswerWithAI(context.Background(), llm, &model.ExamQuestion{QuestionTitle: "题干"}, "答案")
+	assert.NoError(t, err)
+	assert.Equal(t, 40, grade.Score)
+	assert.Equal(t, "qwen-plus", grade.Model)
+}
+
+// 测试进程内假模型：回复无法解析时返回错误
+func TestGradeShortAnswerWithAI_Fake(t *testing.T) {
+	llm := third_part.NewFakeLLMService("抱歉，我无法评分")
+	_, err := gradeShortAnswerWithAI(context.Background(), llm, &model.ExamQuestion{QuestionTitle: "题干"}, "答案")
+	assert.Error(t, err)
+	assert.Len(t, llm.Prompts(), 1)
+}
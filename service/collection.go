@@ -10,7 +10,7 @@ import (
 )
 
 // CreateCollectionService 创建收藏
-func CreateCollectionService(questionID uint, tag, secondTag string) error {
+func CreateCollectionService(userID, questionID uint, tag, secondTag string) error {
 	// 参数校验
 	if questionID == 0 {
 		return errors.New("题目ID不能为空")
@@ -28,7 +28,7 @@ func CreateCollectionService(questionID uint, tag, secondTag string) error {
 
 	// 检查是否已收藏
 	collectionDao := dao.NewCollectionDao(config.DB)
-	_, err = collectionDao.GetCollectionByQuestionID(questionID)
+	_, err = collectionDao.GetCollectionByQuestionID(userID, questionID)
 	if err == nil {
 		return errors.New("题目已收藏")
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -37,6 +37,7 @@ func CreateCollectionService(questionID uint, tag, secondTag string) error {
 
 	// 创建收藏
 	collection := &model.ExamQuestionCollection{
+		UserID:     userID,
 		QuestionID: questionID,
 		Tag:        tag,
 		SecondTag:  secondTag,
@@ -46,23 +47,23 @@ func CreateCollectionService(questionID uint, tag, secondTag string) error {
 }
 
 // DeleteCollectionService 删除收藏
-func DeleteCollectionService(questionID uint) error {
+func DeleteCollectionService(userID, questionID uint) error {
 	if questionID == 0 {
 		return errors.New("题目ID不能为空")
 	}
 
 	collectionDao := dao.NewCollectionDao(config.DB)
-	return collectionDao.DeleteCollection(questionID)
+	return collectionDao.DeleteCollection(userID, questionID)
 }
 
 // GetCollectionStatusService 获取收藏状态
-func GetCollectionStatusService(questionID uint) (bool, error) {
+func GetCollectionStatusService(userID, questionID uint) (bool, error) {
 	if questionID == 0 {
 		return false, errors.New("题目ID不能为空")
 	}
 
 	collectionDao := dao.NewCollectionDao(config.DB)
-	_, err := collectionDao.GetCollectionByQuestionID(questionID)
+	_, err := collectionDao.GetCollectionByQuestionID(userID, questionID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return false, nil
@@ -73,17 +74,17 @@ func GetCollectionStatusService(questionID uint) (bool, error) {
 }
 
 // BatchGetCollectionStatusService 批量获取收藏状态
-func BatchGetCollectionStatusService(questionIDs []uint) (map[uint]bool, error) {
+func BatchGetCollectionStatusService(userID uint, questionIDs []uint) (map[uint]bool, error) {
 	if len(questionIDs) == 0 {
 		return map[uint]bool{}, nil
 	}
 
 	collectionDao := dao.NewCollectionDao(config.DB)
-	return collectionDao.BatchGetCollectionStatus(questionIDs)
+	return collectionDao.BatchGetCollectionStatus(userID, questionIDs)
 }
 
 // GetCollectionListService 获取收藏列表
-func GetCollectionListService(tag, secondTag string, page, size int) ([]*model.ExamQuestionCollection, int64, error) {
+func GetCollectionListService(userID uint, tag, secondTag string, page, size int) ([]*model.ExamQuestionCollection, int64, error) {
 	collectionDao := dao.NewCollectionDao(config.DB)
-	return collectionDao.GetCollectionList(tag, secondTag, page, size)
+	return collectionDao.GetCollectionList(userID, tag, secondTag, page, size)
 }
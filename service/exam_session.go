@@ -0,0 +1,354 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vaynedu/exam_system/config"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/model"
+	"gorm.io/gorm"
+)
+
+// CreateExamSessionRequest 创建考试会话请求参数
+type CreateExamSessionRequest struct {
+	Tag           string   `json:"tag"`
+	SecondTag     string   `json:"second_tag"`
+	QuestionType  *int     `json:"question_type"` // 为空表示不限题型
+	Count         int      `json:"count"`
+	FromWrongBook bool     `json:"from_wrong_book"` // 是否只从错题本（未掌握）中抽题
+	Tags          []string `json:"tags"`            // 多分类筛选，格式为“一级分类/下级分类”
+	MatchAll      bool     `json:"match_all"`       // 是否需同时命中全部分类（默认命中任一即可）
+
+	tagRefs []dao.TagRef // 解析后的多分类筛选条件
+}
+
+// ExamAnswerItem 单题作答参数
+type ExamAnswerItem struct {
+	QuestionID uint   `json:"question_id"`
+	Answer     string `json:"answer"`
+}
+
+// ExamSessionQuestion 考试中下发给用户的题目（不含正确答案与解析）
+type ExamSessionQuestion struct {
+	Seq           int      `json:"seq"`
+	QuestionID    uint     `json:"question_id"`
+	QuestionType  int8     `json:"question_type"`
+	QuestionTitle string   `json:"question_title"`
+	Options       []string `json:"options"`
+	OptionA       string   `json:"option_a"`
+	OptionB       string   `json:"option_b"`
+	OptionC       string   `json:"option_c"`
+	OptionD       string   `json:"option_d"`
+	Tag           string   `json:"tag"`
+	SecondTag     string   `json:"second_tag"`
+	UserAnswer    string   `json:"user_answer"`
+	Answered      bool     `json:"answered"`
+}
+
+// ExamSessionResult 交卷后的考试结果（含正确答案与每题判分）
+type ExamSessionResult struct {
+	Session *model.ExamSession         `json:"session"`
+	Answers []*model.ExamSessionAnswer `json:"answers"`
+}
+
+// ValidateCreateExamSessionRequest 校验创建考试会话参数，并补齐默认题目数量
+func ValidateCreateExamSessionRequest(req *CreateExamSessionRequest) error {
+	if err := validateTagRelation(req.Tag, req.SecondTag); err != nil {
+		return err
+	}
+	tagRefs, err := ParseTagFilters(req.Tags)
+	if err != nil {
+		return err
+	}
+	if req.FromWrongBook && len(tagRefs) > 0 {
+		return errors.New("错题练习暂不支持多分类筛选")
+	}
+	req.tagRefs = tagRefs
+	if req.QuestionType != nil && !consts.CheckQuestionType(*req.QuestionType) {
+		return fmt.Errorf("无效的题型：%d", *req.QuestionType)
+	}
+	if req.Count == 0 {
+		req.Count = consts.ExamSessionDefaultCount
+	}
+	if req.Count < 0 || req.Count > consts.ExamSessionMaxCount {
+		return fmt.Errorf("题目数量需在1-%d之间：%d", consts.ExamSessionMaxCount, req.Count)
+	}
+	return nil
+}
+
+// CreateExamSessionService 创建考试会话：按条件随机抽题并落库
+func CreateExamSessionService(userID uint, req *CreateExamSessionRequest) (*model.ExamSession, error) {
+	if err := ValidateCreateExamSessionRequest(req); err != nil {
+		return nil, err
+	}
+
+	questionType := -1
+	if req.QuestionType != nil {
+		questionType = *req.QuestionType
+	}
+	var questions []model.ExamQuestion
+	var err error
+	if req.FromWrongBook {
+		questions, err = dao.NewWrongQuestionDao(config.DB).GetRandomWrongQuestions(userID, req.Tag, req.SecondTag, questionType, req.Count)
+	} else {
+		questions, err = dao.NewQuestionDao(config.DB).GetRandomQuestionsByCondition(BuildQuestionTagFilter(req.Tag, req.SecondTag, req.tagRefs, req.MatchAll), questionType, req.Count)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("抽取题目失败：%w", err)
+	}
+	if len(questions) == 0 {
+		return nil, errors.New("没有符合条件的题目")
+	}
+
+	session := &model.ExamSession{
+		UserID:        userID,
+		Tag:           req.Tag,
+		SecondTag:     req.SecondTag,
+		QuestionType:  int8(questionType),
+		QuestionCount: len(questions),
+		Status:        consts.ExamSessionStatusInProgress,
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		sessionDao := dao.NewExamSessionDao(tx)
+		if err := sessionDao.CreateSession(session); err != nil {
+			return err
+		}
+		answers := make([]*model.ExamSessionAnswer, 0, len(questions))
+		for i, q := range questions {
+			answers = append(answers, &model.ExamSessionAnswer{
+				SessionID:  session.ID,
+				QuestionID: q.ID,
+				Seq:        i + 1,
+				Result:     consts.AnswerResultUnanswered,
+			})
+		}
+		return sessionDao.CreateAnswers(answers)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建考试会话失败：%w", err)
+	}
+	return session, nil
+}
+
+// GetExamSessionQuestionsService 获取考试会话的题目（不含答案）
+func GetExamSessionQuestionsService(userID, sessionID uint) (*model.ExamSession, []*ExamSessionQuestion, error) {
+	sessionDao := dao.NewExamSessionDao(config.DB)
+	session, err := getExamSession(sessionDao, userID, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	answers, err := sessionDao.GetAnswersBySessionID(sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	questions := make([]*ExamSessionQuestion, 0, len(answers))
+	for _, answer := range answers {
+		if answer.Question == nil {
+			continue // 题目已被删除
+		}
+		questions = append(questions, &ExamSessionQuestion{
+			Seq:           answer.Seq,
+			QuestionID:    answer.QuestionID,
+			QuestionType:  answer.Question.QuestionType,
+			QuestionTitle: answer.Question.QuestionTitle,
+			Options:       answer.Question.Options,
+			OptionA:       answer.Question.OptionA,
+			OptionB:       answer.Question.OptionB,
+			OptionC:       answer.Question.OptionC,
+			OptionD:       answer.Question.OptionD,
+			Tag:           answer.Question.Tag,
+			SecondTag:     answer.Question.SecondTag,
+			UserAnswer:    answer.UserAnswer,
+			Answered:      answer.AnsweredAt != nil,
+		})
+	}
+	return session, questions, nil
+}
+
+// SubmitExamAnswerService 提交单题答案
+func SubmitExamAnswerService(userID, sessionID uint, item ExamAnswerItem) error {
+	return BatchSubmitExamAnswersService(userID, sessionID, []ExamAnswerItem{item})
+}
+
+// BatchSubmitExamAnswersService 批量提交答案（可重复提交，以最后一次为准）
+func BatchSubmitExamAnswersService(userID, sessionID uint, items []ExamAnswerItem) error {
+	if len(items) == 0 {
+		return errors.New("答案列表不能为空")
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		sessionDao := dao.NewExamSessionDao(tx)
+		session, err := getExamSession(sessionDao, userID, sessionID)
+		if err != nil {
+			return err
+		}
+		if session.Status != consts.ExamSessionStatusInProgress {
+			return errors.New("考试已交卷，不能再提交答案")
+		}
+
+		answers, err := sessionDao.GetAnswersBySessionID(sessionID)
+		if err != nil {
+			return err
+		}
+		answerMap := make(map[uint]*model.ExamSessionAnswer, len(answers))
+		for _, answer := range answers {
+			answerMap[answer.QuestionID] = answer
+		}
+
+		now := time.Now()
+		for _, item := range items {
+			answer, ok := answerMap[item.QuestionID]
+			if !ok || answer.Question == nil {
+				return fmt.Errorf("题目%d不属于该考试", item.QuestionID)
+			}
+			answer.UserAnswer = item.Answer
+			answer.Result = GradeAnswer(answer.Question, item.Answer)
+			answer.BlankResults = nil
+			if int(answer.Question.QuestionType) == consts.QuestionTypeFillInTheBlank {
+				answer.BlankResults = GradeFillInTheBlank(answer.Question, item.Answer)
+			}
+			answer.AnsweredAt = &now
+			if err := sessionDao.UpdateAnswer(answer); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FinishExamSessionService 交卷：汇总判分结果并返回考试结果
+func FinishExamSessionService(userID, sessionID uint) (*ExamSessionResult, error) {
+	var result *ExamSessionResult
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		sessionDao := dao.NewExamSessionDao(tx)
+		session, err := getExamSession(sessionDao, userID, sessionID)
+		if err != nil {
+			return err
+		}
+		if session.Status == consts.ExamSessionStatusFinished {
+			return errors.New("考试已交卷")
+		}
+
+		answers, err := sessionDao.GetAnswersBySessionID(sessionID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		session.CorrectCount, session.WrongCount, session.PendingCount, session.Score = summarizeAnswers(answers)
+		session.Status = consts.ExamSessionStatusFinished
+		session.FinishedAt = &now
+		if err := sessionDao.UpdateSession(session); err != nil {
+			return err
+		}
+		if err := recordWrongAnswers(tx, userID, answers); err != nil {
+			return fmt.Errorf("记录错题失败：%w", err)
+		}
+		if err := recordReviewFromAnswers(tx, userID, answers); err != nil {
+			return fmt.Errorf("更新复习计划失败：%w", err)
+		}
+
+		result = &ExamSessionResult{Session: session, Answers: answers}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetExamSessionResultService 获取已交卷考试的结果
+func GetExamSessionResultService(userID, sessionID uint) (*ExamSessionResult, error) {
+	sessionDao := dao.NewExamSessionDao(config.DB)
+	session, err := getExamSession(sessionDao, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != consts.ExamSessionStatusFinished {
+		return nil, errors.New("考试尚未交卷")
+	}
+
+	answers, err := sessionDao.GetAnswersBySessionID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &ExamSessionResult{Session: session, Answers: answers}, nil
+}
+
+// SelfReviewExamAnswerService 问答题自评：将待评阅题目标记为正确或错误，并重新计算成绩
+func SelfReviewExamAnswerService(userID, sessionID, questionID uint, isCorrect bool) (*model.ExamSession, error) {
+	var session *model.ExamSession
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		sessionDao := dao.NewExamSessionDao(tx)
+		var err error
+		session, err = getExamSession(sessionDao, userID, sessionID)
+		if err != nil {
+			return err
+		}
+		if session.Status != consts.ExamSessionStatusFinished {
+			return errors.New("请先交卷再评阅")
+		}
+
+		answers, err := sessionDao.GetAnswersBySessionID(sessionID)
+		if err != nil {
+			return err
+		}
+		var target *model.ExamSessionAnswer
+		for _, answer := range answers {
+			if answer.QuestionID == questionID {
+				target = answer
+				break
+			}
+		}
+		if target == nil {
+			return fmt.Errorf("题目%d不属于该考试", questionID)
+		}
+		if target.Result != consts.AnswerResultPendingReview {
+			return errors.New("该题不是待评阅状态")
+		}
+
+		target.Result = consts.AnswerResultWrong
+		if isCorrect {
+			target.Result = consts.AnswerResultCorrect
+		}
+		if err := sessionDao.UpdateAnswer(target); err != nil {
+			return err
+		}
+		if err := recordWrongAnswers(tx, userID, []*model.ExamSessionAnswer{target}); err != nil {
+			return fmt.Errorf("记录错题失败：%w", err)
+		}
+		if err := recordReviewFromAnswers(tx, userID, []*model.ExamSessionAnswer{target}); err != nil {
+			return fmt.Errorf("更新复习计划失败：%w", err)
+		}
+
+		session.CorrectCount, session.WrongCount, session.PendingCount, session.Score = summarizeAnswers(answers)
+		return sessionDao.UpdateSession(session)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// GetExamSessionListService 获取用户的练习记录
+func GetExamSessionListService(userID uint, page, size int) ([]*model.ExamSession, int64, error) {
+	return dao.NewExamSessionDao(config.DB).GetSessionList(userID, page, size)
+}
+
+// getExamSession 获取用户的考试会话，不存在或不属于该用户时返回业务错误
+func getExamSession(sessionDao *dao.ExamSessionDao, userID, sessionID uint) (*model.ExamSession, error) {
+	session, err := sessionDao.GetSessionByID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("考试会话不存在")
+		}
+		return nil, err
+	}
+	if session.UserID != userID {
+		return nil, errors.New("考试会话不存在")
+	}
+	return session, nil
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+)
+
+// 填空题正确答案的文本写法（Excel导入、correct_answer字段）：
+//   - 多个空之间用换行或分号（；/;）分隔，如“epoll；select”
+//   - 同一个空的多个可接受答案用竖线（|/｜）分隔，如“进程|process”
+//   - 以“re:”开头的部分为正则表达式（忽略大小写），需写在该空的最后，如“epoll|re:^epoll_(wait|ctl)$”，
+//     正则中不能包含换行和分号，需要时请通过blanks字段直接提交
+const (
+	fillBlankSeparators  = "\n；;"
+	fillAnswerSeparators = "|｜"
+	fillRegexPrefix      = "re:"
+	fillBlankJoinSep     = "；"
+	fillAnswerJoinSep    = "|"
+)
+
+// parseFillBlanks 解析填空题正确答案文本为各空的判分规则
+func parseFillBlanks(text string) model.FillBlanks {
+	var blanks model.FillBlanks
+	for _, part := range strings.FieldsFunc(text, func(r rune) bool {
+		return strings.ContainsRune(fillBlankSeparators, r)
+	}) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var blank model.FillBlank
+		tokens := strings.FieldsFunc(part, func(r rune) bool {
+			return strings.ContainsRune(fillAnswerSeparators, r)
+		})
+		for j, token := range tokens {
+			token = strings.TrimSpace(token)
+			if strings.HasPrefix(strings.ToLower(token), fillRegexPrefix) {
+				// 正则表达式本身可能包含竖线，取该空剩余的全部内容
+				tokens[j] = token[len(fillRegexPrefix):]
+				blank.Regex = strings.TrimSpace(strings.Join(tokens[j:], fillAnswerJoinSep))
+				break
+			}
+			if token != "" {
+				blank.Answers = append(blank.Answers, token)
+			}
+		}
+		blanks = append(blanks, blank)
+	}
+	return blanks
+}
+
+// legacyFillBlanks 未写入blanks的旧题目的判分规则：正确答案文本整体作为唯一的空的唯一答案，
+// 不按分号、竖线拆分（旧题目的答案可能本身包含这些字符，如“a || b”），与旧版按整体归一化比较的判分一致
+func legacyFillBlanks(text string) model.FillBlanks {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	return model.FillBlanks{{Answers: []string{text}}}
+}
+
+// keepStoredFillBlanks 更新填空题时未传blanks且题型、正确答案文本均未变化，则沿用题目原有的判分规则
+// （旧题目为legacyFillBlanks），避免只修改题干等其他字段时按新的分隔符写法重新解析原有答案
+func keepStoredFillBlanks(question, existing *model.ExamQuestion) {
+	if question.QuestionType != consts.QuestionTypeFillInTheBlank || len(question.Blanks) > 0 ||
+		existing.QuestionType != consts.QuestionTypeFillInTheBlank ||
+		strings.TrimSpace(question.CorrectAnswer) != strings.TrimSpace(existing.CorrectAnswer) {
+		return
+	}
+	if len(existing.Blanks) > 0 {
+		question.Blanks = existing.Blanks
+	} else {
+		question.Blanks = legacyFillBlanks(existing.CorrectAnswer)
+	}
+}
+
+// formatFillBlanks 将各空的判分规则格式化为正确答案文本（parseFillBlanks的逆过程）
+func formatFillBlanks(blanks model.FillBlanks) string {
+	parts := make([]string, 0, len(blanks))
+	for _, blank := range blanks {
+		tokens := append([]string{}, blank.Answers...)
+		if blank.Regex != "" {
+			tokens = append(tokens, fillRegexPrefix+blank.Regex)
+		}
+		parts = append(parts, strings.Join(tokens, fillAnswerJoinSep))
+	}
+	return strings.Join(parts, fillBlankJoinSep)
+}
+
+// normalizeFillBlanks 校验并规范化填空题：已传blanks时以其为准并重新生成正确答案文本，否则由正确答案文本解析
+func normalizeFillBlanks(question *model.ExamQuestion) error {
+	blanks := question.Blanks
+	if len(blanks) == 0 {
+		blanks = parseFillBlanks(question.CorrectAnswer)
+	}
+	if len(blanks) == 0 {
+		return errors.New("填空题至少需要一个空的答案！")
+	}
+	if len(blanks) > consts.FillBlankMaxCount {
+		return fmt.Errorf("填空题最多%d个空！", consts.FillBlankMaxCount)
+	}
+
+	normalized := make(model.FillBlanks, 0, len(blanks))
+	for i, blank := range blanks {
+		var answers []string
+		seen := make(map[string]bool, len(blank.Answers))
+		for _, answer := range blank.Answers {
+			answer = strings.TrimSpace(answer)
+			key := normalizeFillAnswer(answer)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			answers = append(answers, answer)
+		}
+		regex := strings.TrimSpace(blank.Regex)
+		if len(answers) == 0 && regex == "" {
+			return fmt.Errorf("填空题第%d个空缺少答案！", i+1)
+		}
+		if regex != "" {
+			if utf8.RuneCountInString(regex) > consts.FillBlankRegexMaxLen {
+				return fmt.Errorf("填空题第%d个空的正则表达式不能超过%d个字符！", i+1, consts.FillBlankRegexMaxLen)
+			}
+			if _, err := compileFillRegex(regex); err != nil {
+				return fmt.Errorf("填空题第%d个空的正则表达式无效：%v", i+1, err)
+			}
+		}
+		normalized = append(normalized, model.FillBlank{Answers: answers, Regex: regex})
+	}
+
+	question.Blanks = normalized
+	question.CorrectAnswer = formatFillBlanks(normalized)
+	return nil
+}
+
+// GradeFillInTheBlank 填空题逐空判分，返回每个空的判分结果（全部空都正确才算答对）
+func GradeFillInTheBlank(question *model.ExamQuestion, userAnswer string) model.BlankResults {
+	blanks := question.Blanks
+	if len(blanks) == 0 {
+		blanks = legacyFillBlanks(question.CorrectAnswer)
+	}
+	userAnswers := splitFillUserAnswer(userAnswer, len(blanks))
+
+	results := make(model.BlankResults, 0, len(blanks))
+	for i, blank := range blanks {
+		var answer string
+		if i < len(userAnswers) {
+			answer = strings.TrimSpace(userAnswers[i])
+		}
+		results = append(results, model.BlankResult{
+			Index:      i + 1,
+			UserAnswer: answer,
+			Correct:    answer != "" && matchFillBlank(blank, answer),
+		})
+	}
+	return results
+}
+
+// allBlanksCorrect 判断填空题是否全部空都答对
+func allBlanksCorrect(results model.BlankResults) bool {
+	if len(results) == 0 {
+		return false
+	}
+	for _, result := range results {
+		if !result.Correct {
+			return false
+		}
+	}
+	return true
+}
+
+// splitFillUserAnswer 拆分用户填空答案：支持JSON字符串数组（如["epoll","select"]），
+// 或与正确答案相同的分隔符（换行、分号）；只有一个空时整体作为答案
+func splitFillUserAnswer(answer string, blankCount int) []string {
+	answer = strings.TrimSpace(answer)
+	if strings.HasPrefix(answer, "[") {
+		var answers []string
+		if err := json.Unmarshal([]byte(answer), &answers); err == nil {
+			return answers
+		}
+	}
+	if blankCount <= 1 {
+		return []string{answer}
+	}
+	return strings.FieldsFunc(answer, func(r rune) bool {
+		return strings.ContainsRune(fillBlankSeparators, r)
+	})
+}
+
+// matchFillBlank 判断用户答案是否命中该空的任一可接受答案或正则表达式
+func matchFillBlank(blank model.FillBlank, answer string) bool {
+	normalized := normalizeFillAnswer(answer)
+	for _, accepted := range blank.Answers {
+		if normalizeFillAnswer(accepted) == normalized {
+			return true
+		}
+	}
+	if blank.Regex != "" {
+		if re, err := compileFillRegex(blank.Regex); err == nil {
+			return re.MatchString(normalizeFillWidth(strings.TrimSpace(answer)))
+		}
+	}
+	return false
+}
+
+// compileFillRegex 编译填空题正则表达式（忽略大小写）
+func compileFillRegex(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// normalizeFillAnswer 填空题答案归一化：全角转半角、统一小写、合并连续空白并去除首尾空白
+func normalizeFillAnswer(answer string) string {
+	return strings.Join(strings.Fields(strings.ToLower(normalizeFillWidth(answer))), " ")
+}
+
+// normalizeFillWidth 全角字符转半角（全角空格转为普通空格）
+func normalizeFillWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '　':
+			return ' '
+		case r >= '！' && r <= '～':
+			return r - 0xFEE0
+		case unicode.IsSpace(r):
+			return ' '
+		}
+		return r
+	}, s)
+}
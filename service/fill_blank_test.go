@@ -0,0 +1,105 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+)
+
+// 测试填空题正确答案文本的解析与格式化
+func TestParseFillBlanks(t *testing.T) {
+	blanks := parseFillBlanks(" 进程 | process ；epoll|re:^epoll_(wait|ctl)$\nselect ")
+	assert.Equal(t, model.FillBlanks{
+		{Answers: []string{"进程", "process"}},
+		{Answers: []string{"epoll"}, Regex: "^epoll_(wait|ctl)$"},
+		{Answers: []string{"select"}},
+	}, blanks)
+	assert.Equal(t, "进程|process；epoll|re:^epoll_(wait|ctl)$；select", formatFillBlanks(blanks))
+	assert.Equal(t, blanks, parseFillBlanks(formatFillBlanks(blanks)))
+}
+
+// 测试填空题校验：保留答案大小写、去重，拒绝无效正则
+func TestNormalizeFillBlanks(t *testing.T) {
+	question := &model.ExamQuestion{CorrectAnswer: "var|VAR|Var；epoll_wait"}
+	assert.NoError(t, normalizeFillBlanks(question))
+	assert.Equal(t, "var；epoll_wait", question.CorrectAnswer)
+	assert.Len(t, question.Blanks, 2)
+
+	question = &model.ExamQuestion{CorrectAnswer: "忽略", Blanks: model.FillBlanks{{Regex: "^a+$"}, {Answers: []string{" b "}}}}
+	assert.NoError(t, normalizeFillBlanks(question))
+	assert.Equal(t, "re:^a+$；b", question.CorrectAnswer)
+
+	assert.Error(t, normalizeFillBlanks(&model.ExamQuestion{Blanks: model.FillBlanks{{Regex: "(abc"}}}))
+	assert.Error(t, normalizeFillBlanks(&model.ExamQuestion{Blanks: model.FillBlanks{{Answers: []string{" "}}}}))
+	assert.Error(t, normalizeFillBlanks(&model.ExamQuestion{CorrectAnswer: " ；; "}))
+}
+
+// 测试填空题逐空判分
+func TestGradeFillInTheBlank(t *testing.T) {
+	question := &model.ExamQuestion{
+		QuestionType: consts.QuestionTypeFillInTheBlank,
+		Blanks: model.FillBlanks{
+			{Answers: []string{"进程", "process"}},
+			{Answers: []string{"epoll"}, Regex: `^epoll_(wait|ctl)$`},
+		},
+	}
+
+	results := GradeFillInTheBlank(question, "ＰＲＯＣＥＳＳ；EPOLL_WAIT")
+	assert.Equal(t, model.BlankResults{
+		{Index: 1, UserAnswer: "ＰＲＯＣＥＳＳ", Correct: true},
+		{Index: 2, UserAnswer: "EPOLL_WAIT", Correct: true},
+	}, results)
+	assert.Equal(t, int8(consts.AnswerResultCorrect), GradeAnswer(question, "进程\nepoll"))
+
+	results = GradeFillInTheBlank(question, `["线程", "epoll"]`)
+	assert.False(t, results[0].Correct)
+	assert.True(t, results[1].Correct)
+	assert.Equal(t, int8(consts.AnswerResultWrong), GradeAnswer(question, `["线程", "epoll"]`))
+
+	results = GradeFillInTheBlank(question, "进程")
+	assert.True(t, results[0].Correct)
+	assert.False(t, results[1].Correct)
+
+	// 未迁移blanks的旧题目按正确答案文本判分
+	legacy := &model.ExamQuestion{QuestionType: consts.QuestionTypeFillInTheBlank, CorrectAnswer: "Hash  Table"}
+	assert.Equal(t, int8(consts.AnswerResultCorrect), GradeAnswer(legacy, "hash　table"))
+
+	// 旧题目的答案不按分号、竖线拆分
+	legacy = &model.ExamQuestion{QuestionType: consts.QuestionTypeFillInTheBlank, CorrectAnswer: "a || b"}
+	assert.Equal(t, int8(consts.AnswerResultCorrect), GradeAnswer(legacy, "a || b"))
+	assert.Equal(t, int8(consts.AnswerResultWrong), GradeAnswer(legacy, "a"))
+}
+
+// 测试更新填空题时沿用原有的判分规则
+func TestKeepStoredFillBlanks(t *testing.T) {
+	legacy := &model.ExamQuestion{QuestionType: consts.QuestionTypeFillInTheBlank, CorrectAnswer: "a || b"}
+
+	// 答案未变化：旧题目整体作为唯一答案
+	question := &model.ExamQuestion{QuestionType: consts.QuestionTypeFillInTheBlank, CorrectAnswer: " a || b "}
+	keepStoredFillBlanks(question, legacy)
+	assert.NoError(t, normalizeFillBlanks(question))
+	assert.Equal(t, model.FillBlanks{{Answers: []string{"a || b"}}}, question.Blanks)
+
+	// 答案未变化：沿用已有的blanks（如正则）
+	stored := &model.ExamQuestion{
+		QuestionType: consts.QuestionTypeFillInTheBlank, CorrectAnswer: "epoll|re:^epoll_(wait|ctl)$",
+		Blanks: model.FillBlanks{{Answers: []string{"epoll"}, Regex: "^epoll_(wait|ctl)$"}},
+	}
+	question = &model.ExamQuestion{QuestionType: consts.QuestionTypeFillInTheBlank, CorrectAnswer: stored.CorrectAnswer}
+	keepStoredFillBlanks(question, stored)
+	assert.Equal(t, stored.Blanks, question.Blanks)
+
+	// 答案已修改：按新的写法解析
+	question = &model.ExamQuestion{QuestionType: consts.QuestionTypeFillInTheBlank, CorrectAnswer: "a|b；c"}
+	keepStoredFillBlanks(question, legacy)
+	assert.Empty(t, question.Blanks)
+	assert.NoError(t, normalizeFillBlanks(question))
+	assert.Len(t, question.Blanks, 2)
+}
+
+// 测试全角转半角与空白归一化
+func TestNormalizeFillAnswer(t *testing.T) {
+	assert.Equal(t, "a, b (c)", normalizeFillAnswer("  Ａ，　 Ｂ （c）\t"))
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+)
+
+// GradeAnswer 根据题型自动判分，返回作答结果（consts.AnswerResultXxx）
+// 选择题与正确答案精确比较（忽略大小写），多选题需选项集合完全一致（少选、多选均判错），
+// 判断题统一为对/错后比较，排序题需顺序完全一致，连线题需全部配对一致，填空题需每个空都命中可接受答案，问答题标记为待评阅
+func GradeAnswer(question *model.ExamQuestion, userAnswer string) int8 {
+	userAnswer = strings.TrimSpace(userAnswer)
+	if userAnswer == "" {
+		return consts.AnswerResultUnanswered
+	}
+
+	switch int(question.QuestionType) {
+	case consts.QuestionTypeChoice:
+		if strings.ToUpper(userAnswer) == strings.ToUpper(strings.TrimSpace(question.CorrectAnswer)) {
+			return consts.AnswerResultCorrect
+		}
+		return consts.AnswerResultWrong
+	case consts.QuestionTypeFillInTheBlank:
+		if allBlanksCorrect(GradeFillInTheBlank(question, userAnswer)) {
+			return consts.AnswerResultCorrect
+		}
+		return consts.AnswerResultWrong
+	case consts.QuestionTypeMultipleChoice:
+		user, err := normalizeMultipleChoiceAnswer(userAnswer)
+		correct, _ := normalizeMultipleChoiceAnswer(question.CorrectAnswer)
+		if err == nil && user == correct {
+			return consts.AnswerResultCorrect
+		}
+		return consts.AnswerResultWrong
+	case consts.QuestionTypeTrueFalse:
+		user, ok := normalizeTrueFalseAnswer(userAnswer)
+		correct, _ := normalizeTrueFalseAnswer(question.CorrectAnswer)
+		if ok && user == correct {
+			return consts.AnswerResultCorrect
+		}
+		return consts.AnswerResultWrong
+	case consts.QuestionTypeOrdering:
+		user, err := parseOptionLetters(userAnswer)
+		correct, _ := parseOptionLetters(question.CorrectAnswer)
+		if err == nil && user == correct {
+			return consts.AnswerResultCorrect
+		}
+		return consts.AnswerResultWrong
+	case consts.QuestionTypeMatching:
+		user, err := normalizeMatchingAnswer(userAnswer)
+		correct, _ := normalizeMatchingAnswer(question.CorrectAnswer)
+		if err == nil && user == correct {
+			return consts.AnswerResultCorrect
+		}
+		return consts.AnswerResultWrong
+	case consts.QuestionTypeShortAnswer:
+		return consts.AnswerResultPendingReview
+	}
+	return consts.AnswerResultWrong
+}
+
+// summarizeAnswers 汇总作答结果，未作答计为答错，得分只统计可自动判分的题目
+func summarizeAnswers(answers []*model.ExamSessionAnswer) (correct, wrong, pending, score int) {
+	for _, answer := range answers {
+		switch int(answer.Result) {
+		case consts.AnswerResultCorrect:
+			correct++
+		case consts.AnswerResultPendingReview:
+			pending++
+		default:
+			wrong++
+		}
+	}
+	if correct+wrong > 0 {
+		score = correct * 100 / (correct + wrong)
+	}
+	return correct, wrong, pending, score
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+)
+
+// 测试GradeAnswer按题型判分
+func TestGradeAnswer(t *testing.T) {
+	choice := &model.ExamQuestion{QuestionType: consts.QuestionTypeChoice, CorrectAnswer: "C"}
+	fill := &model.ExamQuestion{QuestionType: consts.QuestionTypeFillInTheBlank, CorrectAnswer: "epoll_wait"}
+	short := &model.ExamQuestion{QuestionType: consts.QuestionTypeShortAnswer, CorrectAnswer: "参考答案"}
+	multiple := &model.ExamQuestion{QuestionType: consts.QuestionTypeMultipleChoice, CorrectAnswer: "ACD"}
+	trueFalse := &model.ExamQuestion{QuestionType: consts.QuestionTypeTrueFalse, CorrectAnswer: "对"}
+	ordering := &model.ExamQuestion{QuestionType: consts.QuestionTypeOrdering, CorrectAnswer: "CABD"}
+	matching := &model.ExamQuestion{QuestionType: consts.QuestionTypeMatching, CorrectAnswer: "A3,B1,C2"}
+
+	testCases := []struct {
+		name     string
+		question *model.ExamQuestion
+		answer   string
+		expected int8
+	}{
+		{"选择题正确", choice, "C", consts.AnswerResultCorrect},
+		{"选择题小写", choice, " c ", consts.AnswerResultCorrect},
+		{"选择题错误", choice, "A", consts.AnswerResultWrong},
+		{"填空题归一化", fill, "  EPOLL_WAIT ", consts.AnswerResultCorrect},
+		{"填空题错误", fill, "epoll", consts.AnswerResultWrong},
+		{"问答题待评阅", short, "我的回答", consts.AnswerResultPendingReview},
+		{"多选题乱序带分隔符", multiple, "d, a、c", consts.AnswerResultCorrect},
+		{"多选题少选", multiple, "AC", consts.AnswerResultWrong},
+		{"多选题多选", multiple, "ABCD", consts.AnswerResultWrong},
+		{"多选题非法字符", multiple, "A1CD", consts.AnswerResultWrong},
+		{"判断题同义写法", trueFalse, "√", consts.AnswerResultCorrect},
+		{"判断题英文", trueFalse, "true", consts.AnswerResultCorrect},
+		{"判断题错误", trueFalse, "错误", consts.AnswerResultWrong},
+		{"判断题无法识别", trueFalse, "不确定", consts.AnswerResultWrong},
+		{"排序题正确", ordering, "c>a>b>d", consts.AnswerResultCorrect},
+		{"排序题顺序错误", ordering, "CBAD", consts.AnswerResultWrong},
+		{"连线题乱序带连接符", matching, "c-2；a→3 b1", consts.AnswerResultCorrect},
+		{"连线题配对错误", matching, "A1,B3,C2", consts.AnswerResultWrong},
+		{"连线题少连", matching, "A3,B1", consts.AnswerResultWrong},
+		{"连线题格式错误", matching, "A,B,C", consts.AnswerResultWrong},
+		{"未作答", choice, "   ", consts.AnswerResultUnanswered},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, GradeAnswer(tc.question, tc.answer))
+		})
+	}
+}
+
+// 测试summarizeAnswers汇总成绩
+func TestSummarizeAnswers(t *testing.T) {
+	answers := []*model.ExamSessionAnswer{
+		{Result: consts.AnswerResultCorrect},
+		{Result: consts.AnswerResultCorrect},
+		{Result: consts.AnswerResultCorrect},
+		{Result: consts.AnswerResultWrong},
+		{Result: consts.AnswerResultUnanswered},
+		{Result: consts.AnswerResultPendingReview},
+	}
+
+	correct, wrong, pending, score := summarizeAnswers(answers)
+	assert.Equal(t, 3, correct)
+	assert.Equal(t, 2, wrong)
+	assert.Equal(t, 1, pending)
+	assert.Equal(t, 60, score)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/third_part"
+)
+
+// newLLMProvider 创建大模型服务提供方（测试时可替换）
+var newLLMProvider = third_part.NewLLMProvider
+
+// llmProviderUploadTypes 大模型服务提供方对应的题目录入方式
+var llmProviderUploadTypes = map[string]int8{
+	third_part.LLMProviderDouBao: consts.QuestionImportTypeAiDouBao,
+	third_part.LLMProviderAli:    consts.QuestionImportTypeAiAli,
+	third_part.LLMProviderYunWu:  consts.QuestionImportTypeAiYunWu,
+	third_part.LLMProviderOpenAI: consts.QuestionImportTypeAiOpenAI,
+}
+
+// validateLLMProvider 校验大模型服务提供方名称（为空表示使用默认提供方）
+func validateLLMProvider(provider string) error {
+	if provider == "" {
+		return nil
+	}
+	if _, ok := llmProviderUploadTypes[provider]; !ok {
+		return fmt.Errorf("不支持的大模型服务：%s，可选值：%v", provider, third_part.LLMProviders)
+	}
+	return nil
+}
+
+// chatJSON 请求模型输出JSON：支持JSON模式的服务使用JSON模式，否则按普通对话调用；
+// ctx携带调用上下文（withLLMCallTrace）时检查每日token预算并记录调用
+func chatJSON(ctx context.Context, llm third_part.LLMProvider, prompt string) (string, error) {
+	chat := func(ctx context.Context) (string, error) {
+		if jsonLLM, ok := llm.(third_part.JSONChatProvider); ok {
+			return jsonLLM.ChatJSON(ctx, prompt)
+		}
+		return llm.Chat(ctx, prompt)
+	}
+	if trace, ok := ctx.Value(llmCallTraceKey{}).(*llmCallTrace); ok {
+		return tracedChat(ctx, trace, llm, prompt, chat)
+	}
+	return chat(ctx)
+}
+
+// extractJSONPayload 从模型回复中截取JSON内容（兼容```json代码块及前后多余文字），返回以{或[开头的部分
+func extractJSONPayload(content string) (string, error) {
+	start := strings.IndexAny(content, "{[")
+	if start < 0 {
+		return "", errors.New("回复中没有JSON内容")
+	}
+	closing := "}"
+	if content[start] == '[' {
+		closing = "]"
+	}
+	end := strings.LastIndex(content, closing)
+	if end < start {
+		return "", errors.New("回复中的JSON内容不完整")
+	}
+	return content[start : end+1], nil
+}
+
+// resolveLLMProvider 按名称创建大模型服务提供方，并返回其生成题目对应的录入方式
+func resolveLLMProvider(provider, modelName string) (third_part.LLMProvider, int8, error) {
+	if provider == "" {
+		provider = third_part.DefaultLLMProvider()
+	}
+	uploadType, ok := llmProviderUploadTypes[provider]
+	if !ok {
+		return nil, 0, fmt.Errorf("不支持的大模型服务：%s", provider)
+	}
+	llm, err := newLLMProvider(provider, modelName)
+	if err != nil {
+		return nil, 0, err
+	}
+	return llm, uploadType, nil
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vaynedu/exam_system/config"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/model"
+	"github.com/vaynedu/exam_system/third_part"
+)
+
+// errLLMBudgetExceeded 今日token用量已达到预算，超出后拒绝继续调用大模型（不重试）
+var errLLMBudgetExceeded = errors.New("今日大模型token用量已超出预算")
+
+// llmCallTrace 一次业务操作中的大模型调用上下文：调用场景、服务与发起用户，以及最近一次成功调用的记录ID。
+// ctx携带trace时，chatJSON会检查每日预算并记录每次调用
+type llmCallTrace struct {
+	scene      string
+	provider   string
+	userID     uint
+	lastCallID uint
+}
+
+type llmCallTraceKey struct{}
+
+// withLLMCallTrace 返回携带调用上下文的ctx，provider为空时使用默认服务
+func withLLMCallTrace(ctx context.Context, scene, provider string, userID uint) (context.Context, *llmCallTrace) {
+	trace := &llmCallTrace{scene: scene, provider: llmProviderName(provider), userID: userID}
+	return context.WithValue(ctx, llmCallTraceKey{}, trace), trace
+}
+
+// linkQuestions 记录最近一次成功调用产生或处理的题目ID，失败只记录日志
+func (t *llmCallTrace) linkQuestions(questionIDs []uint) {
+	if t.lastCallID == 0 || len(questionIDs) == 0 {
+		return
+	}
+	if err := dao.NewLLMCallDao(config.DB).SetCallQuestionIDs(t.lastCallID, questionIDs); err != nil {
+		log.Printf("大模型调用记录%d关联题目失败：%v", t.lastCallID, err)
+	}
+}
+
+// llmProviderName 返回实际使用的服务名称（为空时为默认服务）
+func llmProviderName(provider string) string {
+	if provider == "" {
+		return third_part.DefaultLLMProvider()
+	}
+	return provider
+}
+
+// tracedChat 按ctx中的调用上下文检查预算、调用大模型并记录调用结果（记录失败不影响调用结果）
+func tracedChat(ctx context.Context, trace *llmCallTrace, llm third_part.LLMProvider, prompt string, chat func(context.Context) (string, error)) (string, error) {
+	if err := checkLLMTokenBudget(trace.provider); err != nil {
+		return "", err
+	}
+
+	ctx, usage := third_part.WithUsage(ctx)
+	start := time.Now()
+	content, err := chat(ctx)
+	hash := sha256.Sum256([]byte(prompt))
+	call := &model.ExamLLMCall{
+		UserID:           trace.userID,
+		Scene:            trace.scene,
+		Provider:         trace.provider,
+		Model:            llm.ModelName(),
+		PromptHash:       hex.EncodeToString(hash[:]),
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		Cost:             llmTokenCost(trace.provider, usage.PromptTokens, usage.CompletionTokens),
+		LatencyMs:        time.Since(start).Milliseconds(),
+		Status:           consts.LLMCallStatusSucceeded,
+	}
+	if err != nil {
+		call.Status = consts.LLMCallStatusFailed
+		call.ErrorMsg = truncateRunes(err.Error(), 500)
+	}
+	if createErr := dao.NewLLMCallDao(config.DB).CreateCall(call); createErr != nil {
+		log.Printf("保存大模型调用记录失败：%v", createErr)
+	} else if err == nil {
+		trace.lastCallID = call.ID
+	}
+	return content, err
+}
+
+// checkLLMTokenBudget 检查今日全部服务及指定服务的token用量是否已达到预算
+func checkLLMTokenBudget(provider string) error {
+	since := startOfDay(time.Now())
+	for _, scope := range []string{"", provider} {
+		budget := llmDailyTokenBudget(scope)
+		if budget <= 0 {
+			continue
+		}
+		used, err := dao.NewLLMCallDao(config.DB).SumTokensSince(scope, since)
+		if err != nil {
+			return fmt.Errorf("查询今日token用量失败：%w", err)
+		}
+		if used >= budget {
+			name := scope
+			if name == "" {
+				name = "全部服务"
+			}
+			return fmt.Errorf("%w（%s今日已用%d，预算%d）", errLLMBudgetExceeded, name, used, budget)
+		}
+	}
+	return nil
+}
+
+// llmDailyTokenBudget 读取每日token预算，provider为空表示全部服务的预算，未配置或格式错误时返回0（不限制）
+func llmDailyTokenBudget(provider string) int64 {
+	key := consts.LLMDailyTokenBudgetEnv
+	if provider != "" {
+		key += "_" + strings.ToUpper(provider)
+	}
+	budget, err := strconv.ParseInt(strings.TrimSpace(os.Getenv(key)), 10, 64)
+	if err != nil || budget < 0 {
+		return 0
+	}
+	return budget
+}
+
+// llmTokenCost 按配置的单价（元/百万token）估算调用费用，未配置单价时为0
+func llmTokenCost(provider string, promptTokens, completionTokens int) float64 {
+	inputPrice, outputPrice := parseLLMTokenPrice(os.Getenv(consts.LLMTokenPriceEnvPrefix + strings.ToUpper(provider)))
+	return (float64(promptTokens)*inputPrice + float64(completionTokens)*outputPrice) / 1e6
+}
+
+// parseLLMTokenPrice 解析"输入单价,输出单价"格式的单价，只填一个时输入输出同价，格式错误时为0
+func parseLLMTokenPrice(value string) (float64, float64) {
+	parts := strings.Split(value, ",")
+	if len(parts) > 2 {
+		return 0, 0
+	}
+	prices := make([]float64, 0, 2)
+	for _, part := range parts {
+		price, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || price < 0 {
+			return 0, 0
+		}
+		prices = append(prices, price)
+	}
+	if len(prices) == 1 {
+		return prices[0], prices[0]
+	}
+	return prices[0], prices[1]
+}
+
+// LLMProviderUsage 统计区间内单个服务的用量汇总
+type LLMProviderUsage struct {
+	Provider         string  `json:"provider"`
+	CallCount        int64   `json:"call_count"`
+	FailedCount      int64   `json:"failed_count"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// LLMTokenBudget 今日token预算及用量，Provider为空表示全部服务
+type LLMTokenBudget struct {
+	Provider string `json:"provider"`
+	Budget   int64  `json:"budget"`
+	Used     int64  `json:"used"`
+}
+
+// LLMCallStatistics 大模型调用用量统计
+type LLMCallStatistics struct {
+	Start     string                        `json:"start"`
+	End       string                        `json:"end"`
+	Daily     []*dao.LLMCallDailyStatistics `json:"daily"`     // 按天、按服务
+	Providers []*LLMProviderUsage           `json:"providers"` // 按服务汇总
+	Budgets   []*LLMTokenBudget             `json:"budgets"`   // 已配置的今日预算
+}
+
+// GetLLMCallStatisticsService 按天、按服务统计[start, end]内的大模型调用（日期格式2006-01-02，默认最近7天），
+// 并返回已配置预算的今日用量
+func GetLLMCallStatisticsService(start, end string) (*LLMCallStatistics, error) {
+	startDay, endDay, err := parseLLMCallStatsRange(start, end, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	callDao := dao.NewLLMCallDao(config.DB)
+	daily, err := callDao.GetDailyStatistics(startDay, endDay.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	budgets := []*LLMTokenBudget{}
+	today := startOfDay(time.Now())
+	for _, provider := range append([]string{""}, third_part.LLMProviders...) {
+		budget := llmDailyTokenBudget(provider)
+		if budget <= 0 {
+			continue
+		}
+		used, err := callDao.SumTokensSince(provider, today)
+		if err != nil {
+			return nil, err
+		}
+		budgets = append(budgets, &LLMTokenBudget{Provider: provider, Budget: budget, Used: used})
+	}
+
+	return &LLMCallStatistics{
+		Start:     startDay.Format(time.DateOnly),
+		End:       endDay.Format(time.DateOnly),
+		Daily:     daily,
+		Providers: summarizeLLMProviderUsage(daily),
+		Budgets:   budgets,
+	}, nil
+}
+
+// parseLLMCallStatsRange 解析统计日期区间（含首尾两天），end默认今天、start默认end前6天，区间不超过LLMCallStatsMaxDays天
+func parseLLMCallStatsRange(start, end string, now time.Time) (time.Time, time.Time, error) {
+	endDay := startOfDay(now)
+	if end != "" {
+		day, err := time.ParseInLocation(time.DateOnly, end, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("结束日期格式错误，应为2006-01-02")
+		}
+		endDay = day
+	}
+	startDay := endDay.AddDate(0, 0, -6)
+	if start != "" {
+		day, err := time.ParseInLocation(time.DateOnly, start, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("开始日期格式错误，应为2006-01-02")
+		}
+		startDay = day
+	}
+	if startDay.After(endDay) {
+		return time.Time{}, time.Time{}, errors.New("开始日期不能晚于结束日期")
+	}
+	if startDay.AddDate(0, 0, consts.LLMCallStatsMaxDays).Before(endDay.AddDate(0, 0, 1)) {
+		return time.Time{}, time.Time{}, fmt.Errorf("统计区间不能超过%d天", consts.LLMCallStatsMaxDays)
+	}
+	return startDay, endDay, nil
+}
+
+// summarizeLLMProviderUsage 将按天的统计汇总为按服务的用量（按服务名排序）
+func summarizeLLMProviderUsage(daily []*dao.LLMCallDailyStatistics) []*LLMProviderUsage {
+	usages := make(map[string]*LLMProviderUsage)
+	for _, row := range daily {
+		usage, ok := usages[row.Provider]
+		if !ok {
+			usage = &LLMProviderUsage{Provider: row.Provider}
+			usages[row.Provider] = usage
+		}
+		usage.CallCount += row.CallCount
+		usage.FailedCount += row.FailedCount
+		usage.PromptTokens += row.PromptTokens
+		usage.CompletionTokens += row.CompletionTokens
+		usage.Cost += row.Cost
+	}
+	result := make([]*LLMProviderUsage, 0, len(usages))
+	for _, usage := range usages {
+		result = append(result, usage)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Provider < result[j].Provider })
+	return result
+}
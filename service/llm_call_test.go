@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/third_part"
+)
+
+func TestParseLLMTokenPrice(t *testing.T) {
+	input, output := parseLLMTokenPrice("0.8, 2")
+	assert.Equal(t, 0.8, input)
+	assert.Equal(t, 2.0, output)
+
+	input, output = parseLLMTokenPrice("1.5")
+	assert.Equal(t, 1.5, input)
+	assert.Equal(t, 1.5, output)
+
+	for _, value := range []string{"", "abc", "1,2,3", "-1,2"} {
+		input, output = parseLLMTokenPrice(value)
+		assert.Zero(t, input, value)
+		assert.Zero(t, output, value)
+	}
+}
+
+func TestLLMTokenCostAndBudget(t *testing.T) {
+	t.Setenv("LLM_TOKEN_PRICE_DOUBAO", "0.8,2")
+	assert.InDelta(t, 0.0028, llmTokenCost(third_part.LLMProviderDouBao, 1000, 1000), 1e-9)
+	assert.Zero(t, llmTokenCost(third_part.LLMProviderAli, 1000, 1000))
+
+	t.Setenv("LLM_DAILY_TOKEN_BUDGET", "100000")
+	t.Setenv("LLM_DAILY_TOKEN_BUDGET_ALI", "abc")
+	assert.Equal(t, int64(100000), llmDailyTokenBudget(""))
+	assert.Zero(t, llmDailyTokenBudget(third_part.LLMProviderAli))
+	assert.Zero(t, llmDailyTokenBudget(third_part.LLMProviderOpenAI))
+}
+
+func TestParseLLMCallStatsRange(t *testing.T) {
+	now := time.Date(2026, 10, 17, 15, 30, 0, 0, time.Local)
+
+	start, end, err := parseLLMCallStatsRange("", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10-11", start.Format(time.DateOnly))
+	assert.Equal(t, "2026-10-17", end.Format(time.DateOnly))
+
+	start, end, err = parseLLMCallStatsRange("2026-10-01", "2026-10-05", now)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10-01", start.Format(time.DateOnly))
+	assert.Equal(t, "2026-10-05", end.Format(time.DateOnly))
+
+	_, _, err = parseLLMCallStatsRange("2026-10-05", "2026-10-01", now)
+	assert.Error(t, err)
+	_, _, err = parseLLMCallStatsRange("2026/10/01", "", now)
+	assert.Error(t, err)
+	_, _, err = parseLLMCallStatsRange("2026-01-01", "2026-10-01", now)
+	assert.Error(t, err)
+}
+
+func TestSummarizeLLMProviderUsage(t *testing.T) {
+	usages := summarizeLLMProviderUsage([]*dao.LLMCallDailyStatistics{
+		{Day: "2026-10-16", Provider: "doubao", CallCount: 3, FailedCount: 1, PromptTokens: 100, CompletionTokens: 50, Cost: 0.1},
+		{Day: "2026-10-16", Provider: "ali", CallCount: 1, PromptTokens: 10, CompletionTokens: 5},
+		{Day: "2026-10-17", Provider: "doubao", CallCount: 2, PromptTokens: 40, CompletionTokens: 20, Cost: 0.05},
+	})
+	require.Len(t, usages, 2)
+	assert.Equal(t, "ali", usages[0].Provider)
+	assert.Equal(t, int64(5), usages[1].CallCount)
+	assert.Equal(t, int64(1), usages[1].FailedCount)
+	assert.Equal(t, int64(140), usages[1].PromptTokens)
+	assert.Equal(t, int64(70), usages[1].CompletionTokens)
+	assert.InDelta(t, 0.15, usages[1].Cost, 1e-9)
+}
+
+// 超出预算的错误不重试
+func TestRetryAIGenerateStopsOnBudgetExceeded(t *testing.T) {
+	calls := 0
+	err := retryAIGenerate(context.Background(), 3, func() error {
+		calls++
+		return errors.Join(errors.New("调用AI接口失败"), errLLMBudgetExceeded)
+	})
+	assert.ErrorIs(t, err, errLLMBudgetExceeded)
+	assert.Equal(t, 1, calls)
+}
+
+// 假模型按字符数上报用量
+func TestFakeLLMReportsUsage(t *testing.T) {
+	ctx, usage := third_part.WithUsage(context.Background())
+	_, err := third_part.NewFakeLLMService("回复").Chat(ctx, "提示词")
+	require.NoError(t, err)
+	assert.Equal(t, third_part.Usage{PromptTokens: 3, CompletionTokens: 2}, *usage)
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/third_part"
+)
+
+// 测试按名称选择大模型服务，并映射到对应的题目录入方式
+func TestResolveLLMProvider(t *testing.T) {
+	testCases := []struct {
+		provider   string
+		uploadType int8
+	}{
+		{third_part.LLMProviderDouBao, consts.QuestionImportTypeAiDouBao},
+		{third_part.LLMProviderAli, consts.QuestionImportTypeAiAli},
+		{third_part.LLMProviderYunWu, consts.QuestionImportTypeAiYunWu},
+		{third_part.LLMProviderOpenAI, consts.QuestionImportTypeAiOpenAI},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.provider, func(t *testing.T) {
+			llm, uploadType, err := resolveLLMProvider(tc.provider, "")
+			assert.NoError(t, err)
+			assert.NotEmpty(t, llm.ModelName())
+			assert.Equal(t, tc.uploadType, uploadType)
+		})
+	}
+
+	llm, _, err := resolveLLMProvider(third_part.LLMProviderAli, "qwen-max")
+	assert.NoError(t, err)
+	assert.Equal(t, "qwen-max", llm.ModelName())
+
+	_, _, err = resolveLLMProvider("unknown", "")
+	assert.Error(t, err)
+	_, _, err = resolveLLMProvider("fake", "")
+	assert.Error(t, err)
+	assert.Error(t, validateLLMProvider("unknown"))
+	assert.Error(t, validateLLMProvider("fake"))
+	assert.NoError(t, validateLLMProvider(""))
+}
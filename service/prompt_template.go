@@ -0,0 +1,358 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/vaynedu/exam_system/config"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/model"
+	"gorm.io/gorm"
+)
+
+// defaultAIGeneratePromptTemplate 内置默认提示词模板，首次启动时写入模板表作为default模板的第1版
+const defaultAIGeneratePromptTemplate = `请根据以下要求生成面试练习题。
+题目要求：{{.Requirements}}
+题型：{{.QuestionTypeName}}（question_type={{.QuestionType}}）
+题目数量：{{.Count}}
+{{if .Difficulty}}难度：{{.Difficulty}}
+{{end}}一级分类：{{.Tag}}
+二级分类：{{.SecondTag}}
+
+只输出一个JSON对象，不要输出其他内容，格式为：
+{"questions": [{"question_type": {{.QuestionType}}, "question_title": "题干", "options": ["选项A内容", "选项B内容"], "correct_answer": "正确答案", "answer_analysis": "答案解析", "question_remark": "来源、难度、考察点", "tag": "{{.Tag}}", "second_tag": "{{.SecondTag}}"}]}
+
+字段约定：
+1. options：选择题、多选题、排序题、连线题按A、B、C...的顺序给出2-8个选项内容（不要带“A.”前缀），连线题每个选项为"左项|右项"且右项需打乱顺序，其他题型为空数组；
+2. correct_answer：选择题为单个字母如"B"，多选题为多个字母如"ACD"，判断题为"对"或"错"，排序题为正确顺序如"CABD"，连线题为左项字母与右项序号（按选项顺序从1编号）的配对如"A3,B1,C2"，
+   填空题多个空之间用"；"分隔、同一空的多个可接受答案用"|"分隔，问答题为完整的参考答案；
+3. answer_analysis：针对正确答案做分析，必须填写；
+4. 所有字段都是字符串（question_type为整数），题干和答案中可以包含任意字符。
+{{- if .Examples}}
+
+参考题目（仅参考出题风格和深度，不要出相同的题目）：
+{{- range .Examples}}
+- 题目：{{.QuestionTitle}}
+{{- if .Options}}
+  选项：{{join .Options "；"}}
+{{- end}}
+  参考答案：{{.CorrectAnswer}}
+{{- if .AnswerAnalysis}}
+  答案解析：{{.AnswerAnalysis}}
+{{- end}}
+{{- end}}
+{{- end}}`
+
+// promptTemplateNamePattern 模板名称只允许字母、数字、下划线和中划线
+var promptTemplateNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// promptTemplateFuncs 模板中可用的函数
+var promptTemplateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// PromptTemplateData 渲染提示词模板时可用的字段
+type PromptTemplateData struct {
+	Tag              string
+	SecondTag        string
+	QuestionType     int
+	QuestionTypeName string
+	Count            int
+	Difficulty       string
+	Requirements     string
+	Examples         []PromptExample // few-shot示例题目
+}
+
+// PromptExample few-shot示例题目
+type PromptExample struct {
+	QuestionTitle  string
+	Options        []string
+	CorrectAnswer  string
+	AnswerAnalysis string
+}
+
+// SavePromptTemplateRequest 新增或修改提示词模板请求参数（修改时新增一个版本，名称不可修改）
+type SavePromptTemplateRequest struct {
+	Name        string `json:"name"`
+	Content     string `json:"content"`
+	Description string `json:"description"`
+}
+
+// InitPromptTemplatesService 初始化提示词模板：默认模板不存在时写入内置模板
+func InitPromptTemplatesService() error {
+	_, err := dao.NewPromptTemplateDao(config.DB).GetLatestTemplate(consts.DefaultPromptTemplateName)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return dao.NewPromptTemplateDao(config.DB).CreateTemplate(&model.ExamPromptTemplate{
+		Name:        consts.DefaultPromptTemplateName,
+		Version:     1,
+		Content:     defaultAIGeneratePromptTemplate,
+		Description: "内置默认模板",
+		Enabled:     true,
+	})
+}
+
+// GetPromptTemplatesService 获取全部启用模板的最新版本
+func GetPromptTemplatesService() ([]*model.ExamPromptTemplate, error) {
+	return dao.NewPromptTemplateDao(config.DB).GetLatestEnabledTemplates()
+}
+
+// GetPromptTemplateVersionsService 获取模板的全部版本（版本号倒序）
+func GetPromptTemplateVersionsService(name string) ([]*model.ExamPromptTemplate, error) {
+	tpls, err := dao.NewPromptTemplateDao(config.DB).GetTemplateVersions(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(tpls) == 0 || !tpls[0].Enabled {
+		return nil, fmt.Errorf("模板%s不存在", name)
+	}
+	return tpls, nil
+}
+
+// CreatePromptTemplateService 新增模板（第1版）；同名模板已删除时作为其新版本重新启用
+func CreatePromptTemplateService(userID uint, req *SavePromptTemplateRequest) (*model.ExamPromptTemplate, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := validatePromptTemplateName(name); err != nil {
+		return nil, err
+	}
+
+	version := 1
+	latest, err := dao.NewPromptTemplateDao(config.DB).GetLatestTemplate(name)
+	switch {
+	case err == nil && latest.Enabled:
+		return nil, fmt.Errorf("模板%s已存在，请修改模板生成新版本", name)
+	case err == nil:
+		version = latest.Version + 1
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+	return savePromptTemplateVersion(userID, name, version, req)
+}
+
+// UpdatePromptTemplateService 修改模板：保存为新版本，已有版本保持不变以便追溯已生成的题目
+func UpdatePromptTemplateService(userID uint, name string, req *SavePromptTemplateRequest) (*model.ExamPromptTemplate, error) {
+	latest, err := dao.NewPromptTemplateDao(config.DB).GetLatestTemplate(name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("模板%s不存在", name)
+		}
+		return nil, err
+	}
+	if !latest.Enabled {
+		return nil, fmt.Errorf("模板%s不存在", name)
+	}
+	return savePromptTemplateVersion(userID, name, latest.Version+1, req)
+}
+
+// DeletePromptTemplateService 删除模板：停用全部版本（保留历史以便追溯题目），默认模板不可删除
+func DeletePromptTemplateService(name string) error {
+	if name == consts.DefaultPromptTemplateName {
+		return errors.New("默认模板不可删除")
+	}
+	count, err := dao.NewPromptTemplateDao(config.DB).DisableTemplate(name)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("模板%s不存在", name)
+	}
+	return nil
+}
+
+// savePromptTemplateVersion 校验模板内容并保存为指定版本
+func savePromptTemplateVersion(userID uint, name string, version int, req *SavePromptTemplateRequest) (*model.ExamPromptTemplate, error) {
+	if err := validatePromptTemplateContent(req.Content); err != nil {
+		return nil, err
+	}
+	if utf8.RuneCountInString(req.Description) > 500 {
+		return nil, errors.New("模板说明不能超过500个字符")
+	}
+
+	tpl := &model.ExamPromptTemplate{
+		Name:        name,
+		Version:     version,
+		Content:     req.Content,
+		Description: strings.TrimSpace(req.Description),
+		Enabled:     true,
+		CreatedBy:   userID,
+	}
+	if err := dao.NewPromptTemplateDao(config.DB).CreateTemplate(tpl); err != nil {
+		return nil, fmt.Errorf("保存模板失败：%w", err)
+	}
+	return tpl, nil
+}
+
+// validatePromptTemplateName 校验模板名称
+func validatePromptTemplateName(name string) error {
+	if name == "" {
+		return errors.New("模板名称不能为空")
+	}
+	if len(name) > consts.PromptTemplateNameMaxLen {
+		return fmt.Errorf("模板名称不能超过%d个字符", consts.PromptTemplateNameMaxLen)
+	}
+	if !promptTemplateNamePattern.MatchString(name) {
+		return errors.New("模板名称只能包含字母、数字、下划线和中划线")
+	}
+	return nil
+}
+
+// validatePromptTemplateContent 校验模板内容：语法正确，且能用示例数据渲染（引用了不存在的字段时报错）
+func validatePromptTemplateContent(content string) error {
+	if strings.TrimSpace(content) == "" {
+		return errors.New("模板内容不能为空")
+	}
+	if utf8.RuneCountInString(content) > consts.PromptTemplateContentMaxLen {
+		return fmt.Errorf("模板内容不能超过%d个字符", consts.PromptTemplateContentMaxLen)
+	}
+	tpl, err := parsePromptTemplate(content)
+	if err != nil {
+		return fmt.Errorf("模板语法错误：%w", err)
+	}
+	if _, err := renderPromptTemplate(tpl, samplePromptTemplateData()); err != nil {
+		return fmt.Errorf("模板渲染失败：%w", err)
+	}
+	return nil
+}
+
+// parsePromptTemplate 解析模板内容
+func parsePromptTemplate(content string) (*template.Template, error) {
+	return template.New("prompt").Funcs(promptTemplateFuncs).Option("missingkey=error").Parse(content)
+}
+
+// renderPromptTemplate 渲染模板
+func renderPromptTemplate(tpl *template.Template, data *PromptTemplateData) (string, error) {
+	var builder strings.Builder
+	if err := tpl.Execute(&builder, data); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// samplePromptTemplateData 校验模板时使用的示例数据
+func samplePromptTemplateData() *PromptTemplateData {
+	return &PromptTemplateData{
+		Tag:              "数据存储",
+		SecondTag:        "Redis",
+		QuestionType:     consts.QuestionTypeShortAnswer,
+		QuestionTypeName: consts.GetQuestionTypeName(consts.QuestionTypeShortAnswer),
+		Count:            5,
+		Difficulty:       "中等",
+		Requirements:     "考察缓存的常见问题",
+		Examples: []PromptExample{{
+			QuestionTitle:  "什么是缓存雪崩？如何避免？",
+			CorrectAnswer:  "大量缓存同时失效导致请求打到数据库……",
+			AnswerAnalysis: "从过期时间打散、多级缓存、限流降级等方面回答",
+		}},
+	}
+}
+
+// aiPromptContext 一次生成使用的提示词模板版本及few-shot示例
+type aiPromptContext struct {
+	template *model.ExamPromptTemplate
+	compiled *template.Template
+	examples []PromptExample
+}
+
+// loadAIPromptContext 加载提示词模板（name为空使用默认模板，version为0使用最新版本）及示例题目
+func loadAIPromptContext(name string, version int, exampleIDs []uint) (*aiPromptContext, error) {
+	if name == "" {
+		name = consts.DefaultPromptTemplateName
+	}
+	tplDao := dao.NewPromptTemplateDao(config.DB)
+	latest, err := tplDao.GetLatestTemplate(name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("提示词模板%s不存在", name)
+		}
+		return nil, err
+	}
+	if !latest.Enabled {
+		return nil, fmt.Errorf("提示词模板%s已删除", name)
+	}
+	tpl := latest
+	if version > 0 && version != latest.Version {
+		if tpl, err = tplDao.GetTemplateVersion(name, version); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("提示词模板%s不存在第%d版", name, version)
+			}
+			return nil, err
+		}
+	}
+	return newAIPromptContext(tpl, exampleIDs)
+}
+
+// loadAIPromptContextByID 按模板版本ID加载提示词模板（异步任务提交时已确定版本）
+func loadAIPromptContextByID(id uint, exampleIDs []uint) (*aiPromptContext, error) {
+	tpl, err := dao.NewPromptTemplateDao(config.DB).GetTemplateByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("加载提示词模板失败：%w", err)
+	}
+	return newAIPromptContext(tpl, exampleIDs)
+}
+
+// newAIPromptContext 编译模板并从题库加载示例题目（只能使用已通过审核的题目作为示例）
+func newAIPromptContext(tpl *model.ExamPromptTemplate, exampleIDs []uint) (*aiPromptContext, error) {
+	compiled, err := parsePromptTemplate(tpl.Content)
+	if err != nil {
+		return nil, fmt.Errorf("提示词模板%s第%d版语法错误：%w", tpl.Name, tpl.Version, err)
+	}
+
+	var examples []PromptExample
+	if len(exampleIDs) > 0 {
+		ids := uniqueIDs(exampleIDs)
+		questions, err := dao.NewQuestionDao(config.DB).GetApprovedQuestionsByIDList(ids)
+		if err != nil {
+			return nil, err
+		}
+		if len(questions) != len(ids) {
+			return nil, fmt.Errorf("示例题目%v不存在或未通过审核，只能使用已通过审核的题目作为示例", unapprovedExampleIDs(ids, questions))
+		}
+		for _, question := range questions {
+			examples = append(examples, PromptExample{
+				QuestionTitle:  question.QuestionTitle,
+				Options:        question.Options,
+				CorrectAnswer:  question.CorrectAnswer,
+				AnswerAnalysis: question.AnswerAnalysis,
+			})
+		}
+	}
+	return &aiPromptContext{template: tpl, compiled: compiled, examples: examples}, nil
+}
+
+// unapprovedExampleIDs 按请求顺序返回未加载到（不存在或未通过审核）的示例题目ID
+func unapprovedExampleIDs(ids []uint, approved []model.ExamQuestion) []uint {
+	approvedIDs := make([]uint, 0, len(approved))
+	for _, question := range approved {
+		approvedIDs = append(approvedIDs, question.ID)
+	}
+	_, missing := splitReviewedIDs(ids, approvedIDs)
+	return missing
+}
+
+// validatePromptOptions 校验生成请求中与提示词相关的参数
+func validatePromptOptions(promptTemplate string, promptVersion int, difficulty string, exampleIDs []uint) error {
+	if promptTemplate != "" {
+		if err := validatePromptTemplateName(promptTemplate); err != nil {
+			return err
+		}
+	}
+	if promptVersion < 0 {
+		return errors.New("模板版本号无效")
+	}
+	if utf8.RuneCountInString(difficulty) > consts.PromptDifficultyMaxLen {
+		return fmt.Errorf("难度描述不能超过%d个字符", consts.PromptDifficultyMaxLen)
+	}
+	if len(exampleIDs) > consts.PromptExampleMaxCount {
+		return fmt.Errorf("示例题目最多%d道", consts.PromptExampleMaxCount)
+	}
+	return nil
+}
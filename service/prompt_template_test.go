@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+)
+
+// newTestPromptContext 使用指定模板内容构建提示词上下文（不访问数据库）
+func newTestPromptContext(t *testing.T, content string, examples ...PromptExample) *aiPromptContext {
+	compiled, err := parsePromptTemplate(content)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return &aiPromptContext{
+		template: &model.ExamPromptTemplate{ID: 7, Name: "test", Version: 3, Content: content},
+		compiled: compiled,
+		examples: examples,
+	}
+}
+
+func TestDefaultPromptTemplate(t *testing.T) {
+	assert.NoError(t, validatePromptTemplateContent(defaultAIGeneratePromptTemplate))
+
+	prompt := newTestPromptContext(t, defaultAIGeneratePromptTemplate, PromptExample{
+		QuestionTitle: "Redis有哪些数据结构",
+		Options:       []string{"String", "Hash"},
+		CorrectAnswer: "AB",
+	})
+	req := &GenerateAIQuestionRequest{
+		QuestionType: consts.QuestionTypeShortAnswer,
+		Tag:          "数据存储",
+		SecondTag:    "Redis",
+		Count:        3,
+		Difficulty:   "困难",
+		Requirements: "考察持久化",
+	}
+	content, err := buildAIGeneratePrompt(prompt, req)
+	assert.NoError(t, err)
+	assert.Contains(t, content, "题目要求：考察持久化")
+	assert.Contains(t, content, "题型：简答题（question_type=2）")
+	assert.Contains(t, content, "难度：困难\n一级分类：数据存储")
+	assert.Contains(t, content, `"question_type": 2,`)
+	assert.Contains(t, content, `"second_tag": "Redis"`)
+	assert.Contains(t, content, "- 题目：Redis有哪些数据结构\n  选项：String；Hash\n  参考答案：AB")
+
+	prompt = newTestPromptContext(t, defaultAIGeneratePromptTemplate)
+	req.Difficulty = ""
+	content, err = buildAIGeneratePrompt(prompt, req)
+	assert.NoError(t, err)
+	assert.NotContains(t, content, "难度：")
+	assert.NotContains(t, content, "参考题目")
+	assert.Contains(t, content, "题目数量：3\n一级分类：数据存储")
+}
+
+func TestValidatePromptTemplateContent(t *testing.T) {
+	assert.NoError(t, validatePromptTemplateContent("为{{.Tag}}/{{.SecondTag}}出{{.Count}}道{{.QuestionTypeName}}"))
+	assert.ErrorContains(t, validatePromptTemplateContent("{{.Tag"), "语法错误")
+	assert.ErrorContains(t, validatePromptTemplateContent("{{.Unknown}}"), "渲染失败")
+	assert.Error(t, validatePromptTemplateContent("  "))
+}
+
+func TestValidatePromptTemplateName(t *testing.T) {
+	assert.NoError(t, validatePromptTemplateName("redis-hard_v2"))
+	assert.Error(t, validatePromptTemplateName(""))
+	assert.Error(t, validatePromptTemplateName("中文名"))
+	assert.Error(t, validatePromptTemplateName("a b"))
+}
+
+func TestValidatePromptOptions(t *testing.T) {
+	assert.NoError(t, validatePromptOptions("", 0, "中等", []uint{1, 2}))
+	assert.Error(t, validatePromptOptions("bad name", 0, "", nil))
+	assert.Error(t, validatePromptOptions("", -1, "", nil))
+	assert.Error(t, validatePromptOptions("", 0, "", []uint{1, 2, 3, 4, 5, 6}))
+}
+
+func TestUnapprovedExampleIDs(t *testing.T) {
+	approved := []model.ExamQuestion{{ID: 3}, {ID: 1}}
+	assert.Equal(t, []uint{2, 5}, unapprovedExampleIDs([]uint{1, 2, 3, 5}, approved))
+	assert.Equal(t, []uint{}, unapprovedExampleIDs([]uint{1, 3}, approved))
+}
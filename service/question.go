@@ -6,51 +6,69 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/vaynedu/exam_system/config"
 	"github.com/vaynedu/exam_system/consts"
 	"github.com/vaynedu/exam_system/dao"
 	"github.com/vaynedu/exam_system/model"
-	"github.com/xuri/excelize/v2"
 	"gorm.io/gorm"
 )
 
-// AddQuestionService 新增题目服务
-func AddQuestionService(question *model.ExamQuestion) error {
-	// 1. 题型合法性校验（0/1/2）
-	if question.QuestionType != 0 && question.QuestionType != 1 && question.QuestionType != 2 {
-		return errors.New("题型无效！仅支持0（选择题）、1（填空题）、2（问答题）")
+// AddQuestionService 新增题目服务，duplicatePolicy为与题库中已有题目重复时的处理策略：
+// skip（默认）返回错误，flag仍然新增并标记疑似重复，merge不新增而是合并到已有题目；发现重复时返回重复情况
+func AddQuestionService(question *model.ExamQuestion, duplicatePolicy string) (*QuestionDuplicate, error) {
+	policy, err := validateDuplicatePolicy(duplicatePolicy)
+	if err != nil {
+		return nil, err
 	}
 
-	// 2. 通用校验：题干和正确答案不能为空
-	if question.QuestionTitle == "" || question.CorrectAnswer == "" {
-		return errors.New("题干和正确答案不能为空！")
+	// 1-4. 题目内容与分类校验
+	if err := validateQuestion(question); err != nil {
+		return nil, err
 	}
 
-	// 3. 选择题专属校验
-	if question.QuestionType == 0 {
-		if question.OptionA == "" || question.OptionB == "" || question.OptionC == "" || question.OptionD == "" {
-			return errors.New("选择题的选项A-D不能为空！")
-		}
-		// 校验选择题答案
-		validAnswers := []string{"A", "B", "C", "D"}
-		isValid := false
-		for _, a := range validAnswers {
-			if strings.ToUpper(question.CorrectAnswer) == a {
-				isValid = true
-				break
-			}
-		}
-		if !isValid {
-			return errors.New("选择题正确答案只能是A/B/C/D！")
-		}
+	// 5.题目上传方式（手动录入的题目直接进入正式题库）
+	question.UploadType = consts.QuestionImportTypeManual
+	question.Status = consts.QuestionStatusApproved
+	question.ReviewedBy, question.ReviewedAt, question.RejectReason = 0, nil, ""
+	question.DuplicateOf = 0
+
+	// 6. 查重
+	questionDao := dao.NewQuestionDao(config.DB)
+	deduper, err := newDeduperWithBank(questionDao, []*model.ExamQuestion{question})
+	if err != nil {
+		return nil, err
+	}
+	duplicate, merge, keep := deduper.resolve(question, 0, policy)
+	if duplicate != nil && duplicate.Action == consts.DuplicatePolicySkip {
+		return duplicate, errors.New(duplicate.Description() + "，如需仍然新增请指定duplicate_policy=flag")
+	}
+	if merge != nil {
+		err = questionDao.MergeDuplicateQuestion(merge.targetID, question.AnswerAnalysis, question.QuestionRemark,
+			questionTagsWithMain(question))
+		return duplicate, err
+	}
+
+	// 7. 调用DAO层插入数据
+	if keep {
+		err = questionDao.CreateQuestion(question)
+	}
+	return duplicate, err
+}
+
+// validateQuestion 新增题目的完整校验：题型、题干、选项与正确答案，以及主分类和多分类（AI生成题目复用）
+func validateQuestion(question *model.ExamQuestion) error {
+	// 1-3. 题型、题干、选项与正确答案校验（按题型区分）
+	if err := validateQuestionContent(question); err != nil {
+		return err
 	}
 
 	// 【核心修改】使用新的Tag校验函数（适配知识树）
 	if question.Tag != "" {
 		// 1. 校验一级分类是否合法
 		if !consts.IsValidPrimaryTag(question.Tag) {
-			return errors.New("一级分类无效！请从合法分类中选择（算法、系统设计、数据存储、高频考点）")
+			return fmt.Errorf("一级分类无效！请从合法分类中选择（%s）", strings.Join(consts.GetPrimaryTagNames(), "、"))
 		}
 		// 2. 一级分类存在时，二级分类不能为空
 		if question.SecondTag == "" {
@@ -67,34 +85,44 @@ func AddQuestionService(question *model.ExamQuestion) error {
 		}
 	}
 
-	// 4.题目上传方式
-	question.UploadType = consts.QuestionImportTypeManual
-
-	// 5. 调用DAO层插入数据
-	return dao.NewQuestionDao(config.DB).CreateQuestion(question)
+	// 4. 多分类校验（主分类会自动加入分类列表）
+	if err := normalizeQuestionTags(question); err != nil {
+		return err
+	}
+	return nil
 }
 
-// GetRandomQuestionsService 随机获取题目服务
-func GetRandomQuestionsService(tag, secondTag string, limit int) ([]model.ExamQuestion, error) {
+// GetRandomQuestionsService 随机获取题目服务，tags为多分类筛选条件（matchAll=true时需同时命中全部分类）
+func GetRandomQuestionsService(tag, secondTag string, tags []dao.TagRef, matchAll bool, limit int) ([]model.ExamQuestion, error) {
 	// 校验标签参数
+	if err := ValidateRandomTagParams(tag, secondTag); err != nil {
+		return nil, err
+	}
+
+	// 调用DAO层获取随机题目
+	filter := BuildQuestionTagFilter(tag, secondTag, tags, matchAll)
+	return dao.NewQuestionDao(config.DB).GetRandomQuestionsByTags(filter, limit)
+}
+
+// ValidateRandomTagParams 随机抽题的标签参数校验（随机练习、错题练习复用），
+// 返回的错误均为参数错误，handler层据此返回400
+func ValidateRandomTagParams(tag, secondTag string) error {
 	if tag != "" {
 		if !IsValidPrimaryTag(tag) {
-			return nil, errors.New("一级分类无效")
+			return errors.New("一级分类无效")
 		}
 		if secondTag == "" {
-			return nil, errors.New("当指定一级分类时，二级分类不能为空")
+			return errors.New("当指定一级分类时，二级分类不能为空")
 		}
 		if !IsSecondaryOfPrimary(tag, secondTag) {
-			return nil, errors.New("二级分类与一级分类不匹配")
+			return errors.New("二级分类与一级分类不匹配")
 		}
 	} else {
 		if secondTag != "" {
-			return nil, errors.New("未指定一级分类时，不能单独指定二级分类")
+			return errors.New("未指定一级分类时，不能单独指定二级分类")
 		}
 	}
-
-	// 调用DAO层获取随机题目
-	return dao.NewQuestionDao(config.DB).GetRandomQuestionsByTag(tag, secondTag, limit)
+	return nil
 }
 
 // IsValidPrimaryTag 验证一级标签是否有效
@@ -108,28 +136,18 @@ func IsSecondaryOfPrimary(primary, secondary string) bool {
 	return consts.IsSecondaryOfPrimary(primary, secondary)
 }
 
-// GetQuestionsByFilterService 根据筛选条件获取题目列表服务
-func GetQuestionsByFilterService(tag, secondTag, questionType, keyword string, page, size int) ([]model.ExamQuestion, int64, error) {
+// GetQuestionsByFilterService 根据筛选条件获取题目列表服务（只展示已通过审核的题目）
+// tag/secondTag与tags均按题目的全部分类匹配（含下级分类），matchAll=true时需同时命中全部分类
+func GetQuestionsByFilterService(tag, secondTag string, tags []dao.TagRef, matchAll bool, questionType, keyword string, page, size int) ([]model.ExamQuestion, int64, error) {
+	return listQuestionsByStatus(consts.QuestionStatusApproved, tag, secondTag, tags, matchAll, questionType, keyword, page, size)
+}
+
+// listQuestionsByStatus 按审核状态及筛选条件分页获取题目
+func listQuestionsByStatus(status int, tag, secondTag string, tags []dao.TagRef, matchAll bool, questionType, keyword string, page, size int) ([]model.ExamQuestion, int64, error) {
 	offset := (page - 1) * size
 
 	// 构建查询条件
-	query := config.DB.Model(&model.ExamQuestion{})
-
-	if tag != "" {
-		query = query.Where("tag = ?", tag)
-	}
-	if secondTag != "" {
-		query = query.Where("second_tag = ?", secondTag)
-	}
-	if questionType != "" {
-		if typeInt, err := strconv.Atoi(questionType); err == nil {
-			query = query.Where("question_type = ?", typeInt)
-		}
-	}
-	if keyword != "" {
-		query = query.Where("question_title LIKE ? OR correct_answer LIKE ?",
-			"%"+keyword+"%", "%"+keyword+"%")
-	}
+	query := questionFilterQuery(status, tag, secondTag, tags, matchAll, questionType, keyword)
 
 	// 获取总数
 	var total int64
@@ -139,17 +157,42 @@ func GetQuestionsByFilterService(tag, secondTag, questionType, keyword string, p
 
 	// 获取分页数据
 	var questions []model.ExamQuestion
-	if err := query.Offset(offset).Limit(size).Order("id DESC").Find(&questions).Error; err != nil {
+	if err := query.Preload("Tags").Offset(offset).Limit(size).Order("id DESC").Find(&questions).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return questions, total, nil
 }
 
-// GetQuestionByIDService 根据ID获取题目详情服务
+// questionFilterQuery 构建按审核状态及筛选条件查询题目的条件（题目列表、AI补充解析复用）
+func questionFilterQuery(status int, tag, secondTag string, tags []dao.TagRef, matchAll bool, questionType, keyword string) *gorm.DB {
+	query := config.DB.Model(&model.ExamQuestion{}).Where("exam_questions.status = ?", status)
+
+	if tag == "" && secondTag != "" {
+		query = query.Where("second_tag = ?", secondTag)
+	}
+	query = dao.NewQuestionDao(config.DB).WithTagFilter(query, BuildQuestionTagFilter(tag, secondTag, tags, matchAll))
+	if questionType != "" {
+		if typeInt, err := strconv.Atoi(questionType); err == nil {
+			query = query.Where("question_type = ?", typeInt)
+		}
+	}
+	if keyword != "" {
+		query = query.Where("(question_title LIKE ? OR correct_answer LIKE ?)",
+			"%"+keyword+"%", "%"+keyword+"%")
+	}
+	return query
+}
+
+// GetQuestionByIDService 根据ID获取已通过审核的题目详情服务（待审核、已驳回的题目只能在审核队列中查看）
 func GetQuestionByIDService(id uint) (*model.ExamQuestion, error) {
+	return getQuestionWithTags(config.DB.Scopes(dao.ApprovedQuestions), id)
+}
+
+// getQuestionWithTags 根据ID获取题目及其全部分类，未找到时返回空对象
+func getQuestionWithTags(db *gorm.DB, id uint) (*model.ExamQuestion, error) {
 	var question model.ExamQuestion
-	if err := config.DB.Where("id = ?", id).First(&question).Error; err != nil {
+	if err := db.Preload("Tags").Where("id = ?", id).First(&question).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return &model.ExamQuestion{}, nil // 返回空对象表示未找到
 		}
@@ -160,32 +203,22 @@ func GetQuestionByIDService(id uint) (*model.ExamQuestion, error) {
 
 // UpdateQuestionService 更新题目服务
 func UpdateQuestionService(question *model.ExamQuestion) error {
-	// 题目校验（复用AddQuestionService的校验逻辑）
-	if question.QuestionType != 0 && question.QuestionType != 1 && question.QuestionType != 2 {
-		return errors.New("题型无效！仅支持0（选择题）、1（填空题）、2（问答题）")
-	}
-
-	if question.QuestionTitle == "" || question.CorrectAnswer == "" {
-		return errors.New("题干和正确答案不能为空！")
-	}
-
-	if question.QuestionType == 0 {
-		if question.OptionA == "" || question.OptionB == "" || question.OptionC == "" || question.OptionD == "" {
-			return errors.New("选择题的选项A-D不能为空！")
-		}
-		validAnswers := []string{"A", "B", "C", "D"}
-		isValid := false
-		for _, a := range validAnswers {
-			if strings.ToUpper(question.CorrectAnswer) == a {
-				isValid = true
-				break
-			}
+	// 填空题答案未修改时沿用原有的判分规则
+	if question.QuestionType == consts.QuestionTypeFillInTheBlank && len(question.Blanks) == 0 {
+		existing, err := dao.NewQuestionDao(config.DB).GetQuestionsByIDList([]uint{question.ID})
+		if err != nil {
+			return err
 		}
-		if !isValid {
-			return errors.New("选择题正确答案只能是A/B/C/D！")
+		if len(existing) > 0 {
+			keepStoredFillBlanks(question, &existing[0])
 		}
 	}
 
+	// 题目校验（复用AddQuestionService的校验逻辑）
+	if err := validateQuestionContent(question); err != nil {
+		return err
+	}
+
 	// 标签校验
 	if question.Tag != "" {
 		if !IsValidPrimaryTag(question.Tag) {
@@ -203,134 +236,209 @@ func UpdateQuestionService(question *model.ExamQuestion) error {
 		}
 	}
 
+	// 未传分类列表时保留原有的其他分类，仅替换主分类
+	if question.Tags == nil {
+		oldQuestion, err := getQuestionWithTags(config.DB, question.ID)
+		if err != nil {
+			return err
+		}
+		if oldQuestion.ID == 0 {
+			return errors.New("题目不存在")
+		}
+		for _, tag := range oldQuestion.Tags {
+			if tag.Tag != oldQuestion.Tag || tag.SecondTag != oldQuestion.SecondTag {
+				question.Tags = append(question.Tags, tag)
+			}
+		}
+	}
+	if err := normalizeQuestionTags(question); err != nil {
+		return err
+	}
+
 	// 调用DAO层更新
-	return dao.NewQuestionDao(config.DB).UpdateQuestion(question)
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		questionDao := dao.NewQuestionDao(tx)
+		if err := questionDao.UpdateQuestion(question); err != nil {
+			return err
+		}
+		return questionDao.ReplaceQuestionTags(question.ID, question.Tags)
+	})
 }
 
 // DeleteQuestionService 删除题目服务
 func DeleteQuestionService(id uint) error {
-	return dao.NewQuestionDao(config.DB).DeleteQuestion(id)
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		questionDao := dao.NewQuestionDao(tx)
+		if err := questionDao.DeleteQuestion(id); err != nil {
+			return err
+		}
+		return questionDao.DeleteQuestionTags(id)
+	})
 }
 
-// ImportExcelQuestions 解析Excel并导入题目（核心业务逻辑）
-func ImportExcelQuestions(fileReader io.Reader) (successCount, failCount, invalidRow int, err error) {
-	// 1. 解析Excel文件
-	excelFile, err := excelize.OpenReader(fileReader)
+// ImportExcelQuestions 解析导入文件（.xlsx的全部工作表或.csv）并在一个事务中导入题目（核心业务逻辑）：
+// 填写了题目编号且编号已存在的行更新对应题目（内容未变化时不更新），其余行查重后新增。
+// 未通过校验的行及原因记录在FailedRows中，被跳过或合并的重复题目记录在Duplicates中；
+// AllOrNothing为true且存在未通过校验的行时取消导入（Aborted），不写入任何题目
+func ImportExcelQuestions(fileReader io.Reader, opts *ExcelImportOptions) (*ExcelImportResult, error) {
+	policy, err := validateDuplicatePolicy(opts.DuplicatePolicy)
 	if err != nil {
-		return 0, 0, 0, fmt.Errorf("解析Excel失败：%w", err)
+		return nil, err
 	}
 
-	// 2. 读取工作表数据
-	sheetName := excelFile.GetSheetName(0)
-	rows, err := excelFile.GetRows(sheetName)
+	// 1. 解析导入文件，读取全部题目工作表
+	excelFile, sheets, err := openImportWorkbook(fileReader, opts.Filename)
 	if err != nil {
-		return 0, 0, 0, fmt.Errorf("读取Excel数据失败：%w", err)
+		return nil, err
+	}
+	defer excelFile.Close()
+
+	// 2. 解析&校验每行数据
+	result := &ExcelImportResult{FailedRows: []*ExcelImportRow{}}
+	var validRows []*ExcelImportRow
+	for _, row := range parseImportSheets(sheets, opts.SheetAsTag) {
+		if row.Error != "" {
+			result.FailedRows = append(result.FailedRows, row)
+			continue
+		}
+		validRows = append(validRows, row)
+	}
+	result.FailCount = len(result.FailedRows)
+	if opts.AllOrNothing && result.FailCount > 0 {
+		result.Aborted = true
+		return result, nil
+	}
+	if len(validRows) == 0 {
+		return result, nil
 	}
 
-	// 3. 校验数据行数（至少表头+1行数据）
-	if len(rows) <= 1 {
-		return 0, 0, 0, errors.New("excel无有效数据（需包含表头+至少1行题目）")
+	// 3. 按题目编号匹配已有题目，其余行与题库查重（以序号区分各工作表的行，再换算回工作表与行号）
+	existing, err := getQuestionsByExternalID(validRows)
+	if err != nil {
+		return nil, err
+	}
+	updates, newRows, unchanged := splitImportRowsByExternalID(validRows, existing)
+	questions := make([]*model.ExamQuestion, len(newRows))
+	labels := make([]int, len(newRows))
+	for i, row := range newRows {
+		questions[i], labels[i] = row.Question, i+1
+	}
+	plan, err := planQuestionDedupe(questions, labels, policy)
+	if err != nil {
+		return nil, err
 	}
 
-	// 4. 解析&校验每行数据
-	var questions []*model.ExamQuestion
-	invalidRow = 0
-	for i := 1; i < len(rows); i++ {
-		row := rows[i]
-		// 单行列数校验
-		if len(row) < 9 {
-			failCount++
-			invalidRow = getExcelRowNum(i)
-			continue
+	// 4. 在同一事务中新增、合并及更新题目，任一步失败则整体回滚
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		questionDao := dao.NewQuestionDao(tx)
+		if err := plan.save(questionDao); err != nil {
+			return err
 		}
-
-		// 提取并格式化数据
-		question, rowErr := parseAndValidateRow(row, i)
-		if rowErr != nil {
-			failCount++
-			invalidRow = getExcelRowNum(i)
-			continue
+		for _, update := range updates {
+			if err := updateImportedQuestion(questionDao, update.existing, update.question); err != nil {
+				return fmt.Errorf("更新题目#%d（编号%s）失败：%w", update.existing.ID, update.existing.ExternalID, err)
+			}
 		}
-
-		questions = append(questions, question)
-		successCount++
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("导入失败，已全部回滚：%w", err)
 	}
 
-	// 5. 批量插入数据库（调用DAO层）
-	if len(questions) > 0 {
-		questionDao := dao.NewQuestionDao(config.DB)
-		if err := questionDao.CreateQuestionsInBatches(questions, 100); err != nil {
-			return successCount, failCount, invalidRow, fmt.Errorf("批量插入失败：%w", err)
+	for _, dup := range plan.duplicates {
+		row := newRows[dup.Index-1]
+		dup.Sheet, dup.Index = row.Sheet, row.Row
+		if dup.DuplicateOf == 0 && dup.DuplicateIndex > 0 {
+			row = newRows[dup.DuplicateIndex-1]
+			dup.DuplicateSheet, dup.DuplicateIndex = row.Sheet, row.Row
 		}
 	}
-
-	return successCount, failCount, invalidRow, nil
+	result.InsertedCount = len(plan.kept)
+	result.UpdatedCount = len(updates)
+	result.UnchangedCount = unchanged
+	result.Duplicates = plan.duplicates
+	return result, nil
 }
 
-// parseAndValidateRow 解析并校验单行数据
-func parseAndValidateRow(row []string, rowIdx int) (*model.ExamQuestion, error) {
-	// 提取字段（trim空格）
-	typeStr := strings.TrimSpace(row[0])
-	title := strings.TrimSpace(row[1])
-	optA := strings.TrimSpace(row[2])
-	optB := strings.TrimSpace(row[3])
-	optC := strings.TrimSpace(row[4])
-	optD := strings.TrimSpace(row[5])
-	answer := strings.TrimSpace(strings.ToUpper(row[6]))
-	analysis := strings.TrimSpace(row[7])
-	remark := strings.TrimSpace(row[8])
-	tag := strings.TrimSpace(row[9])        // 一级分类
-	secondTag := strings.TrimSpace(row[10]) // 二级分类
-
-	// 1. 题型转换&校验
-	typeInt, err := strconv.Atoi(typeStr)
-	if err != nil || (typeInt != 0 && typeInt != 1 && typeInt != 2) {
-		return nil, errors.New("题型无效（仅支持0/1/2：选择/填空/问答）")
-	}
-
-	// 2. 通用必填项校验
-	if title == "" || answer == "" {
-		return nil, errors.New("题干/正确答案不能为空")
-	}
-
-	// 3. 选择题专属校验
-	if typeInt == 0 {
-		if optA == "" || optB == "" || optC == "" || optD == "" {
-			return nil, errors.New("选择题选项A-D不能为空")
-		}
-		validAnswers := []string{"A", "B", "C", "D"}
-		isValidAnswer := false
-		for _, a := range validAnswers {
-			if answer == a {
-				isValidAnswer = true
-				break
-			}
-		}
-		if !isValidAnswer {
-			return nil, errors.New("选择题答案仅支持A/B/C/D")
-		}
+// parseAndValidateRow 按列映射解析并校验单行数据，defaultTag为一级分类列为空时使用的一级分类（如工作表名），各题型约定：
+//   - 选择题(0)：从A开始填写2-8个选项，答案为单个字母，如“B”
+//   - 多选题(3)：从A开始填写2-8个选项，答案为多个字母，如“ACD”“A,C,D”
+//   - 填空题(1)：选项留空，多个空用分号或换行分隔，同一空的多个答案用竖线分隔，如“epoll|EPOLL；select”
+//   - 判断题(4)：选项留空，答案为对/错（也支持正确/错误、T/F、√/×）
+//   - 排序题(5)：从A开始填写2-8个待排序项，答案为正确顺序，如“CABD”“C>A>B>D”
+//   - 连线题(6)：从A开始填写2-8个“左项|右项”，右项依次编号为1、2、3...，答案为左项与右项序号的配对，如“A2,B3,C1”
+func parseAndValidateRow(row []string, columns *excelColumns, defaultTag string) (*model.ExamQuestion, error) {
+	// 提取字段（trim空格），缺少的列按空值处理
+	typeStr := rowCell(row, columns.Type)
+	title := rowCell(row, columns.Title)
+	answer := rowCell(row, columns.Answer) // 不转大写，避免破坏填空题答案；选项类答案由validateQuestionContent规范化
+	analysis := rowCell(row, columns.Analysis)
+	remark := rowCell(row, columns.Remark)
+	tag := rowCell(row, columns.Tag)             // 一级分类
+	secondTag := rowCell(row, columns.SecondTag) // 二级分类
+	externalID := rowCell(row, columns.ExternalID)
+	if tag == "" {
+		tag = defaultTag
 	}
 
-	// 4. 标签校验（调用consts层）
-	if err = validateTagRelation(tag, secondTag); err != nil {
-		return nil, err
+	// 选项A-H，去掉末尾未填写的选项
+	options := make(model.QuestionOptions, 0, consts.QuestionOptionMaxCount)
+	for _, col := range columns.Options {
+		options = append(options, rowCell(row, col))
+	}
+	for len(options) > 0 && options[len(options)-1] == "" {
+		options = options[:len(options)-1]
+	}
+
+	// 1. 题型转换（题型编号或题型名称）
+	typeInt, ok := parseQuestionTypeCell(typeStr)
+	if !ok {
+		return nil, errors.New("题型无效（支持0-6或题型名称：选择题/填空题/简答题/多选题/判断题/排序题/连线题）")
 	}
 
 	// 构造题目对象
-	return &model.ExamQuestion{
+	question := &model.ExamQuestion{
 		QuestionType:   int8(typeInt),
 		QuestionTitle:  title,
-		OptionA:        optA,
-		OptionB:        optB,
-		OptionC:        optC,
-		OptionD:        optD,
+		Options:        options,
 		CorrectAnswer:  answer,
 		AnswerAnalysis: analysis,
 		QuestionRemark: remark,
 		Tag:            tag,
 		SecondTag:      secondTag,
 		UploadType:     consts.QuestionImportTypeExcel,
-	}, nil
+		ExternalID:     externalID,
+	}
+	if utf8.RuneCountInString(externalID) > consts.QuestionExternalIDMaxLen {
+		return nil, fmt.Errorf("题目编号不能超过%d个字符", consts.QuestionExternalIDMaxLen)
+	}
+
+	// 2. 题干、选项与正确答案校验（按题型区分）
+	if err := validateQuestionContent(question); err != nil {
+		return nil, err
+	}
+
+	// 3. 标签校验（调用consts层）
+	if err := validateTagRelation(tag, secondTag); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+// parseQuestionTypeCell 解析Excel中的题型：题型编号（如“0”）或题型名称（如“选择题”）
+func parseQuestionTypeCell(value string) (int, bool) {
+	if typeInt, err := strconv.Atoi(value); err == nil {
+		return typeInt, consts.CheckQuestionType(typeInt)
+	}
+	return consts.GetQuestionTypeByName(value)
+}
+
+// rowCell 读取Excel行中指定列的内容（去除首尾空格），列不存在时返回空串
+func rowCell(row []string, idx int) string {
+	if idx < 0 || idx >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[idx])
 }
 
 // 辅助函数：获取Excel实际行号（索引+1）
@@ -361,42 +469,3 @@ func validateTagRelation(primaryTag, secondaryTag string) error {
 	}
 	return nil
 }
-
-// ExportExcelQuestionRequest 导出题目请求参数结构体
-type ExportExcelQuestionRequest struct {
-	IDs          []uint `json:"ids"`        // 指定题目ID列表
-	ExportAll    bool   `json:"export_all"` // 是否导出全部
-	Tag          string `json:"tag"`        // 一级分类
-	SecondTag    string `json:"second_tag"` // 二级分类
-	QuestionType string `json:"type"`       // 题型
-	Keyword      string `json:"keyword"`    // 关键词搜索
-}
-
-// ExportExcelQuestionService 导出Excel题目的服务函数
-func ExportExcelQuestionService(req ExportExcelQuestionRequest) ([]model.ExamQuestion, error) {
-	var questions []model.ExamQuestion
-	var err error
-
-	// 根据不同条件获取题目
-	if len(req.IDs) > 0 {
-		// 根据ID列表导出
-		questions, err = dao.NewQuestionDao(config.DB).GetQuestionsByIDList(req.IDs)
-		if err != nil {
-			return nil, fmt.Errorf("根据ID列表获取题目失败：%v", err)
-		}
-	} else if req.ExportAll {
-		// 导出全部题目
-		questions, err = dao.NewQuestionDao(config.DB).GetAllQuestions()
-		if err != nil {
-			return nil, fmt.Errorf("获取全部题目失败：%v", err)
-		}
-	} else {
-		// 根据筛选条件导出
-		questions, err = dao.NewQuestionDao(config.DB).GetQuestionsByFilter(req.Tag, req.SecondTag, req.QuestionType, req.Keyword)
-		if err != nil {
-			return nil, fmt.Errorf("根据筛选条件获取题目失败：%v", err)
-		}
-	}
-
-	return questions, nil
-}
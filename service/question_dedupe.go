@@ -0,0 +1,570 @@
+package service
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/vaynedu/exam_system/config"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/model"
+	"gorm.io/gorm"
+)
+
+// 题目查重：题干归一化（全角转半角、统一小写、去掉标点和空白）后计算MD5指纹判断完全重复，
+// 再按字符二元组（shingle）的Jaccard相似度判断近似重复，只在同一题型内比较。
+// 指纹保存在title_fingerprint列，新增题目时按指纹索引在整个题库中查找完全重复，近似重复只与同一级分类的题目比较，
+// 避免每次都将全部题目加载到内存
+
+// duplicateShingleSize 题干分片的字符数
+const duplicateShingleSize = 2
+
+// QuestionDuplicate 题目与已有题目（或同批次题目）重复的检测结果
+type QuestionDuplicate struct {
+	Sheet          string  `json:"sheet,omitempty"`           // 导入文件中所在的工作表
+	Index          int     `json:"index"`                     // 在本批次中的序号（Excel行号或AI回复序号），单题新增为0
+	QuestionTitle  string  `json:"question_title"`            // 新题目的题干
+	DuplicateOf    uint    `json:"duplicate_of"`              // 重复的已有题目ID，0表示与同批次的题目重复
+	DuplicateSheet string  `json:"duplicate_sheet,omitempty"` // 与同批次题目重复时，该题目所在的工作表
+	DuplicateIndex int     `json:"duplicate_index,omitempty"` // 与同批次题目重复时，该题目在本批次中的序号
+	DuplicateTitle string  `json:"duplicate_title"`
+	Similarity     float64 `json:"similarity"` // 题干相似度（0-1）
+	Action         string  `json:"action"`     // 实际采取的处理策略：skip/flag/merge
+}
+
+// Description 重复情况的文字说明
+func (d *QuestionDuplicate) Description() string {
+	target := fmt.Sprintf("题目#%d「%s」", d.DuplicateOf, d.DuplicateTitle)
+	if d.DuplicateOf == 0 {
+		target = fmt.Sprintf("同批次第%d条题目「%s」", d.DuplicateIndex, d.DuplicateTitle)
+		if d.DuplicateSheet != "" {
+			target = fmt.Sprintf("工作表%s第%d行题目「%s」", d.DuplicateSheet, d.DuplicateIndex, d.DuplicateTitle)
+		}
+	}
+	return fmt.Sprintf("与%s重复（相似度%.0f%%）", target, d.Similarity*100)
+}
+
+// ActionDescription 重复情况及处理结果的文字说明
+func (d *QuestionDuplicate) ActionDescription() string {
+	switch d.Action {
+	case consts.DuplicatePolicyFlag:
+		return d.Description() + "，已标记为疑似重复"
+	case consts.DuplicatePolicyMerge:
+		return d.Description() + "，已合并到该题目"
+	}
+	return d.Description() + "，已跳过"
+}
+
+// validateDuplicatePolicy 校验重复题目处理策略，返回规范化后的策略（默认跳过）
+func validateDuplicatePolicy(policy string) (string, error) {
+	policy = strings.ToLower(strings.TrimSpace(policy))
+	if !consts.CheckDuplicatePolicy(policy) {
+		return "", fmt.Errorf("不支持的重复题目处理策略：%s（可选skip/flag/merge）", policy)
+	}
+	if policy == "" {
+		policy = consts.DuplicatePolicySkip
+	}
+	return policy, nil
+}
+
+// normalizeQuestionTitle 题干归一化：全角转半角、统一小写，只保留字母、数字和汉字等文字字符
+func normalizeQuestionTitle(title string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(normalizeFillWidth(title)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// questionTitleFingerprint 归一化题干的MD5指纹，指纹相同即视为完全重复
+func questionTitleFingerprint(normalized string) string {
+	sum := md5.Sum([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// syncTitleFingerprint 按题干计算题目的指纹（新增、更新题干时调用）
+func syncTitleFingerprint(question *model.ExamQuestion) {
+	question.TitleFingerprint = questionTitleFingerprint(normalizeQuestionTitle(question.QuestionTitle))
+}
+
+// titleShingles 归一化题干的字符分片集合，题干短于分片长度时整体作为一个分片
+func titleShingles(normalized string) map[string]struct{} {
+	runes := []rune(normalized)
+	shingles := make(map[string]struct{}, len(runes))
+	if len(runes) == 0 {
+		return shingles
+	}
+	if len(runes) < duplicateShingleSize {
+		shingles[normalized] = struct{}{}
+		return shingles
+	}
+	for i := 0; i+duplicateShingleSize <= len(runes); i++ {
+		shingles[string(runes[i:i+duplicateShingleSize])] = struct{}{}
+	}
+	return shingles
+}
+
+// jaccardSimilarity 两个分片集合的Jaccard相似度（交集/并集）
+func jaccardSimilarity(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	intersection := 0
+	for shingle := range a {
+		if _, ok := b[shingle]; ok {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+// dedupeEntry 查重索引中的一道题目
+type dedupeEntry struct {
+	question    *model.ExamQuestion
+	label       int // 同批次题目的序号，已有题目为0
+	fingerprint string
+	shingles    map[string]struct{}
+}
+
+// duplicateMatch 查重命中的题目及相似度
+type duplicateMatch struct {
+	entry      *dedupeEntry
+	similarity float64
+}
+
+// questionDeduper 题目查重索引：按指纹查找完全重复，按分片倒排索引统计交集计算近似重复
+type questionDeduper struct {
+	threshold     float64
+	entries       []*dedupeEntry
+	byFingerprint map[string][]int
+	byShingle     map[string][]int
+}
+
+// newQuestionDeduper 创建查重索引，相似度达到threshold即视为重复
+func newQuestionDeduper(threshold float64) *questionDeduper {
+	return &questionDeduper{
+		threshold:     threshold,
+		byFingerprint: make(map[string][]int),
+		byShingle:     make(map[string][]int),
+	}
+}
+
+// add 将题目加入查重索引，归一化后题干为空的题目不参与查重
+func (d *questionDeduper) add(question *model.ExamQuestion, label int) {
+	normalized := normalizeQuestionTitle(question.QuestionTitle)
+	if normalized == "" {
+		return
+	}
+	idx := len(d.entries)
+	entry := &dedupeEntry{
+		question:    question,
+		label:       label,
+		fingerprint: questionTitleFingerprint(normalized),
+		shingles:    titleShingles(normalized),
+	}
+	d.entries = append(d.entries, entry)
+	d.byFingerprint[entry.fingerprint] = append(d.byFingerprint[entry.fingerprint], idx)
+	for shingle := range entry.shingles {
+		d.byShingle[shingle] = append(d.byShingle[shingle], idx)
+	}
+}
+
+// matches 查找索引中与题目同题型且相似度达到阈值的全部题目，按相似度降序、加入索引的先后排列
+func (d *questionDeduper) matches(question *model.ExamQuestion) []duplicateMatch {
+	normalized := normalizeQuestionTitle(question.QuestionTitle)
+	if normalized == "" {
+		return nil
+	}
+
+	similarities := make(map[int]float64)
+	for _, idx := range d.byFingerprint[questionTitleFingerprint(normalized)] {
+		similarities[idx] = 1
+	}
+	shingles := titleShingles(normalized)
+	intersections := make(map[int]int)
+	for shingle := range shingles {
+		for _, idx := range d.byShingle[shingle] {
+			intersections[idx]++
+		}
+	}
+	for idx, intersection := range intersections {
+		if _, ok := similarities[idx]; ok {
+			continue
+		}
+		union := len(shingles) + len(d.entries[idx].shingles) - intersection
+		if similarity := float64(intersection) / float64(union); similarity >= d.threshold {
+			similarities[idx] = similarity
+		}
+	}
+
+	result := make([]duplicateMatch, 0, len(similarities))
+	for idx, similarity := range similarities {
+		entry := d.entries[idx]
+		if entry.question.QuestionType != question.QuestionType {
+			continue
+		}
+		result = append(result, duplicateMatch{entry: entry, similarity: similarity})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].similarity != result[j].similarity {
+			return result[i].similarity > result[j].similarity
+		}
+		return result[i].entry.question.ID < result[j].entry.question.ID
+	})
+	return result
+}
+
+// questionMerge 需要合并到已有题目的重复题目
+type questionMerge struct {
+	targetID uint
+	source   *model.ExamQuestion
+}
+
+// resolve 按策略处理一道新题目：未重复时加入索引并入库；与已有题目重复时跳过、标记（仍入库）或合并；
+// 与同批次题目重复时不再入库，合并策略下将内容补充到同批次的题目中
+func (d *questionDeduper) resolve(question *model.ExamQuestion, label int, policy string) (duplicate *QuestionDuplicate, merge *questionMerge, keep bool) {
+	matches := d.matches(question)
+	if len(matches) == 0 {
+		d.add(question, label)
+		return nil, nil, true
+	}
+
+	// 优先与已有题目比较，其次为同批次题目
+	best := matches[0]
+	for _, match := range matches {
+		if match.entry.question.ID > 0 {
+			best = match
+			break
+		}
+	}
+	target := best.entry.question
+	duplicate = &QuestionDuplicate{
+		Index:          label,
+		QuestionTitle:  question.QuestionTitle,
+		DuplicateOf:    target.ID,
+		DuplicateTitle: target.QuestionTitle,
+		Similarity:     math.Round(best.similarity*100) / 100,
+		Action:         policy,
+	}
+
+	if target.ID == 0 {
+		duplicate.DuplicateIndex = best.entry.label
+		if policy == consts.DuplicatePolicyMerge {
+			mergeQuestionInto(target, question)
+		} else {
+			duplicate.Action = consts.DuplicatePolicySkip
+		}
+		return duplicate, nil, false
+	}
+
+	switch policy {
+	case consts.DuplicatePolicyFlag:
+		question.DuplicateOf = target.ID
+		return duplicate, nil, true
+	case consts.DuplicatePolicyMerge:
+		return duplicate, &questionMerge{targetID: target.ID, source: question}, false
+	}
+	return duplicate, nil, false
+}
+
+// mergeQuestionInto 将重复题目的内容补充到目标题目：只补充为空的答案解析和备注，并追加缺少的分类
+func mergeQuestionInto(target, source *model.ExamQuestion) {
+	if target.AnswerAnalysis == "" {
+		target.AnswerAnalysis = source.AnswerAnalysis
+	}
+	if target.QuestionRemark == "" {
+		target.QuestionRemark = source.QuestionRemark
+	}
+	target.Tags = unionQuestionTags(questionTagsWithMain(target), questionTagsWithMain(source))
+}
+
+// questionTagsWithMain 题目的全部分类（含主分类）
+func questionTagsWithMain(question *model.ExamQuestion) []*model.ExamQuestionTag {
+	tags := make([]*model.ExamQuestionTag, 0, len(question.Tags)+1)
+	if question.Tag != "" {
+		tags = append(tags, &model.ExamQuestionTag{Tag: question.Tag, SecondTag: question.SecondTag})
+	}
+	for _, tag := range question.Tags {
+		if tag != nil {
+			tags = append(tags, tag)
+		}
+	}
+	return unionQuestionTags(tags)
+}
+
+// unionQuestionTags 合并多组分类并按一级、二级分类去重，保持原有顺序
+func unionQuestionTags(groups ...[]*model.ExamQuestionTag) []*model.ExamQuestionTag {
+	var result []*model.ExamQuestionTag
+	seen := make(map[dao.TagRef]bool)
+	for _, tags := range groups {
+		for _, tag := range tags {
+			ref := dao.TagRef{Tag: tag.Tag, SecondTag: tag.SecondTag}
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+			result = append(result, &model.ExamQuestionTag{Tag: tag.Tag, SecondTag: tag.SecondTag})
+		}
+	}
+	return result
+}
+
+// newDeduperWithBank 加载题库中可能与questions重复的题目（已通过及待审核）构建查重索引：
+// 同题型中题干指纹相同的题目（按索引查询整个题库），以及同题型、同一级分类的题目（用于近似重复）
+func newDeduperWithBank(questionDao *dao.QuestionDao, questions []*model.ExamQuestion) (*questionDeduper, error) {
+	typeSet, tagSet, fingerprintSet := make(map[int8]bool), make(map[string]bool), make(map[string]bool)
+	filter := dao.DedupeCandidateFilter{Tags: []string{}, Fingerprints: []string{}}
+	for _, question := range questions {
+		if normalizeQuestionTitle(question.QuestionTitle) == "" {
+			continue
+		}
+		if !typeSet[question.QuestionType] {
+			typeSet[question.QuestionType] = true
+			filter.QuestionTypes = append(filter.QuestionTypes, question.QuestionType)
+		}
+		if !tagSet[question.Tag] {
+			tagSet[question.Tag] = true
+			filter.Tags = append(filter.Tags, question.Tag)
+		}
+		if question.TitleFingerprint == "" {
+			syncTitleFingerprint(question)
+		}
+		if !fingerprintSet[question.TitleFingerprint] {
+			fingerprintSet[question.TitleFingerprint] = true
+			filter.Fingerprints = append(filter.Fingerprints, question.TitleFingerprint)
+		}
+	}
+	deduper := newQuestionDeduper(consts.DuplicateSimilarityThreshold)
+	if len(filter.QuestionTypes) == 0 {
+		return deduper, nil
+	}
+
+	exact, err := questionDao.GetDedupeCandidates(dao.DedupeCandidateFilter{
+		QuestionTypes: filter.QuestionTypes, Fingerprints: filter.Fingerprints,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询题库查重数据失败：%w", err)
+	}
+	sameTag, err := questionDao.GetDedupeCandidates(dao.DedupeCandidateFilter{
+		QuestionTypes: filter.QuestionTypes, Tags: filter.Tags,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询题库查重数据失败：%w", err)
+	}
+	added := make(map[uint]bool, len(exact)+len(sameTag))
+	for _, existing := range [][]model.ExamQuestion{exact, sameTag} {
+		for i := range existing {
+			if !added[existing[i].ID] {
+				added[existing[i].ID] = true
+				deduper.add(&existing[i], 0)
+			}
+		}
+	}
+	return deduper, nil
+}
+
+// saveQuestionsWithDedupe 查重后批量入库：与题库及同批次的题目比较，按策略跳过、标记或合并重复题目，
+// labels为各题目在本批次中的序号（Excel行号或AI回复序号），返回实际新增的题目及重复记录
+func saveQuestionsWithDedupe(questions []*model.ExamQuestion, labels []int, policy string) ([]*model.ExamQuestion, []*QuestionDuplicate, error) {
+	plan, err := planQuestionDedupe(questions, labels, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		return plan.save(dao.NewQuestionDao(tx))
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return plan.kept, plan.duplicates, nil
+}
+
+// questionDedupePlan 查重结果：需新增的题目、需合并到已有题目的重复题目及重复记录
+type questionDedupePlan struct {
+	kept       []*model.ExamQuestion
+	merges     []*questionMerge
+	duplicates []*QuestionDuplicate
+}
+
+// planQuestionDedupe 与题库及同批次的题目比较，按策略确定每道题目新增、跳过、标记或合并（不写库）
+func planQuestionDedupe(questions []*model.ExamQuestion, labels []int, policy string) (*questionDedupePlan, error) {
+	deduper, err := newDeduperWithBank(dao.NewQuestionDao(config.DB), questions)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &questionDedupePlan{}
+	for i, question := range questions {
+		duplicate, merge, keep := deduper.resolve(question, labels[i], policy)
+		if duplicate != nil {
+			plan.duplicates = append(plan.duplicates, duplicate)
+		}
+		if merge != nil {
+			plan.merges = append(plan.merges, merge)
+		}
+		if keep {
+			plan.kept = append(plan.kept, question)
+		}
+	}
+	return plan, nil
+}
+
+// save 批量新增题目并合并重复题目，questionDao应在事务中创建
+func (p *questionDedupePlan) save(questionDao *dao.QuestionDao) error {
+	if len(p.kept) > 0 {
+		if err := questionDao.CreateQuestionsInBatches(p.kept, 100); err != nil {
+			return err
+		}
+	}
+	for _, merge := range p.merges {
+		if err := questionDao.MergeDuplicateQuestion(merge.targetID, merge.source.AnswerAnalysis,
+			merge.source.QuestionRemark, questionTagsWithMain(merge.source)); err != nil {
+			return fmt.Errorf("合并重复题目到题目#%d失败：%w", merge.targetID, err)
+		}
+	}
+	return nil
+}
+
+// DuplicateCluster 题库中疑似重复的一组题目
+type DuplicateCluster struct {
+	Questions     []model.ExamQuestion `json:"questions"`      // 按题目ID升序
+	MaxSimilarity float64              `json:"max_similarity"` // 组内题干的最高相似度
+}
+
+// GetDuplicateClustersService 查询题库（已通过及待审核）中疑似重复的题目聚类，questionType、tag为空表示不限，
+// threshold为0时使用默认阈值；参与比较的题目超过consts.DuplicateClusterMaxQuestions道时需缩小范围
+func GetDuplicateClustersService(questionType, tag string, threshold float64) ([]*DuplicateCluster, error) {
+	if threshold == 0 {
+		threshold = consts.DuplicateSimilarityThreshold
+	}
+	if threshold < consts.DuplicateSimilarityMinThreshold || threshold > 1 {
+		return nil, fmt.Errorf("相似度阈值需在%.1f-1之间", consts.DuplicateSimilarityMinThreshold)
+	}
+
+	var filter dao.DedupeCandidateFilter
+	if questionType != "" {
+		typeInt, err := strconv.Atoi(questionType)
+		if err != nil || !consts.CheckQuestionType(typeInt) {
+			return nil, errors.New("题型无效")
+		}
+		filter.QuestionTypes = []int8{int8(typeInt)}
+	}
+	if tag != "" {
+		filter.Tags = []string{tag}
+	}
+
+	questionDao := dao.NewQuestionDao(config.DB)
+	total, err := questionDao.CountDedupeCandidates(filter)
+	if err != nil {
+		return nil, err
+	}
+	if total > consts.DuplicateClusterMaxQuestions {
+		return nil, fmt.Errorf("参与比较的题目共%d道，超过单次上限%d道，请按题型或一级分类缩小范围",
+			total, consts.DuplicateClusterMaxQuestions)
+	}
+	questions, err := questionDao.GetDedupeCandidates(filter)
+	if err != nil {
+		return nil, err
+	}
+	return findDuplicateClusters(questions, threshold), nil
+}
+
+// findDuplicateClusters 将相似度达到阈值或已标记为重复的题目合并为聚类（并查集），
+// 只返回包含两道及以上题目的聚类，按题目数量降序、最小题目ID升序排列
+func findDuplicateClusters(questions []model.ExamQuestion, threshold float64) []*DuplicateCluster {
+	parent := make([]int, len(questions))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	maxSimilarity := make(map[int]float64) // 并查集根节点 -> 组内最高相似度
+	union := func(i, j int, similarity float64) {
+		ri, rj := find(i), find(j)
+		best := math.Max(similarity, math.Max(maxSimilarity[ri], maxSimilarity[rj]))
+		if ri != rj {
+			parent[rj] = ri
+			delete(maxSimilarity, rj)
+		}
+		maxSimilarity[ri] = best
+	}
+
+	deduper := newQuestionDeduper(threshold)
+	positions := make(map[*model.ExamQuestion]int, len(questions))
+	byID := make(map[uint]int, len(questions))
+	for i := range questions {
+		question := &questions[i]
+		for _, match := range deduper.matches(question) {
+			union(positions[match.entry.question], i, match.similarity)
+		}
+		deduper.add(question, 0)
+		positions[question] = i
+		byID[question.ID] = i
+	}
+	for i, question := range questions {
+		if j, ok := byID[question.DuplicateOf]; ok && question.DuplicateOf > 0 && j != i {
+			union(j, i, 0)
+		}
+	}
+
+	groups := make(map[int][]model.ExamQuestion)
+	var roots []int
+	for i := range questions {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], questions[i])
+	}
+
+	var clusters []*DuplicateCluster
+	for _, root := range roots {
+		members := groups[root]
+		if len(members) < 2 {
+			continue
+		}
+		sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+		clusters = append(clusters, &DuplicateCluster{
+			Questions:     members,
+			MaxSimilarity: math.Round(maxSimilarity[root]*100) / 100,
+		})
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if len(clusters[i].Questions) != len(clusters[j].Questions) {
+			return len(clusters[i].Questions) > len(clusters[j].Questions)
+		}
+		return clusters[i].Questions[0].ID < clusters[j].Questions[0].ID
+	})
+	return clusters
+}
+
+// BackfillTitleFingerprintsService 为尚未计算题干指纹的题目（新增指纹列之前的题目）补充指纹，返回补充的数量
+func BackfillTitleFingerprintsService() (int, error) {
+	questionDao := dao.NewQuestionDao(config.DB)
+	count := 0
+	err := questionDao.FindQuestionsWithoutFingerprintInBatches(1000, func(questions []model.ExamQuestion) error {
+		for i := range questions {
+			syncTitleFingerprint(&questions[i])
+			if err := questionDao.SetTitleFingerprint(questions[i].ID, questions[i].TitleFingerprint); err != nil {
+				return err
+			}
+		}
+		count += len(questions)
+		return nil
+	})
+	return count, err
+}
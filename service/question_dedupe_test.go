@@ -0,0 +1,159 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+)
+
+func TestNormalizeQuestionTitle(t *testing.T) {
+	assert.Equal(t, "什么是缓存雪崩", normalizeQuestionTitle(" 什么是 缓存雪崩？"))
+	assert.Equal(t, "redis为什么快", normalizeQuestionTitle("Ｒｅｄｉｓ 为什么快!!"))
+	assert.Equal(t, "", normalizeQuestionTitle("？？ ..."))
+	assert.Equal(t, questionTitleFingerprint(normalizeQuestionTitle("什么是缓存雪崩？")),
+		questionTitleFingerprint(normalizeQuestionTitle("什么是缓存雪崩")))
+
+	// 校验通过的题目写入题干指纹，用于按索引查找完全重复的题目
+	question := &model.ExamQuestion{QuestionType: consts.QuestionTypeShortAnswer, QuestionTitle: "什么是缓存雪崩？", CorrectAnswer: "答案"}
+	assert.NoError(t, validateQuestionContent(question))
+	assert.Equal(t, questionTitleFingerprint("什么是缓存雪崩"), question.TitleFingerprint)
+	assert.Len(t, question.TitleFingerprint, 32)
+}
+
+func TestJaccardSimilarity(t *testing.T) {
+	a := titleShingles(normalizeQuestionTitle("什么是Redis"))
+	b := titleShingles(normalizeQuestionTitle("什么是MySQL"))
+	assert.Less(t, jaccardSimilarity(a, b), consts.DuplicateSimilarityThreshold)
+	assert.Equal(t, 1.0, jaccardSimilarity(a, a))
+	assert.Equal(t, 0.0, jaccardSimilarity(a, titleShingles("")))
+	assert.Len(t, titleShingles("a"), 1)
+}
+
+func TestQuestionDeduper_Resolve(t *testing.T) {
+	newDeduper := func() *questionDeduper {
+		deduper := newQuestionDeduper(consts.DuplicateSimilarityThreshold)
+		deduper.add(&model.ExamQuestion{ID: 12, QuestionType: consts.QuestionTypeShortAnswer, QuestionTitle: "什么是缓存雪崩？如何避免缓存雪崩"}, 0)
+		return deduper
+	}
+	newQuestion := func(title string) *model.ExamQuestion {
+		return &model.ExamQuestion{QuestionType: consts.QuestionTypeShortAnswer, QuestionTitle: title,
+			AnswerAnalysis: "解析", Tag: "数据存储", SecondTag: "Redis"}
+	}
+
+	t.Run("不重复的题目入库", func(t *testing.T) {
+		duplicate, merge, keep := newDeduper().resolve(newQuestion("什么是缓存穿透"), 1, consts.DuplicatePolicySkip)
+		assert.Nil(t, duplicate)
+		assert.Nil(t, merge)
+		assert.True(t, keep)
+	})
+
+	t.Run("题型不同不算重复", func(t *testing.T) {
+		question := newQuestion("什么是缓存雪崩？如何避免缓存雪崩")
+		question.QuestionType = consts.QuestionTypeChoice
+		duplicate, _, keep := newDeduper().resolve(question, 1, consts.DuplicatePolicySkip)
+		assert.Nil(t, duplicate)
+		assert.True(t, keep)
+	})
+
+	t.Run("近似重复跳过", func(t *testing.T) {
+		duplicate, merge, keep := newDeduper().resolve(newQuestion("什么是缓存雪崩，如何避免缓存雪崩？"), 3, consts.DuplicatePolicySkip)
+		assert.False(t, keep)
+		assert.Nil(t, merge)
+		if assert.NotNil(t, duplicate) {
+			assert.Equal(t, uint(12), duplicate.DuplicateOf)
+			assert.Equal(t, 3, duplicate.Index)
+			assert.Equal(t, 1.0, duplicate.Similarity)
+			assert.Contains(t, duplicate.ActionDescription(), "已跳过")
+		}
+	})
+
+	t.Run("标记策略仍然入库", func(t *testing.T) {
+		question := newQuestion("什么是缓存雪崩？如何避免缓存雪崩呢")
+		duplicate, _, keep := newDeduper().resolve(question, 1, consts.DuplicatePolicyFlag)
+		assert.True(t, keep)
+		if assert.NotNil(t, duplicate) {
+			assert.Equal(t, 0.92, duplicate.Similarity)
+		}
+		assert.Equal(t, uint(12), question.DuplicateOf)
+	})
+
+	t.Run("合并到已有题目", func(t *testing.T) {
+		question := newQuestion("什么是缓存雪崩？如何避免缓存雪崩")
+		duplicate, merge, keep := newDeduper().resolve(question, 1, consts.DuplicatePolicyMerge)
+		assert.False(t, keep)
+		assert.NotNil(t, duplicate)
+		if assert.NotNil(t, merge) {
+			assert.Equal(t, uint(12), merge.targetID)
+			assert.Same(t, question, merge.source)
+		}
+	})
+
+	t.Run("同批次重复", func(t *testing.T) {
+		deduper := newDeduper()
+		first := newQuestion("Redis持久化有哪些方式")
+		first.AnswerAnalysis = ""
+		_, _, keep := deduper.resolve(first, 2, consts.DuplicatePolicyMerge)
+		assert.True(t, keep)
+
+		second := newQuestion("Redis 持久化有哪些方式？")
+		second.Tag, second.SecondTag = "数据存储", "AOF"
+		duplicate, merge, keep := deduper.resolve(second, 5, consts.DuplicatePolicyMerge)
+		assert.False(t, keep)
+		assert.Nil(t, merge)
+		if assert.NotNil(t, duplicate) {
+			assert.Equal(t, uint(0), duplicate.DuplicateOf)
+			assert.Equal(t, 2, duplicate.DuplicateIndex)
+		}
+		assert.Equal(t, "解析", first.AnswerAnalysis)
+		assert.Len(t, first.Tags, 2)
+
+		_, _, keep = deduper.resolve(newQuestion("Redis持久化有哪些方式"), 6, consts.DuplicatePolicyFlag)
+		assert.False(t, keep)
+	})
+}
+
+func TestFindDuplicateClusters(t *testing.T) {
+	questions := []model.ExamQuestion{
+		{ID: 1, QuestionType: consts.QuestionTypeShortAnswer, QuestionTitle: "什么是缓存雪崩"},
+		{ID: 2, QuestionType: consts.QuestionTypeShortAnswer, QuestionTitle: "Redis持久化有哪些方式"},
+		{ID: 3, QuestionType: consts.QuestionTypeShortAnswer, QuestionTitle: "什么是缓存雪崩？"},
+		{ID: 4, QuestionType: consts.QuestionTypeChoice, QuestionTitle: "什么是缓存雪崩"},
+		{ID: 5, QuestionType: consts.QuestionTypeShortAnswer, QuestionTitle: "讲讲缓存雪崩", DuplicateOf: 1},
+		{ID: 6, QuestionType: consts.QuestionTypeShortAnswer, QuestionTitle: "redis持久化有哪些方式?"},
+		{ID: 7, QuestionType: consts.QuestionTypeShortAnswer, QuestionTitle: "TCP三次握手的过程"},
+	}
+
+	clusters := findDuplicateClusters(questions, consts.DuplicateSimilarityThreshold)
+	if assert.Len(t, clusters, 2) {
+		var ids []uint
+		for _, question := range clusters[0].Questions {
+			ids = append(ids, question.ID)
+		}
+		assert.Equal(t, []uint{1, 3, 5}, ids)
+		assert.Equal(t, 1.0, clusters[0].MaxSimilarity)
+
+		assert.Equal(t, uint(2), clusters[1].Questions[0].ID)
+		assert.Equal(t, uint(6), clusters[1].Questions[1].ID)
+	}
+}
+
+func TestAIQuestionIndexes(t *testing.T) {
+	rejections := []*AIQuestionRejection{{Index: 2}, {Index: 4}}
+	assert.Equal(t, []int{1, 3, 5}, aiQuestionIndexes(3, rejections))
+	assert.Equal(t, []int{1, 2}, aiQuestionIndexes(2, nil))
+}
+
+func TestValidateDuplicatePolicy(t *testing.T) {
+	policy, err := validateDuplicatePolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, consts.DuplicatePolicySkip, policy)
+
+	policy, err = validateDuplicatePolicy(" MERGE ")
+	assert.NoError(t, err)
+	assert.Equal(t, consts.DuplicatePolicyMerge, policy)
+
+	_, err = validateDuplicatePolicy("replace")
+	assert.Error(t, err)
+}
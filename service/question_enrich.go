@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vaynedu/exam_system/config"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/model"
+	"github.com/vaynedu/exam_system/third_part"
+	"gorm.io/gorm"
+)
+
+// aiEnrichPromptTemplate AI补充解析提示词：题目列表JSON
+const aiEnrichPromptTemplate = `你是一名资深技术面试官，请为以下面试题补充答案解析、难度和考察要点。
+要求：
+1. analysis：结合正确答案讲清原理和答题思路，不超过300字；题目已有解析时在其基础上完善；
+2. difficulty：只能是"简单"、"中等"、"困难"之一；
+3. key_points：2-5个考察要点，每个不超过20字；
+4. 只输出一个JSON对象，不要输出其他内容，每道题都要给出结果，格式为：
+{"items": [{"id": 题目ID, "analysis": "答案解析", "difficulty": "中等", "key_points": ["考察要点"]}]}
+
+题目列表：
+%s`
+
+// EnrichQuestionsRequest AI补充解析请求参数（题目筛选条件与题目列表相同，由handler从查询参数解析）
+type EnrichQuestionsRequest struct {
+	Tag          string       `json:"-"`
+	SecondTag    string       `json:"-"`
+	Tags         []dao.TagRef `json:"-"`
+	MatchAll     bool         `json:"-"`
+	QuestionType string       `json:"-"`
+	Keyword      string       `json:"-"`
+	Limit        int          `json:"limit"`        // 本次最多处理的题目数量
+	OnlyMissing  *bool        `json:"only_missing"` // 只处理缺少答案解析或备注的题目，默认true
+	Provider     string       `json:"provider"`
+	Model        string       `json:"model"`
+}
+
+// EnrichFailure 未能生成建议的题目及原因
+type EnrichFailure struct {
+	QuestionID uint   `json:"question_id"`
+	Reason     string `json:"reason"`
+}
+
+// EnrichQuestionsResult AI补充解析结果
+type EnrichQuestionsResult struct {
+	Suggestions []*model.ExamQuestionSuggestion `json:"suggestions"`
+	Failed      []*EnrichFailure                `json:"failed"`
+}
+
+// aiEnrichItem 模型输出的单道题目建议
+type aiEnrichItem struct {
+	ID         uint     `json:"id"`
+	Analysis   string   `json:"analysis"`
+	Difficulty string   `json:"difficulty"`
+	KeyPoints  []string `json:"key_points"`
+}
+
+// EnrichQuestionsService 按筛选条件选出已通过审核的题目（默认只选缺少解析或备注、且没有待处理建议的题目），
+// 分批调用大模型生成答案解析、难度和考察要点，保存为待处理建议，由编辑采纳后才写入题目
+func EnrichQuestionsService(ctx context.Context, userID uint, req *EnrichQuestionsRequest) (*EnrichQuestionsResult, error) {
+	if req.Limit <= 0 {
+		req.Limit = consts.QuestionEnrichDefaultCount
+	}
+	if req.Limit > consts.QuestionEnrichMaxCount {
+		return nil, fmt.Errorf("单次最多补充%d道题", consts.QuestionEnrichMaxCount)
+	}
+	if err := validateLLMProvider(req.Provider); err != nil {
+		return nil, err
+	}
+	llm, _, err := resolveLLMProvider(req.Provider, req.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	query := questionFilterQuery(consts.QuestionStatusApproved, req.Tag, req.SecondTag, req.Tags, req.MatchAll, req.QuestionType, req.Keyword).
+		Where("exam_questions.id NOT IN (?)", dao.NewQuestionSuggestionDao(config.DB).PendingQuestionIDs())
+	if req.OnlyMissing == nil || *req.OnlyMissing {
+		query = query.Where("(answer_analysis = '' OR question_remark = '')")
+	}
+	var questions []*model.ExamQuestion
+	if err := query.Order("exam_questions.id ASC").Limit(req.Limit).Find(&questions).Error; err != nil {
+		return nil, err
+	}
+	result := &EnrichQuestionsResult{Suggestions: []*model.ExamQuestionSuggestion{}, Failed: []*EnrichFailure{}}
+	if len(questions) == 0 {
+		return result, nil
+	}
+
+	// 分批并发调用大模型
+	batches := slices.Collect(slices.Chunk(questions, consts.QuestionEnrichBatchSize))
+	suggestions := make([][]*model.ExamQuestionSuggestion, len(batches))
+	failures := make([][]*EnrichFailure, len(batches))
+	slots := make(chan struct{}, consts.QuestionEnrichConcurrency)
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			batchCtx, trace := withLLMCallTrace(ctx, consts.LLMCallSceneEnrich, req.Provider, userID)
+			suggestions[i], failures[i] = enrichQuestionBatch(batchCtx, llm, batch)
+			trace.linkQuestions(suggestionQuestionIDs(suggestions[i]))
+		}()
+	}
+	wg.Wait()
+
+	for i := range batches {
+		for _, suggestion := range suggestions[i] {
+			suggestion.CreatedBy = userID
+			result.Suggestions = append(result.Suggestions, suggestion)
+		}
+		result.Failed = append(result.Failed, failures[i]...)
+	}
+	if err := dao.NewQuestionSuggestionDao(config.DB).CreateSuggestions(result.Suggestions); err != nil {
+		return nil, fmt.Errorf("保存AI建议失败：%w", err)
+	}
+	return result, nil
+}
+
+// enrichQuestionBatch 调用大模型为一批题目生成建议，调用失败时整批记为失败
+func enrichQuestionBatch(ctx context.Context, llm third_part.LLMProvider, questions []*model.ExamQuestion) ([]*model.ExamQuestionSuggestion, []*EnrichFailure) {
+	var suggestions []*model.ExamQuestionSuggestion
+	var failures []*EnrichFailure
+	failAll := func(reason string) {
+		for _, question := range questions {
+			failures = append(failures, &EnrichFailure{QuestionID: question.ID, Reason: reason})
+		}
+	}
+
+	content, err := chatJSON(ctx, llm, buildEnrichPrompt(questions))
+	if err != nil {
+		failAll("调用AI接口失败：" + err.Error())
+		return nil, failures
+	}
+	items, err := parseEnrichReply(content)
+	if err != nil {
+		failAll("解析AI回复失败：" + err.Error())
+		return nil, failures
+	}
+
+	for _, question := range questions {
+		item, ok := items[question.ID]
+		if !ok {
+			failures = append(failures, &EnrichFailure{QuestionID: question.ID, Reason: "AI回复中缺少该题目"})
+			continue
+		}
+		if item.Analysis == "" && item.Difficulty == "" && len(item.KeyPoints) == 0 {
+			failures = append(failures, &EnrichFailure{QuestionID: question.ID, Reason: "AI未给出有效建议"})
+			continue
+		}
+		suggestions = append(suggestions, &model.ExamQuestionSuggestion{
+			QuestionID: question.ID,
+			Status:     consts.QuestionSuggestionStatusPending,
+			Analysis:   item.Analysis,
+			Difficulty: item.Difficulty,
+			KeyPoints:  item.KeyPoints,
+			Model:      llm.ModelName(),
+			Question:   question,
+		})
+	}
+	return suggestions, failures
+}
+
+// suggestionQuestionIDs 建议对应的题目ID列表
+func suggestionQuestionIDs(suggestions []*model.ExamQuestionSuggestion) []uint {
+	ids := make([]uint, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		ids = append(ids, suggestion.QuestionID)
+	}
+	return ids
+}
+
+// buildEnrichPrompt 构建AI补充解析的提示词，题目以JSON数组列出
+func buildEnrichPrompt(questions []*model.ExamQuestion) string {
+	type promptQuestion struct {
+		ID             uint     `json:"id"`
+		QuestionType   string   `json:"question_type"`
+		QuestionTitle  string   `json:"question_title"`
+		Options        []string `json:"options,omitempty"`
+		CorrectAnswer  string   `json:"correct_answer"`
+		AnswerAnalysis string   `json:"answer_analysis,omitempty"`
+	}
+	items := make([]promptQuestion, 0, len(questions))
+	for _, question := range questions {
+		items = append(items, promptQuestion{
+			ID:             question.ID,
+			QuestionType:   consts.GetQuestionTypeName(int(question.QuestionType)),
+			QuestionTitle:  question.QuestionTitle,
+			Options:        question.Options,
+			CorrectAnswer:  question.CorrectAnswer,
+			AnswerAnalysis: question.AnswerAnalysis,
+		})
+	}
+	data, _ := json.MarshalIndent(items, "", "  ")
+	return fmt.Sprintf(aiEnrichPromptTemplate, data)
+}
+
+// parseEnrichReply 解析模型回复（{"items":[...]}或直接为数组），按题目ID返回规范化后的建议：
+// 难度不在取值范围内时置空，考察要点去空并截取前QuestionKeyPointMaxCount个，解析按字段长度截断
+func parseEnrichReply(content string) (map[uint]*aiEnrichItem, error) {
+	payload, err := extractJSONPayload(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*aiEnrichItem
+	if strings.HasPrefix(payload, "[") {
+		err = json.Unmarshal([]byte(payload), &items)
+	} else {
+		var wrapper struct {
+			Items []*aiEnrichItem `json:"items"`
+		}
+		err = json.Unmarshal([]byte(payload), &wrapper)
+		items = wrapper.Items
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint]*aiEnrichItem, len(items))
+	for _, item := range items {
+		if item == nil || item.ID == 0 {
+			continue
+		}
+		item.Analysis = truncateRunes(strings.TrimSpace(item.Analysis), 2000)
+		item.Difficulty = strings.TrimSpace(item.Difficulty)
+		if !slices.Contains(consts.QuestionDifficulties, item.Difficulty) {
+			item.Difficulty = ""
+		}
+		item.KeyPoints = trimNonEmpty(item.KeyPoints)
+		if len(item.KeyPoints) > consts.QuestionKeyPointMaxCount {
+			item.KeyPoints = item.KeyPoints[:consts.QuestionKeyPointMaxCount]
+		}
+		result[item.ID] = item
+	}
+	return result, nil
+}
+
+// GetQuestionSuggestionsService 分页获取指定状态的AI建议（含题目）
+func GetQuestionSuggestionsService(status, page, size int) ([]*model.ExamQuestionSuggestion, int64, error) {
+	if !consts.CheckQuestionSuggestionStatus(status) {
+		return nil, 0, errors.New("建议状态无效")
+	}
+	return dao.NewQuestionSuggestionDao(config.DB).GetSuggestionsByStatus(status, page, size)
+}
+
+// AcceptQuestionSuggestionService 采纳AI建议：将选中的字段（默认全部）写入题目，答案解析写入answer_analysis，
+// 难度与考察要点写入question_remark；题目原有内容会被覆盖
+func AcceptQuestionSuggestionService(reviewerID, suggestionID uint, fields []string) (*model.ExamQuestionSuggestion, error) {
+	suggestion, err := getPendingQuestionSuggestion(suggestionID)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := suggestionColumns(suggestion, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		ok, err := dao.NewQuestionSuggestionDao(tx).ReviewSuggestion(suggestionID, consts.QuestionSuggestionStatusAccepted, reviewerID, time.Now())
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("该建议已处理")
+		}
+		return dao.NewQuestionDao(tx).UpdateQuestionColumns(suggestion.QuestionID, columns)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return suggestion, nil
+}
+
+// IgnoreQuestionSuggestionService 忽略AI建议，题目内容不变
+func IgnoreQuestionSuggestionService(reviewerID, suggestionID uint) error {
+	ok, err := dao.NewQuestionSuggestionDao(config.DB).ReviewSuggestion(suggestionID, consts.QuestionSuggestionStatusIgnored, reviewerID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("建议不存在或已处理")
+	}
+	return nil
+}
+
+// getPendingQuestionSuggestion 获取待处理的建议
+func getPendingQuestionSuggestion(id uint) (*model.ExamQuestionSuggestion, error) {
+	suggestion, err := dao.NewQuestionSuggestionDao(config.DB).GetSuggestionByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("建议不存在")
+		}
+		return nil, err
+	}
+	if suggestion.Status != consts.QuestionSuggestionStatusPending {
+		return nil, errors.New("该建议已处理")
+	}
+	if suggestion.Question == nil {
+		return nil, errors.New("题目不存在")
+	}
+	return suggestion, nil
+}
+
+// suggestionColumns 根据采纳的字段生成需要更新的题目列，fields为空表示采纳建议中的全部字段
+func suggestionColumns(suggestion *model.ExamQuestionSuggestion, fields []string) (map[string]interface{}, error) {
+	if len(fields) == 0 {
+		fields = []string{consts.QuestionSuggestionFieldAnalysis, consts.QuestionSuggestionFieldRemark}
+	}
+	columns := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		switch field {
+		case consts.QuestionSuggestionFieldAnalysis:
+			if suggestion.Analysis != "" {
+				columns["answer_analysis"] = suggestion.Analysis
+			}
+		case consts.QuestionSuggestionFieldRemark:
+			if remark := formatSuggestionRemark(suggestion.Difficulty, suggestion.KeyPoints); remark != "" {
+				columns["question_remark"] = remark
+			}
+		default:
+			return nil, fmt.Errorf("不支持采纳的字段：%s（可选analysis/remark）", field)
+		}
+	}
+	if len(columns) == 0 {
+		return nil, errors.New("建议中没有可采纳的内容")
+	}
+	return columns, nil
+}
+
+// formatSuggestionRemark 将难度与考察要点格式化为题目备注，如“难度：中等；考察要点：持久化、AOF重写”
+func formatSuggestionRemark(difficulty string, keyPoints []string) string {
+	var parts []string
+	if difficulty != "" {
+		parts = append(parts, "难度："+difficulty)
+	}
+	if len(keyPoints) > 0 {
+		parts = append(parts, "考察要点："+strings.Join(keyPoints, "、"))
+	}
+	return truncateRunes(strings.Join(parts, "；"), 500)
+}
+
+// truncateRunes 按字符数截断字符串
+func truncateRunes(s string, maxLen int) string {
+	if utf8.RuneCountInString(s) <= maxLen {
+		return s
+	}
+	return string([]rune(s)[:maxLen])
+}
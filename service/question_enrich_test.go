@@ -0,0 +1,75 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+)
+
+func TestParseEnrichReply(t *testing.T) {
+	content := "```json\n" + `{"items": [
+		{"id": 1, "analysis": " RDB是快照，AOF是追加日志 ", "difficulty": "中等", "key_points": ["RDB", " ", "AOF", "混合持久化", "a", "b", "c"]},
+		{"id": 2, "analysis": "", "difficulty": "超难", "key_points": []},
+		{"id": 0, "analysis": "无效ID"}
+	]}` + "\n```"
+	items, err := parseEnrichReply(content)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	assert.Equal(t, "RDB是快照，AOF是追加日志", items[1].Analysis)
+	assert.Equal(t, "中等", items[1].Difficulty)
+	assert.Equal(t, []string{"RDB", "AOF", "混合持久化", "a", "b"}, items[1].KeyPoints)
+	// 难度不在取值范围内时置空
+	assert.Equal(t, "", items[2].Difficulty)
+
+	// 直接返回数组也可以解析
+	items, err = parseEnrichReply(`[{"id": 3, "difficulty": "简单"}]`)
+	require.NoError(t, err)
+	assert.Equal(t, "简单", items[3].Difficulty)
+
+	_, err = parseEnrichReply("无法生成")
+	assert.Error(t, err)
+}
+
+func TestFormatSuggestionRemark(t *testing.T) {
+	assert.Equal(t, "难度：中等；考察要点：持久化、AOF重写", formatSuggestionRemark("中等", []string{"持久化", "AOF重写"}))
+	assert.Equal(t, "难度：困难", formatSuggestionRemark("困难", nil))
+	assert.Equal(t, "考察要点：锁", formatSuggestionRemark("", []string{"锁"}))
+	assert.Equal(t, "", formatSuggestionRemark("", nil))
+}
+
+func TestSuggestionColumns(t *testing.T) {
+	suggestion := &model.ExamQuestionSuggestion{
+		Analysis:   "解析",
+		Difficulty: "简单",
+		KeyPoints:  model.StringList{"索引"},
+	}
+	columns, err := suggestionColumns(suggestion, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"answer_analysis": "解析",
+		"question_remark": "难度：简单；考察要点：索引",
+	}, columns)
+
+	columns, err = suggestionColumns(suggestion, []string{consts.QuestionSuggestionFieldAnalysis})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"answer_analysis": "解析"}, columns)
+
+	_, err = suggestionColumns(suggestion, []string{"title"})
+	assert.Error(t, err)
+
+	_, err = suggestionColumns(&model.ExamQuestionSuggestion{Difficulty: "中等"}, []string{consts.QuestionSuggestionFieldAnalysis})
+	assert.Error(t, err)
+}
+
+func TestBuildEnrichPrompt(t *testing.T) {
+	prompt := buildEnrichPrompt([]*model.ExamQuestion{
+		{ID: 7, QuestionType: consts.QuestionTypeChoice, QuestionTitle: "Redis默认端口是？", CorrectAnswer: "A"},
+	})
+	assert.Contains(t, prompt, `"id": 7`)
+	assert.Contains(t, prompt, "Redis默认端口是？")
+	assert.Contains(t, prompt, `"items"`)
+}
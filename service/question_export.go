@@ -0,0 +1,325 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vaynedu/exam_system/config"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/model"
+	"github.com/xuri/excelize/v2"
+)
+
+// 流式导出的参数
+const (
+	questionExportBatchSize   = 1000  // 每批从数据库读取的题目数
+	questionExportSampleRows  = 200   // 按表头及每个工作表的前200行估算列宽
+	questionExportMinColWidth = 8     // 列宽下限
+	questionExportMaxColWidth = 60    // 列宽上限
+	questionExportSheetName   = "题目"  // 不按分类拆分时的工作表名
+	questionExportUntagged    = "未分类" // 按分类拆分时，没有一级分类的题目所在的工作表名
+)
+
+// ExportExcelQuestionRequest 导出题目请求参数结构体
+type ExportExcelQuestionRequest struct {
+	IDs          []uint `json:"ids"`          // 指定题目ID列表
+	ExportAll    bool   `json:"export_all"`   // 是否导出全部
+	Tag          string `json:"tag"`          // 一级分类
+	SecondTag    string `json:"second_tag"`   // 二级分类
+	QuestionType string `json:"type"`         // 题型
+	Keyword      string `json:"keyword"`      // 关键词搜索
+	SplitByTag   bool   `json:"split_by_tag"` // 每个一级分类导出为一个工作表
+
+	// 按ID导出时是否包含未通过审核的题目，由handler按审核权限设置，不从请求中解析
+	IncludeUnapproved bool `json:"-"`
+}
+
+// ExportExcelQuestions 按导出条件分批读取题目并通过StreamWriter写入Excel，大题库不会一次性加载到内存；
+// 表头与导入模板一致、题型写为名称，导出的文件可直接重新导入（填写了题目编号的题目会更新而不是重复新增）。
+// 返回生成的文件及导出的题目数，没有符合条件的题目时文件为nil
+func ExportExcelQuestions(req ExportExcelQuestionRequest) (*excelize.File, int, error) {
+	// 指定ID时只按ID导出（未通过审核的题目需有审核权限才导出），导出全部时忽略筛选条件
+	filter := dao.QuestionExportFilter{IDs: req.IDs, IncludeUnapproved: req.IncludeUnapproved}
+	if len(req.IDs) == 0 && !req.ExportAll {
+		filter = dao.QuestionExportFilter{
+			Tag: req.Tag, SecondTag: req.SecondTag, QuestionType: req.QuestionType, Keyword: req.Keyword,
+		}
+	}
+	questionDao := dao.NewQuestionDao(config.DB)
+
+	var tags []string
+	if req.SplitByTag {
+		var err error
+		tags, err = questionDao.GetExportTags(filter)
+		if err != nil {
+			return nil, 0, fmt.Errorf("获取导出题目的分类失败：%w", err)
+		}
+		if len(tags) == 0 {
+			return nil, 0, nil
+		}
+	}
+
+	file := excelize.NewFile()
+	exporter, err := newQuestionExporter(file, req.SplitByTag, tags)
+	if err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+	count := 0
+	err = questionDao.FindExportQuestionsInBatches(filter, questionExportBatchSize, func(questions []model.ExamQuestion) error {
+		for i := range questions {
+			if err := exporter.write(&questions[i]); err != nil {
+				return err
+			}
+		}
+		count += len(questions)
+		return nil
+	})
+	if err == nil && count > 0 {
+		err = exporter.flush()
+	}
+	if err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("导出题目失败：%w", err)
+	}
+	if count == 0 {
+		file.Close()
+		return nil, 0, nil
+	}
+	return file, count, nil
+}
+
+// questionExporter 将题目按工作表流式写入Excel
+type questionExporter struct {
+	file        *excelize.File
+	headers     []string
+	columns     *excelColumns
+	headerStyle int
+	splitByTag  bool
+	sheets      map[string]*questionExportSheet // 按一级分类（不拆分时只有一个）
+	order       []*questionExportSheet          // 按创建顺序
+	sheetNames  map[string]bool
+}
+
+// questionExportSheet 一个导出工作表：写入前先缓存前questionExportSampleRows行用于估算列宽
+type questionExportSheet struct {
+	name    string
+	writer  *excelize.StreamWriter
+	pending [][]interface{}
+	rows    int // 已写入的行数（含表头）
+}
+
+// newQuestionExporter 创建导出器，splitByTag为true时按tags的顺序预先创建各一级分类的工作表
+func newQuestionExporter(file *excelize.File, splitByTag bool, tags []string) (*questionExporter, error) {
+	headers := importTemplateHeaders()
+	columns, err := parseExcelHeader(headers)
+	if err != nil {
+		return nil, err
+	}
+	headerStyle, err := file.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"D9E1F2"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exporter := &questionExporter{
+		file:        file,
+		headers:     headers,
+		columns:     columns,
+		headerStyle: headerStyle,
+		splitByTag:  splitByTag,
+		sheets:      make(map[string]*questionExportSheet),
+		sheetNames:  make(map[string]bool),
+	}
+	if !splitByTag {
+		tags = []string{""}
+	}
+	for _, tag := range tags {
+		if _, err := exporter.sheet(tag); err != nil {
+			return nil, err
+		}
+	}
+	return exporter, nil
+}
+
+// sheet 获取一级分类对应的工作表，不存在时创建（第一个工作表使用新建文件的默认工作表）
+func (e *questionExporter) sheet(tag string) (*questionExportSheet, error) {
+	if !e.splitByTag {
+		tag = ""
+	}
+	if sheet, ok := e.sheets[tag]; ok {
+		return sheet, nil
+	}
+
+	name := questionExportSheetName
+	if e.splitByTag {
+		name = questionExportUntagged
+		if tag != "" {
+			name = sanitizeSheetName(tag)
+		}
+	}
+	name = uniqueSheetName(name, e.sheetNames)
+	if len(e.order) == 0 {
+		if err := e.file.SetSheetName(e.file.GetSheetName(0), name); err != nil {
+			return nil, err
+		}
+	} else if _, err := e.file.NewSheet(name); err != nil {
+		return nil, err
+	}
+
+	sheet := &questionExportSheet{name: name}
+	e.sheets[tag] = sheet
+	e.order = append(e.order, sheet)
+	e.sheetNames[strings.ToLower(name)] = true
+	return sheet, nil
+}
+
+// write 写入一道题目
+func (e *questionExporter) write(question *model.ExamQuestion) error {
+	sheet, err := e.sheet(question.Tag)
+	if err != nil {
+		return err
+	}
+	row := questionExportRow(question, e.columns, len(e.headers))
+	if sheet.writer == nil {
+		sheet.pending = append(sheet.pending, row)
+		if len(sheet.pending) < questionExportSampleRows {
+			return nil
+		}
+		return e.start(sheet)
+	}
+	if sheet.rows >= excelize.TotalRows {
+		return fmt.Errorf("工作表%s超过Excel最大行数", sheet.name)
+	}
+	sheet.rows++
+	cell, _ := excelize.CoordinatesToCellName(1, sheet.rows)
+	return sheet.writer.SetRow(cell, row)
+}
+
+// start 按缓存的行估算列宽，创建工作表的StreamWriter，写入冻结的表头及缓存的行
+func (e *questionExporter) start(sheet *questionExportSheet) error {
+	writer, err := e.file.NewStreamWriter(sheet.name)
+	if err != nil {
+		return err
+	}
+	// 列宽、冻结窗格需在写入行之前设置
+	for i, width := range questionExportColWidths(e.headers, sheet.pending) {
+		if err := writer.SetColWidth(i+1, i+1, width); err != nil {
+			return err
+		}
+	}
+	if err := writer.SetPanes(&excelize.Panes{
+		Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft",
+	}); err != nil {
+		return err
+	}
+
+	header := make([]interface{}, len(e.headers))
+	for i, value := range e.headers {
+		header[i] = value
+	}
+	if err := writer.SetRow("A1", header, excelize.RowOpts{StyleID: e.headerStyle}); err != nil {
+		return err
+	}
+	sheet.writer, sheet.rows = writer, 1
+	pending := sheet.pending
+	sheet.pending = nil
+	for _, row := range pending {
+		sheet.rows++
+		cell, _ := excelize.CoordinatesToCellName(1, sheet.rows)
+		if err := writer.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flush 写完全部工作表（没有题目的工作表只有表头）
+func (e *questionExporter) flush() error {
+	for _, sheet := range e.order {
+		if sheet.writer == nil {
+			if err := e.start(sheet); err != nil {
+				return err
+			}
+		}
+		if err := sheet.writer.Flush(); err != nil {
+			return err
+		}
+	}
+	e.file.SetActiveSheet(0)
+	return nil
+}
+
+// questionExportRow 按表头列映射生成题目的导出行：题型写为名称，选项按字母依次写入各选项列
+func questionExportRow(question *model.ExamQuestion, columns *excelColumns, width int) []interface{} {
+	row := make([]interface{}, width)
+	for i := range row {
+		row[i] = ""
+	}
+	row[columns.Type] = consts.GetQuestionTypeName(int(question.QuestionType))
+	row[columns.Title] = question.QuestionTitle
+	for i, col := range columns.Options {
+		row[col] = question.OptionAt(i)
+	}
+	row[columns.Answer] = question.CorrectAnswer
+	row[columns.Analysis] = question.AnswerAnalysis
+	row[columns.Remark] = question.QuestionRemark
+	row[columns.Tag] = question.Tag
+	row[columns.SecondTag] = question.SecondTag
+	row[columns.ExternalID] = question.ExternalID
+	return row
+}
+
+// questionExportColWidths 按表头及样本行中每列最宽的内容（多行内容取最长的一行，中文按2个字符宽度）估算列宽
+func questionExportColWidths(headers []string, rows [][]interface{}) []float64 {
+	widths := make([]float64, len(headers))
+	for i, header := range headers {
+		widths[i] = displayWidth(header)
+	}
+	for _, row := range rows {
+		for i, value := range row {
+			if i < len(widths) {
+				widths[i] = max(widths[i], displayWidth(fmt.Sprint(value)))
+			}
+		}
+	}
+	for i := range widths {
+		widths[i] = min(max(widths[i]+2, questionExportMinColWidth), questionExportMaxColWidth)
+	}
+	return widths
+}
+
+// displayWidth 文本在Excel中的显示宽度（多行文本取最长的一行），中日韩字符及全角符号（U+2E80起）按2个字符计算
+func displayWidth(text string) float64 {
+	widest := 0
+	for _, line := range strings.Split(text, "\n") {
+		width := 0
+		for _, r := range line {
+			if r >= 0x2E80 {
+				width += 2
+			} else {
+				width++
+			}
+		}
+		widest = max(widest, width)
+	}
+	return float64(widest)
+}
+
+// uniqueSheetName 工作表名重复（不区分大小写）时追加序号，如“算法(2)”，并保证不超过31个字符
+func uniqueSheetName(name string, used map[string]bool) string {
+	if !used[strings.ToLower(name)] {
+		return name
+	}
+	for i := 2; ; i++ {
+		suffix := fmt.Sprintf("(%d)", i)
+		candidate := truncateRunes(name, excelize.MaxSheetNameLength-utf8.RuneCountInString(suffix)) + suffix
+		if !used[strings.ToLower(candidate)] {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaynedu/exam_system/model"
+	"github.com/xuri/excelize/v2"
+)
+
+// exportTestQuestions 按导入模板的示例生成各题型题目（已通过校验规范化）
+func exportTestQuestions(t *testing.T) []*model.ExamQuestion {
+	headers := importTemplateHeaders()
+	columns, err := parseExcelHeader(headers)
+	require.NoError(t, err)
+	var questions []*model.ExamQuestion
+	for _, row := range importTemplateExampleRows(headers, columns, testKnowledgeTree) {
+		question, err := parseAndValidateRow(row, columns, "")
+		require.NoError(t, err)
+		questions = append(questions, question)
+	}
+	return questions
+}
+
+func TestQuestionExporter_RoundTrip(t *testing.T) {
+	setTestKnowledgeTree(t, testKnowledgeTree)
+	questions := exportTestQuestions(t)
+	questions[1].Tag, questions[1].SecondTag = "系统设计", "分布式锁"
+	questions[2].Tag, questions[2].SecondTag, questions[2].ExternalID = "", "", ""
+
+	file := excelize.NewFile()
+	defer file.Close()
+	exporter, err := newQuestionExporter(file, true, []string{"", "数据存储", "系统设计"})
+	require.NoError(t, err)
+	for _, question := range questions {
+		require.NoError(t, exporter.write(question))
+	}
+	require.NoError(t, exporter.flush())
+	assert.Equal(t, []string{"未分类", "数据存储", "系统设计"}, file.GetSheetList())
+
+	var buf bytes.Buffer
+	require.NoError(t, file.Write(&buf))
+	exported, err := excelize.OpenReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	defer exported.Close()
+
+	// 题型写为名称，表头冻结
+	typeName, _ := exported.GetCellValue("系统设计", "A2")
+	assert.Equal(t, "填空题", typeName)
+	panes, err := exported.GetPanes("数据存储")
+	require.NoError(t, err)
+	assert.True(t, panes.Freeze)
+	assert.Equal(t, 1, panes.YSplit)
+
+	// 重新导入后内容与导出前一致
+	workbook, sheets, err := openImportWorkbook(bytes.NewReader(buf.Bytes()), "export.xlsx")
+	require.NoError(t, err)
+	defer workbook.Close()
+	rows := parseImportSheets(sheets, false)
+	require.Len(t, rows, len(questions))
+	imported := make(map[string]*model.ExamQuestion)
+	for _, row := range rows {
+		require.Empty(t, row.Error, "工作表%s第%d行", row.Sheet, row.Row)
+		imported[row.Question.QuestionTitle] = row.Question
+	}
+	for _, question := range questions {
+		got, ok := imported[question.QuestionTitle]
+		require.True(t, ok, question.QuestionTitle)
+		assert.False(t, questionContentChanged(question, got), question.QuestionTitle)
+		assert.Equal(t, question.ExternalID, got.ExternalID)
+	}
+}
+
+func TestQuestionExporter_SingleSheet(t *testing.T) {
+	setTestKnowledgeTree(t, testKnowledgeTree)
+	question := exportTestQuestions(t)[0]
+
+	file := excelize.NewFile()
+	defer file.Close()
+	exporter, err := newQuestionExporter(file, false, nil)
+	require.NoError(t, err)
+	for i := 0; i < questionExportSampleRows+5; i++ {
+		require.NoError(t, exporter.write(question))
+	}
+	require.NoError(t, exporter.flush())
+
+	assert.Equal(t, []string{questionExportSheetName}, file.GetSheetList())
+	rows, err := file.GetRows(questionExportSheetName)
+	require.NoError(t, err)
+	assert.Len(t, rows, questionExportSampleRows+6)
+	assert.Equal(t, "选择题", rows[len(rows)-1][0])
+
+	width, err := file.GetColWidth(questionExportSheetName, "B")
+	require.NoError(t, err)
+	assert.Equal(t, displayWidth(question.QuestionTitle)+2, width)
+}
+
+func TestQuestionExportColWidths(t *testing.T) {
+	widths := questionExportColWidths([]string{"题型*", "题干*", "A"}, [][]interface{}{
+		{"选择题", "Redis默认端口是？\n第二行", ""},
+		{"判断题", strings.Repeat("长", 100), ""},
+	})
+	assert.Equal(t, []float64{8, questionExportMaxColWidth, questionExportMinColWidth}, widths)
+	assert.Equal(t, float64(9), displayWidth("Redis端口"))
+	assert.Equal(t, float64(4), displayWidth("ab\n（）"))
+}
+
+func TestUniqueSheetName(t *testing.T) {
+	used := map[string]bool{"算法": true, "redis": true, "算法(2)": true}
+	assert.Equal(t, "网络", uniqueSheetName("网络", used))
+	assert.Equal(t, "算法(3)", uniqueSheetName("算法", used))
+	assert.Equal(t, "Redis(2)", uniqueSheetName("Redis", used))
+
+	long := strings.Repeat("a", 31)
+	used[long] = true
+	assert.Equal(t, strings.Repeat("a", 28)+"(2)", uniqueSheetName(long, used))
+}
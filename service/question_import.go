@@ -0,0 +1,547 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/vaynedu/exam_system/config"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/model"
+	"github.com/xuri/excelize/v2"
+)
+
+// excelImportErrorHeader 导入校验报告中追加的错误原因列表头
+const excelImportErrorHeader = "错误原因"
+
+// ExcelImportOptions 导入选项
+type ExcelImportOptions struct {
+	Filename        string // 上传的文件名，按扩展名区分.xlsx/.csv；.csv文件以去掉扩展名的文件名作为工作表名
+	DuplicatePolicy string // 重复题目的处理策略：skip（默认）/flag/merge
+	SheetAsTag      bool   // 一级分类列为空时使用工作表名作为一级分类
+	AllOrNothing    bool   // 存在未通过校验的行时整体取消导入，不写入任何题目
+}
+
+// ExcelImportRow 导入文件中一行题目的解析结果：通过校验时为解析出的题目，否则为错误原因
+type ExcelImportRow struct {
+	Sheet    string              `json:"sheet"` // 工作表名
+	Row      int                 `json:"row"`   // 行号（表头为第1行）
+	Question *model.ExamQuestion `json:"question,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// ExcelImportResult Excel导入结果
+type ExcelImportResult struct {
+	InsertedCount  int                  `json:"inserted_count"`  // 新增的题目数
+	UpdatedCount   int                  `json:"updated_count"`   // 按题目编号更新的已有题目数
+	UnchangedCount int                  `json:"unchanged_count"` // 题目编号已存在且内容未变化的题目数
+	FailCount      int                  `json:"fail_count"`
+	Aborted        bool                 `json:"aborted"`     // 全部成功才导入时，因存在无效行而取消了导入
+	FailedRows     []*ExcelImportRow    `json:"failed_rows"` // 未通过校验的行及原因
+	Duplicates     []*QuestionDuplicate `json:"duplicates"`  // 被跳过、标记或合并的重复题目
+}
+
+// ExcelImportPreview Excel导入预览（只解析校验，不入库）
+type ExcelImportPreview struct {
+	TotalCount   int               `json:"total_count"`
+	ValidCount   int               `json:"valid_count"`
+	InvalidCount int               `json:"invalid_count"`
+	Rows         []*ExcelImportRow `json:"rows"`
+}
+
+// PreviewExcelQuestions 解析并校验导入文件中全部工作表的题目（不入库），返回每一行解析出的题目或错误原因
+func PreviewExcelQuestions(fileReader io.Reader, opts *ExcelImportOptions) (*ExcelImportPreview, error) {
+	excelFile, sheets, err := openImportWorkbook(fileReader, opts.Filename)
+	if err != nil {
+		return nil, err
+	}
+	defer excelFile.Close()
+
+	preview := &ExcelImportPreview{Rows: parseImportSheets(sheets, opts.SheetAsTag)}
+	for _, row := range preview.Rows {
+		if row.Error != "" {
+			preview.InvalidCount++
+		} else {
+			preview.ValidCount++
+		}
+	}
+	preview.TotalCount = len(preview.Rows)
+	return preview, nil
+}
+
+// BuildExcelImportReport 解析并校验导入文件中的题目，返回文件的.xlsx副本：每个题目工作表末尾追加“错误原因”列，
+// 未通过校验的行标红并写入错误原因，便于修改后重新导入
+func BuildExcelImportReport(fileReader io.Reader, opts *ExcelImportOptions) (*excelize.File, error) {
+	excelFile, sheets, err := openImportWorkbook(fileReader, opts.Filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := annotateExcelImportErrors(excelFile, sheets, parseImportSheets(sheets, opts.SheetAsTag)); err != nil {
+		excelFile.Close()
+		return nil, fmt.Errorf("生成校验报告失败：%w", err)
+	}
+	return excelFile, nil
+}
+
+// annotateExcelImportErrors 在各工作表已有的最后一列之后追加错误原因列，并将未通过校验的行标红
+func annotateExcelImportErrors(excelFile *excelize.File, sheets []*importSheet, rows []*ExcelImportRow) error {
+	errorStyle, err := excelFile.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFC7CE"}},
+		Font: &excelize.Font{Color: "9C0006"},
+	})
+	if err != nil {
+		return err
+	}
+
+	errorColumns := make(map[string]int, len(sheets))
+	for _, sheet := range sheets {
+		lastColumn := 0
+		for _, row := range sheet.Rows {
+			lastColumn = max(lastColumn, len(row))
+		}
+		errorColumn := lastColumn + 1
+		errorColumnName, err := excelize.ColumnNumberToName(errorColumn)
+		if err != nil {
+			return err
+		}
+		headerCell, _ := excelize.CoordinatesToCellName(errorColumn, 1)
+		if err := excelFile.SetCellValue(sheet.Name, headerCell, excelImportErrorHeader); err != nil {
+			return err
+		}
+		if err := excelFile.SetColWidth(sheet.Name, errorColumnName, errorColumnName, 40); err != nil {
+			return err
+		}
+		errorColumns[sheet.Name] = errorColumn
+	}
+
+	for _, row := range rows {
+		if row.Error == "" {
+			continue
+		}
+		firstCell, _ := excelize.CoordinatesToCellName(1, row.Row)
+		errorCell, _ := excelize.CoordinatesToCellName(errorColumns[row.Sheet], row.Row)
+		if err := excelFile.SetCellValue(row.Sheet, errorCell, row.Error); err != nil {
+			return err
+		}
+		if err := excelFile.SetCellStyle(row.Sheet, firstCell, errorCell, errorStyle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importSheet 导入文件中的题目工作表
+type importSheet struct {
+	Name    string
+	Rows    [][]string // 含表头
+	Columns *excelColumns
+}
+
+// openImportWorkbook 打开导入文件（.csv转换为只有一个工作表的工作簿），返回表头可识别的工作表；
+// 隐藏的工作表及表头中没有任何可识别列的工作表（如填写说明）会被忽略，缺少必填列时返回错误
+func openImportWorkbook(fileReader io.Reader, filename string) (*excelize.File, []*importSheet, error) {
+	var excelFile *excelize.File
+	var err error
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		excelFile, err = csvToWorkbook(fileReader, strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename)))
+	} else {
+		excelFile, err = excelize.OpenReader(fileReader)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("解析Excel失败：%w", err)
+	}
+
+	sheets, err := readImportSheets(excelFile)
+	if err != nil {
+		excelFile.Close()
+		return nil, nil, err
+	}
+	return excelFile, sheets, nil
+}
+
+// readImportSheets 读取工作簿中全部题目工作表（跳过隐藏的工作表，如导入模板中的分类数据）
+func readImportSheets(excelFile *excelize.File) ([]*importSheet, error) {
+	var sheets []*importSheet
+	dataRows := 0
+	for _, name := range excelFile.GetSheetList() {
+		if visible, err := excelFile.GetSheetVisible(name); err == nil && !visible {
+			continue
+		}
+		rows, err := excelFile.GetRows(name)
+		if err != nil {
+			return nil, fmt.Errorf("读取工作表%s失败：%w", name, err)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		columns, err := parseExcelHeader(rows[0])
+		if err != nil {
+			return nil, fmt.Errorf("工作表%s%s", name, err.Error())
+		}
+		if columns == nil {
+			continue
+		}
+		sheets = append(sheets, &importSheet{Name: name, Rows: rows, Columns: columns})
+		for _, row := range rows[1:] {
+			if !isBlankExcelRow(row) {
+				dataRows++
+			}
+		}
+	}
+	if dataRows == 0 {
+		return nil, errors.New("excel无有效数据（需包含表头+至少1行题目）")
+	}
+	return sheets, nil
+}
+
+// csvToWorkbook 读取CSV（UTF-8，可带BOM）并写入只有一个工作表的工作簿，工作表以sheetName命名
+func csvToWorkbook(fileReader io.Reader, sheetName string) (*excelize.File, error) {
+	data, err := io.ReadAll(fileReader)
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	excelFile := excelize.NewFile()
+	name := sanitizeSheetName(sheetName)
+	if err := excelFile.SetSheetName(excelFile.GetSheetName(0), name); err != nil {
+		excelFile.Close()
+		return nil, err
+	}
+	for i, record := range records {
+		values := make([]interface{}, len(record))
+		for j, value := range record {
+			values[j] = value
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := excelFile.SetSheetRow(name, cell, &values); err != nil {
+			excelFile.Close()
+			return nil, err
+		}
+	}
+	return excelFile, nil
+}
+
+// sanitizeSheetName 去掉工作表名中不允许的字符并截断到31个字符，为空时使用Sheet1
+func sanitizeSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+	name = truncateRunes(strings.Trim(name, "'"), excelize.MaxSheetNameLength)
+	if name == "" {
+		return "Sheet1"
+	}
+	return name
+}
+
+// parseImportSheets 逐个工作表逐行解析校验题目（跳过表头与空行），返回每一行的解析结果；
+// sheetAsTag为true时，一级分类列为空的行使用工作表名作为一级分类
+func parseImportSheets(sheets []*importSheet, sheetAsTag bool) []*ExcelImportRow {
+	var result []*ExcelImportRow
+	for _, sheet := range sheets {
+		defaultTag := ""
+		if sheetAsTag {
+			defaultTag = strings.TrimSpace(sheet.Name)
+		}
+		for i := 1; i < len(sheet.Rows); i++ {
+			if isBlankExcelRow(sheet.Rows[i]) {
+				continue
+			}
+			row := &ExcelImportRow{Sheet: sheet.Name, Row: getExcelRowNum(i)}
+			question, err := parseAndValidateRow(sheet.Rows[i], sheet.Columns, defaultTag)
+			if err != nil {
+				row.Error = err.Error()
+			} else {
+				row.Question = question
+			}
+			result = append(result, row)
+		}
+	}
+	markDuplicateExternalIDs(result)
+	return result
+}
+
+// markDuplicateExternalIDs 同一文件中题目编号重复时，保留第一行，之后的行视为未通过校验
+func markDuplicateExternalIDs(rows []*ExcelImportRow) {
+	seen := make(map[string]*ExcelImportRow)
+	for _, row := range rows {
+		if row.Question == nil || row.Question.ExternalID == "" {
+			continue
+		}
+		if first, ok := seen[row.Question.ExternalID]; ok {
+			row.Error = fmt.Sprintf("题目编号「%s」与工作表「%s」第%d行重复", row.Question.ExternalID, first.Sheet, first.Row)
+			row.Question = nil
+			continue
+		}
+		seen[row.Question.ExternalID] = row
+	}
+}
+
+// importQuestionUpdate 按题目编号匹配到的已有题目及导入文件中的新内容
+type importQuestionUpdate struct {
+	existing *model.ExamQuestion
+	question *model.ExamQuestion
+}
+
+// getQuestionsByExternalID 按题目编号查询已有题目，同一编号对应多道题目时使用ID最小的一道
+func getQuestionsByExternalID(rows []*ExcelImportRow) (map[string]*model.ExamQuestion, error) {
+	var externalIDs []string
+	for _, row := range rows {
+		if row.Question.ExternalID != "" {
+			externalIDs = append(externalIDs, row.Question.ExternalID)
+		}
+	}
+	questions, err := dao.NewQuestionDao(config.DB).GetQuestionsByExternalIDs(externalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("按题目编号查询已有题目失败：%w", err)
+	}
+	existing := make(map[string]*model.ExamQuestion, len(questions))
+	for i := range questions {
+		if _, ok := existing[questions[i].ExternalID]; !ok {
+			existing[questions[i].ExternalID] = &questions[i]
+		}
+	}
+	return existing, nil
+}
+
+// splitImportRowsByExternalID 将通过校验的行分为：题目编号已存在且内容有变化（需更新）、
+// 没有编号或编号不存在（需新增）两类，返回编号已存在但内容未变化的行数
+func splitImportRowsByExternalID(rows []*ExcelImportRow, existing map[string]*model.ExamQuestion) ([]*importQuestionUpdate, []*ExcelImportRow, int) {
+	var updates []*importQuestionUpdate
+	var newRows []*ExcelImportRow
+	unchanged := 0
+	for _, row := range rows {
+		current, ok := existing[row.Question.ExternalID]
+		if row.Question.ExternalID == "" || !ok {
+			newRows = append(newRows, row)
+			continue
+		}
+		if questionContentChanged(current, row.Question) {
+			updates = append(updates, &importQuestionUpdate{existing: current, question: row.Question})
+		} else {
+			unchanged++
+		}
+	}
+	return updates, newRows, unchanged
+}
+
+// questionContentChanged 比较导入文件中可填写的字段（题型、题干、选项、答案、解析、备注、主分类）是否有变化
+func questionContentChanged(existing, question *model.ExamQuestion) bool {
+	return existing.QuestionType != question.QuestionType ||
+		existing.QuestionTitle != question.QuestionTitle ||
+		!slices.Equal(existing.Options, question.Options) ||
+		existing.CorrectAnswer != question.CorrectAnswer ||
+		existing.AnswerAnalysis != question.AnswerAnalysis ||
+		existing.QuestionRemark != question.QuestionRemark ||
+		existing.Tag != question.Tag ||
+		existing.SecondTag != question.SecondTag
+}
+
+// updateImportedQuestion 用导入文件中的内容更新已有题目：题型或答案未变化时保留原有的填空判分规则（如正则），
+// 主分类变化时替换主分类并保留题目的其他分类；审核状态与来源信息不变
+func updateImportedQuestion(questionDao *dao.QuestionDao, existing, question *model.ExamQuestion) error {
+	columns := map[string]interface{}{
+		"question_type":     question.QuestionType,
+		"question_title":    question.QuestionTitle,
+		"title_fingerprint": question.TitleFingerprint,
+		"options":           question.Options,
+		"correct_answer":    question.CorrectAnswer,
+		"answer_analysis":   question.AnswerAnalysis,
+		"question_remark":   question.QuestionRemark,
+		"tag":               question.Tag,
+		"second_tag":        question.SecondTag,
+	}
+	if existing.QuestionType != question.QuestionType || existing.CorrectAnswer != question.CorrectAnswer {
+		columns["blanks"] = question.Blanks
+	}
+	if err := questionDao.UpdateQuestionColumns(existing.ID, columns); err != nil {
+		return err
+	}
+	if existing.Tag == question.Tag && existing.SecondTag == question.SecondTag {
+		return nil
+	}
+
+	tags, err := questionDao.GetQuestionTags(existing.ID)
+	if err != nil {
+		return err
+	}
+	var newTags []*model.ExamQuestionTag
+	if question.Tag != "" {
+		newTags = append(newTags, &model.ExamQuestionTag{Tag: question.Tag, SecondTag: question.SecondTag})
+	}
+	for _, tag := range tags {
+		if tag.Tag != existing.Tag || tag.SecondTag != existing.SecondTag {
+			newTags = append(newTags, tag)
+		}
+	}
+	return questionDao.ReplaceQuestionTags(existing.ID, unionQuestionTags(newTags))
+}
+
+// isBlankExcelRow 判断是否为空行（所有单元格都为空白）
+func isBlankExcelRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// excelColumns 导入文件中各字段所在的列（从0开始），-1表示没有该列
+type excelColumns struct {
+	Type       int
+	Title      int
+	Options    [consts.QuestionOptionMaxCount]int // 选项A-H
+	Answer     int
+	Analysis   int
+	Remark     int
+	Tag        int
+	SecondTag  int
+	ExternalID int
+}
+
+// 导入文件表头对应的字段
+const (
+	excelFieldType      = "type"
+	excelFieldTitle     = "title"
+	excelFieldAnswer    = "answer"
+	excelFieldAnalysis  = "analysis"
+	excelFieldRemark    = "remark"
+	excelFieldTag       = "tag"
+	excelFieldSecondTag = "second_tag"
+	excelFieldExternal  = "external_id"
+	excelFieldOption    = "option_" // 加选项字母，如option_a
+)
+
+// excelHeaderAliases 表头别名（按normalizeExcelHeader规范化后匹配）对应的字段，选项列的别名在init中生成
+var excelHeaderAliases = map[string]string{
+	"题型":             excelFieldType,
+	"题目类型":           excelFieldType,
+	"type":           excelFieldType,
+	"questiontype":   excelFieldType,
+	"题干":             excelFieldTitle,
+	"题目":             excelFieldTitle,
+	"题目内容":           excelFieldTitle,
+	"title":          excelFieldTitle,
+	"question":       excelFieldTitle,
+	"questiontitle":  excelFieldTitle,
+	"正确答案":           excelFieldAnswer,
+	"答案":             excelFieldAnswer,
+	"参考答案":           excelFieldAnswer,
+	"answer":         excelFieldAnswer,
+	"correctanswer":  excelFieldAnswer,
+	"解析":             excelFieldAnalysis,
+	"答案解析":           excelFieldAnalysis,
+	"analysis":       excelFieldAnalysis,
+	"answeranalysis": excelFieldAnalysis,
+	"备注":             excelFieldRemark,
+	"题目备注":           excelFieldRemark,
+	"remark":         excelFieldRemark,
+	"questionremark": excelFieldRemark,
+	"一级分类":           excelFieldTag,
+	"分类":             excelFieldTag,
+	"tag":            excelFieldTag,
+	"primarytag":     excelFieldTag,
+	"二级分类":           excelFieldSecondTag,
+	"secondtag":      excelFieldSecondTag,
+	"secondarytag":   excelFieldSecondTag,
+	"subtag":         excelFieldSecondTag,
+	"题目编号":           excelFieldExternal,
+	"编号":             excelFieldExternal,
+	"外部id":           excelFieldExternal,
+	"externalid":     excelFieldExternal,
+	"questioncode":   excelFieldExternal,
+	"code":           excelFieldExternal,
+}
+
+func init() {
+	// 选项列：选项A、A、option_a、optionA
+	for i := 0; i < consts.QuestionOptionMaxCount; i++ {
+		letter := model.OptionLetter(i)
+		field := excelFieldOption + strings.ToLower(letter)
+		for _, alias := range []string{"选项" + letter, letter, "option" + letter} {
+			excelHeaderAliases[normalizeExcelHeader(alias)] = field
+		}
+	}
+}
+
+// normalizeExcelHeader 规范化表头：去掉括号内的说明及空白、下划线、连字符、星号（必填标记），英文转小写
+func normalizeExcelHeader(header string) string {
+	header = strings.ToLower(strings.TrimSpace(header))
+	if idx := strings.IndexAny(header, "(（"); idx > 0 {
+		header = header[:idx]
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '_', '-', '*':
+			return -1
+		}
+		return r
+	}, header)
+}
+
+// parseExcelHeader 按表头定位各字段所在的列（列顺序任意，同一字段出现多次时使用第一列）；
+// 表头中没有任何可识别的列时返回nil（不是题目工作表），缺少题型、题干或正确答案列时返回错误
+func parseExcelHeader(header []string) (*excelColumns, error) {
+	fields := make(map[string]int)
+	for i, cell := range header {
+		field, ok := excelHeaderAliases[normalizeExcelHeader(cell)]
+		if !ok {
+			continue
+		}
+		if _, exists := fields[field]; !exists {
+			fields[field] = i
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	var missing []string
+	for _, required := range []struct{ field, name string }{
+		{excelFieldType, "题型"}, {excelFieldTitle, "题干"}, {excelFieldAnswer, "正确答案"},
+	} {
+		if _, ok := fields[required.field]; !ok {
+			missing = append(missing, required.name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("缺少必填列：%s", strings.Join(missing, "、"))
+	}
+
+	column := func(field string) int {
+		if idx, ok := fields[field]; ok {
+			return idx
+		}
+		return -1
+	}
+	columns := &excelColumns{
+		Type:       column(excelFieldType),
+		Title:      column(excelFieldTitle),
+		Answer:     column(excelFieldAnswer),
+		Analysis:   column(excelFieldAnalysis),
+		Remark:     column(excelFieldRemark),
+		Tag:        column(excelFieldTag),
+		SecondTag:  column(excelFieldSecondTag),
+		ExternalID: column(excelFieldExternal),
+	}
+	for i := range columns.Options {
+		columns.Options[i] = column(excelFieldOption + strings.ToLower(model.OptionLetter(i)))
+	}
+	return columns, nil
+}
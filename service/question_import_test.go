@@ -0,0 +1,235 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+	"github.com/xuri/excelize/v2"
+)
+
+// testExcelColumns 按固定列顺序解析单行的测试用列：题型、题干、选项A-D、正确答案、答案解析、题目备注、一级分类、二级分类、选项E-H
+var testExcelColumns = excelColumns{
+	Type: 0, Title: 1, Options: [consts.QuestionOptionMaxCount]int{2, 3, 4, 5, 11, 12, 13, 14},
+	Answer: 6, Analysis: 7, Remark: 8, Tag: 9, SecondTag: 10, ExternalID: -1,
+}
+
+var testImportRows = [][]string{
+	{"题型", "题干", "选项A", "选项B", "选项C", "选项D", "正确答案", "解析", "备注", "一级分类", "二级分类"},
+	{"0", "Redis默认端口是？", "6379", "3306", "", "", "A"},
+	{"9", "题型错误的题目"},
+	{"", " ", ""},
+	{"4", "", "", "", "", "", "对"},
+}
+
+// newTestWorkbook 按工作表名与行数据生成.xlsx文件内容
+func newTestWorkbook(t *testing.T, sheets map[string][][]string, order ...string) *bytes.Buffer {
+	file := excelize.NewFile()
+	defer file.Close()
+	for i, name := range order {
+		if i == 0 {
+			require.NoError(t, file.SetSheetName(file.GetSheetName(0), name))
+		} else {
+			_, err := file.NewSheet(name)
+			require.NoError(t, err)
+		}
+		for j, row := range sheets[name] {
+			values := make([]interface{}, len(row))
+			for k, value := range row {
+				values[k] = value
+			}
+			cell, _ := excelize.CoordinatesToCellName(1, j+1)
+			require.NoError(t, file.SetSheetRow(name, cell, &values))
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, file.Write(&buf))
+	return &buf
+}
+
+func TestParseImportSheets(t *testing.T) {
+	columns, err := parseExcelHeader(testImportRows[0])
+	require.NoError(t, err)
+	rows := parseImportSheets([]*importSheet{{Name: "题目", Rows: testImportRows, Columns: columns}}, false)
+	require.Len(t, rows, 3) // 空行被跳过
+
+	assert.Equal(t, "题目", rows[0].Sheet)
+	assert.Equal(t, 2, rows[0].Row)
+	assert.Empty(t, rows[0].Error)
+	require.NotNil(t, rows[0].Question)
+	assert.Equal(t, "Redis默认端口是？", rows[0].Question.QuestionTitle)
+
+	assert.Equal(t, 3, rows[1].Row)
+	assert.Nil(t, rows[1].Question)
+	assert.Contains(t, rows[1].Error, "题型无效")
+
+	assert.Equal(t, 5, rows[2].Row)
+	assert.NotEmpty(t, rows[2].Error)
+}
+
+func TestParseExcelHeader(t *testing.T) {
+	// 列顺序任意，支持中英文别名，缺少的可选列为-1
+	columns, err := parseExcelHeader([]string{"Answer", "Question Title", "option_b", "选项A", "Type", "备注（可选）"})
+	require.NoError(t, err)
+	assert.Equal(t, 4, columns.Type)
+	assert.Equal(t, 1, columns.Title)
+	assert.Equal(t, 0, columns.Answer)
+	assert.Equal(t, 3, columns.Options[0])
+	assert.Equal(t, 2, columns.Options[1])
+	assert.Equal(t, -1, columns.Options[2])
+	assert.Equal(t, 5, columns.Remark)
+	assert.Equal(t, -1, columns.Tag)
+
+	// 没有可识别的列：不是题目工作表
+	columns, err = parseExcelHeader([]string{"填写说明"})
+	assert.NoError(t, err)
+	assert.Nil(t, columns)
+
+	_, err = parseExcelHeader([]string{"题干", "解析"})
+	assert.ErrorContains(t, err, "题型、正确答案")
+
+	// 缺少列的行按空值处理，不会越界
+	columns, err = parseExcelHeader([]string{"题型", "题干", "正确答案", "选项A", "选项B", "一级分类", "二级分类"})
+	require.NoError(t, err)
+	question, err := parseAndValidateRow([]string{"0", "题干", "B", "A1", "B1"}, columns, "")
+	require.NoError(t, err)
+	assert.Equal(t, "B", question.CorrectAnswer)
+	assert.Empty(t, question.Tag)
+}
+
+func TestOpenImportWorkbook(t *testing.T) {
+	questions := [][]string{
+		{"题干", "题型", "正确答案"},
+		{"TCP是面向连接的协议", "4", "对"},
+	}
+	buf := newTestWorkbook(t, map[string][][]string{
+		"填写说明": {{"说明"}, {"请按表头填写"}},
+		"网络":   questions,
+		"空表":   {{"题型", "题干", "正确答案"}},
+	}, "填写说明", "网络", "空表")
+
+	file, sheets, err := openImportWorkbook(buf, "questions.xlsx")
+	require.NoError(t, err)
+	defer file.Close()
+	require.Len(t, sheets, 2)
+	assert.Equal(t, "网络", sheets[0].Name)
+
+	rows := parseImportSheets(sheets, false)
+	require.Len(t, rows, 1)
+	assert.Empty(t, rows[0].Error)
+
+	// 工作表名作为一级分类时，未配置该分类会校验失败
+	rows = parseImportSheets(sheets, true)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "一级分类标签无效", rows[0].Error)
+
+	buf = newTestWorkbook(t, map[string][][]string{"说明": {{"说明"}, {"内容"}}}, "说明")
+	_, _, err = openImportWorkbook(buf, "questions.xlsx")
+	assert.Error(t, err)
+}
+
+func TestOpenImportWorkbook_CSV(t *testing.T) {
+	content := "\xef\xbb\xbfquestion_type,question_title,correct_answer,option_a,option_b\n" +
+		"0,\"Redis默认端口是？\",A,6379,3306\n" +
+		"4,TCP是面向连接的协议,对\n"
+	file, sheets, err := openImportWorkbook(strings.NewReader(content), "网络/基础.csv")
+	require.NoError(t, err)
+	defer file.Close()
+	require.Len(t, sheets, 1)
+	assert.Equal(t, "基础", sheets[0].Name)
+
+	rows := parseImportSheets(sheets, false)
+	require.Len(t, rows, 2)
+	for _, row := range rows {
+		assert.Empty(t, row.Error)
+	}
+	assert.Equal(t, []string{"6379", "3306"}, []string(rows[0].Question.Options))
+
+	assert.Equal(t, "Sheet1", sanitizeSheetName(" [*] "))
+	assert.Equal(t, "ab", sanitizeSheetName("a/b"))
+}
+
+func TestBuildExcelImportReport(t *testing.T) {
+	buf := newTestWorkbook(t, map[string][][]string{"题目": testImportRows}, "题目")
+	report, err := BuildExcelImportReport(buf, &ExcelImportOptions{Filename: "questions.xlsx"})
+	require.NoError(t, err)
+	defer report.Close()
+
+	// 错误原因列追加在最后一列（第11列）之后
+	header, _ := report.GetCellValue("题目", "L1")
+	assert.Equal(t, excelImportErrorHeader, header)
+	valid, _ := report.GetCellValue("题目", "L2")
+	assert.Empty(t, valid)
+	invalid, _ := report.GetCellValue("题目", "L3")
+	assert.Contains(t, invalid, "题型无效")
+	styleID, _ := report.GetCellStyle("题目", "A3")
+	assert.NotZero(t, styleID)
+	styleID, _ = report.GetCellStyle("题目", "A2")
+	assert.Zero(t, styleID)
+}
+
+func TestMarkDuplicateExternalIDs(t *testing.T) {
+	columns, err := parseExcelHeader([]string{"题目编号", "题型", "题干", "正确答案"})
+	require.NoError(t, err)
+	rows := parseImportSheets([]*importSheet{{Name: "题目", Columns: columns, Rows: [][]string{
+		{"题目编号", "题型", "题干", "正确答案"},
+		{"Q001", "4", "TCP是面向连接的协议", "对"},
+		{"Q002", "4", "UDP是面向连接的协议", "错"},
+		{"Q001", "4", "HTTP是无状态协议", "对"},
+		{"", "4", "HTTPS默认端口是443", "对"},
+	}}}, false)
+	require.Len(t, rows, 4)
+
+	assert.Equal(t, "Q001", rows[0].Question.ExternalID)
+	assert.Empty(t, rows[1].Error)
+	assert.Nil(t, rows[2].Question)
+	assert.Equal(t, "题目编号「Q001」与工作表「题目」第2行重复", rows[2].Error)
+	assert.Empty(t, rows[3].Error)
+}
+
+func TestSplitImportRowsByExternalID(t *testing.T) {
+	newRow := func(externalID, title string) *ExcelImportRow {
+		return &ExcelImportRow{Question: &model.ExamQuestion{
+			ExternalID: externalID, QuestionType: 4, QuestionTitle: title, CorrectAnswer: "对",
+		}}
+	}
+	existing := map[string]*model.ExamQuestion{
+		"Q001": {ID: 1, ExternalID: "Q001", QuestionType: 4, QuestionTitle: "TCP是面向连接的协议", CorrectAnswer: "对"},
+		"Q002": {ID: 2, ExternalID: "Q002", QuestionType: 4, QuestionTitle: "旧题干", CorrectAnswer: "对"},
+	}
+	rows := []*ExcelImportRow{
+		newRow("Q001", "TCP是面向连接的协议"), // 未变化
+		newRow("Q002", "新题干"),         // 更新
+		newRow("Q003", "编号不存在的题目"),    // 新增
+		newRow("", "没有编号的题目"),         // 新增
+	}
+
+	updates, newRows, unchanged := splitImportRowsByExternalID(rows, existing)
+	assert.Equal(t, 1, unchanged)
+	require.Len(t, updates, 1)
+	assert.Equal(t, uint(2), updates[0].existing.ID)
+	assert.Equal(t, "新题干", updates[0].question.QuestionTitle)
+	assert.Equal(t, []*ExcelImportRow{rows[2], rows[3]}, newRows)
+}
+
+func TestQuestionContentChanged(t *testing.T) {
+	existing := &model.ExamQuestion{
+		QuestionType: 0, QuestionTitle: "Redis默认端口是？", Options: model.QuestionOptions{"6379", "3306"},
+		CorrectAnswer: "A", Tag: "", Blanks: model.FillBlanks{}, Status: 1,
+	}
+	question := *existing
+	question.Options = model.QuestionOptions{"6379", "3306"}
+	question.Status = 0 // 审核状态等不可导入的字段不参与比较
+	assert.False(t, questionContentChanged(existing, &question))
+
+	question.Options = append(question.Options, "27017")
+	assert.True(t, questionContentChanged(existing, &question))
+
+	question = *existing
+	question.AnswerAnalysis = "默认端口为6379"
+	assert.True(t, questionContentChanged(existing, &question))
+}
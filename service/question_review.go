@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vaynedu/exam_system/config"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/model"
+	"gorm.io/gorm"
+)
+
+// QuestionReviewBatchMaxCount 单次批量审核的最大题目数量
+const QuestionReviewBatchMaxCount = 100
+
+// QuestionReviewResult 审核结果：成功审核的题目ID，以及不存在或不是待审核状态而跳过的题目ID
+type QuestionReviewResult struct {
+	Succeeded []uint `json:"succeeded"`
+	Skipped   []uint `json:"skipped"`
+}
+
+// GetReviewQuestionsService 获取审核队列中的题目（默认待审核，也可查看已驳回、已通过），筛选条件同题目列表
+func GetReviewQuestionsService(status int, tag, secondTag, questionType, keyword string, page, size int) ([]model.ExamQuestion, int64, error) {
+	if !consts.CheckQuestionStatus(status) {
+		return nil, 0, errors.New("审核状态无效")
+	}
+	if err := validateTagRelation(tag, secondTag); err != nil {
+		return nil, 0, err
+	}
+	return listQuestionsByStatus(status, tag, secondTag, nil, false, questionType, keyword, page, size)
+}
+
+// UpdateReviewQuestionService 编辑待审核的题目（校验规则同更新题目）
+func UpdateReviewQuestionService(question *model.ExamQuestion) error {
+	ids, err := dao.NewQuestionDao(config.DB).GetQuestionIDsByStatus([]uint{question.ID}, consts.QuestionStatusPending)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return errors.New("题目不存在或不是待审核状态")
+	}
+	return UpdateQuestionService(question)
+}
+
+// ApproveQuestionsService 审核通过题目，通过后进入正式题库
+func ApproveQuestionsService(ids []uint, reviewerID uint) (*QuestionReviewResult, error) {
+	return reviewQuestions(ids, consts.QuestionStatusApproved, reviewerID, "")
+}
+
+// RejectQuestionsService 驳回题目，驳回的题目不会进入正式题库
+func RejectQuestionsService(ids []uint, reviewerID uint, reason string) (*QuestionReviewResult, error) {
+	if utf8.RuneCountInString(reason) > consts.QuestionRejectReasonMaxLen {
+		return nil, fmt.Errorf("驳回原因不能超过%d个字符", consts.QuestionRejectReasonMaxLen)
+	}
+	return reviewQuestions(ids, consts.QuestionStatusRejected, reviewerID, reason)
+}
+
+// reviewQuestions 将待审核的题目改为指定状态并记录审核人与时间，非待审核状态的题目跳过
+func reviewQuestions(ids []uint, status int, reviewerID uint, reason string) (*QuestionReviewResult, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return nil, errors.New("请选择要审核的题目")
+	}
+	if len(ids) > QuestionReviewBatchMaxCount {
+		return nil, fmt.Errorf("单次最多审核%d道题目", QuestionReviewBatchMaxCount)
+	}
+
+	result := &QuestionReviewResult{Succeeded: []uint{}, Skipped: []uint{}}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		questionDao := dao.NewQuestionDao(tx)
+		pendingIDs, err := questionDao.GetQuestionIDsByStatus(ids, consts.QuestionStatusPending)
+		if err != nil {
+			return err
+		}
+		if _, err := questionDao.ReviewQuestions(pendingIDs, consts.QuestionStatusPending, status, reviewerID, reason, time.Now()); err != nil {
+			return err
+		}
+		result.Succeeded, result.Skipped = splitReviewedIDs(ids, pendingIDs)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// splitReviewedIDs 按请求顺序将题目ID分为已审核与跳过两组
+func splitReviewedIDs(ids, reviewedIDs []uint) (succeeded, skipped []uint) {
+	reviewed := make(map[uint]bool, len(reviewedIDs))
+	for _, id := range reviewedIDs {
+		reviewed[id] = true
+	}
+	succeeded, skipped = []uint{}, []uint{}
+	for _, id := range ids {
+		if reviewed[id] {
+			succeeded = append(succeeded, id)
+		} else {
+			skipped = append(skipped, id)
+		}
+	}
+	return succeeded, skipped
+}
+
+// uniqueIDs 去除重复及为0的ID，保持原有顺序
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
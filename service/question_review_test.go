@@ -0,0 +1,30 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/exam_system/consts"
+)
+
+func TestUniqueIDs(t *testing.T) {
+	assert.Equal(t, []uint{3, 1, 2}, uniqueIDs([]uint{3, 1, 0, 3, 2, 1}))
+	assert.Empty(t, uniqueIDs(nil))
+}
+
+func TestSplitReviewedIDs(t *testing.T) {
+	succeeded, skipped := splitReviewedIDs([]uint{1, 2, 3, 4}, []uint{4, 2})
+	assert.Equal(t, []uint{2, 4}, succeeded)
+	assert.Equal(t, []uint{1, 3}, skipped)
+
+	succeeded, skipped = splitReviewedIDs([]uint{5}, nil)
+	assert.Empty(t, succeeded)
+	assert.Equal(t, []uint{5}, skipped)
+}
+
+func TestCheckQuestionStatus(t *testing.T) {
+	assert.True(t, consts.CheckQuestionStatus(consts.QuestionStatusApproved))
+	assert.True(t, consts.CheckQuestionStatus(consts.QuestionStatusPending))
+	assert.True(t, consts.CheckQuestionStatus(consts.QuestionStatusRejected))
+	assert.False(t, consts.CheckQuestionStatus(3))
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/model"
+)
+
+// ParseTagFilters 解析多分类筛选参数，每项格式为“一级分类”或“一级分类/下级分类”（下级分类可为任意层级）
+func ParseTagFilters(values []string) ([]dao.TagRef, error) {
+	refs := make([]dao.TagRef, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		// 一级分类名称不含“/”，下级分类名称可能含“/”（如“高级/竞赛算法”），只按第一个“/”拆分
+		tag, secondTag, _ := strings.Cut(value, "/")
+		ref := dao.TagRef{Tag: strings.TrimSpace(tag), SecondTag: strings.TrimSpace(secondTag)}
+		if !consts.IsValidPrimaryTag(ref.Tag) {
+			return nil, fmt.Errorf("一级分类无效：%s", ref.Tag)
+		}
+		if ref.SecondTag != "" && !consts.IsSecondaryOfPrimary(ref.Tag, ref.SecondTag) {
+			return nil, fmt.Errorf("分类「%s」不属于一级分类「%s」", ref.SecondTag, ref.Tag)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// BuildQuestionTagFilter 合并单分类参数（tag/secondTag）与多分类参数，并将每个分类展开为其自身及全部下级分类
+func BuildQuestionTagFilter(tag, secondTag string, tags []dao.TagRef, matchAll bool) dao.QuestionTagFilter {
+	if tag != "" {
+		tags = append([]dao.TagRef{{Tag: tag, SecondTag: secondTag}}, tags...)
+	}
+
+	filter := dao.QuestionTagFilter{MatchAll: matchAll}
+	for _, ref := range tags {
+		match := dao.TagMatch{Tag: ref.Tag}
+		if ref.SecondTag != "" {
+			match.SecondTags = consts.GetTagDescendants(ref.Tag, ref.SecondTag)
+			if len(match.SecondTags) == 0 {
+				match.SecondTags = []string{ref.SecondTag}
+			}
+		}
+		filter.Matches = append(filter.Matches, match)
+	}
+	return filter
+}
+
+// normalizeQuestionTags 校验题目的分类列表并去重，保证包含主分类；未设置主分类时以第一个分类作为主分类
+func normalizeQuestionTags(question *model.ExamQuestion) error {
+	if question.Tag == "" && len(question.Tags) > 0 {
+		question.Tag = strings.TrimSpace(question.Tags[0].Tag)
+		question.SecondTag = strings.TrimSpace(question.Tags[0].SecondTag)
+	}
+
+	tags := make([]*model.ExamQuestionTag, 0, len(question.Tags)+1)
+	seen := make(map[dao.TagRef]bool, len(question.Tags)+1)
+	if question.Tag != "" {
+		tags = append(tags, &model.ExamQuestionTag{Tag: question.Tag, SecondTag: question.SecondTag})
+		seen[dao.TagRef{Tag: question.Tag, SecondTag: question.SecondTag}] = true
+	}
+	for _, tag := range question.Tags {
+		if tag == nil {
+			continue
+		}
+		ref := dao.TagRef{Tag: strings.TrimSpace(tag.Tag), SecondTag: strings.TrimSpace(tag.SecondTag)}
+		if ref.Tag == "" || ref.SecondTag == "" {
+			return errors.New("题目分类的一级分类和二级分类都不能为空")
+		}
+		if err := validateTagRelation(ref.Tag, ref.SecondTag); err != nil {
+			return fmt.Errorf("分类「%s/%s」无效：%w", ref.Tag, ref.SecondTag, err)
+		}
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		tags = append(tags, &model.ExamQuestionTag{Tag: ref.Tag, SecondTag: ref.SecondTag})
+	}
+	question.Tags = tags
+	return nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/model"
+)
+
+// setTestKnowledgeTree 替换知识树缓存，测试结束后恢复
+func setTestKnowledgeTree(t *testing.T, tree []consts.PrimaryTag) {
+	old := consts.GetKnowledgeTree()
+	consts.SetKnowledgeTree(tree)
+	t.Cleanup(func() { consts.SetKnowledgeTree(old) })
+}
+
+var testKnowledgeTree = []consts.PrimaryTag{
+	{
+		Name:      "数据存储",
+		SecondTag: []string{"Redis", "持久化", "AOF", "MySQL"},
+		Children: []consts.TagNode{
+			{Name: "Redis", Children: []consts.TagNode{
+				{Name: "持久化", Children: []consts.TagNode{{Name: "AOF"}}},
+			}},
+			{Name: "MySQL"},
+		},
+	},
+	{
+		Name:      "算法",
+		SecondTag: []string{"高级/竞赛算法"},
+		Children:  []consts.TagNode{{Name: "高级/竞赛算法"}},
+	},
+	{
+		Name:      "系统设计",
+		SecondTag: []string{"分布式锁"},
+		Children:  []consts.TagNode{{Name: "分布式锁"}},
+	},
+}
+
+func TestParseTagFilters(t *testing.T) {
+	setTestKnowledgeTree(t, testKnowledgeTree)
+
+	refs, err := ParseTagFilters([]string{"数据存储/Redis", " 算法/高级/竞赛算法 ", "系统设计", ""})
+	assert.NoError(t, err)
+	assert.Equal(t, []dao.TagRef{
+		{Tag: "数据存储", SecondTag: "Redis"},
+		{Tag: "算法", SecondTag: "高级/竞赛算法"},
+		{Tag: "系统设计"},
+	}, refs)
+
+	_, err = ParseTagFilters([]string{"不存在"})
+	assert.Error(t, err)
+	_, err = ParseTagFilters([]string{"系统设计/Redis"})
+	assert.Error(t, err)
+}
+
+func TestBuildQuestionTagFilter(t *testing.T) {
+	setTestKnowledgeTree(t, testKnowledgeTree)
+
+	filter := BuildQuestionTagFilter("数据存储", "持久化", []dao.TagRef{
+		{Tag: "系统设计", SecondTag: "分布式锁"},
+		{Tag: "算法"},
+	}, true)
+	assert.True(t, filter.MatchAll)
+	assert.Equal(t, []dao.TagMatch{
+		{Tag: "数据存储", SecondTags: []string{"持久化", "AOF"}},
+		{Tag: "系统设计", SecondTags: []string{"分布式锁"}},
+		{Tag: "算法"},
+	}, filter.Matches)
+
+	assert.Empty(t, BuildQuestionTagFilter("", "", nil, false).Matches)
+}
+
+func TestNormalizeQuestionTags(t *testing.T) {
+	setTestKnowledgeTree(t, testKnowledgeTree)
+
+	question := &model.ExamQuestion{
+		Tag:       "数据存储",
+		SecondTag: "Redis",
+		Tags: []*model.ExamQuestionTag{
+			{Tag: "系统设计", SecondTag: "分布式锁"},
+			{Tag: "数据存储", SecondTag: "Redis"},
+			{Tag: " 系统设计 ", SecondTag: "分布式锁"},
+		},
+	}
+	assert.NoError(t, normalizeQuestionTags(question))
+	assert.Equal(t, []*model.ExamQuestionTag{
+		{Tag: "数据存储", SecondTag: "Redis"},
+		{Tag: "系统设计", SecondTag: "分布式锁"},
+	}, question.Tags)
+
+	// 未设置主分类时取第一个分类
+	question = &model.ExamQuestion{Tags: []*model.ExamQuestionTag{{Tag: "数据存储", SecondTag: "AOF"}}}
+	assert.NoError(t, normalizeQuestionTags(question))
+	assert.Equal(t, "数据存储", question.Tag)
+	assert.Equal(t, "AOF", question.SecondTag)
+	assert.Len(t, question.Tags, 1)
+
+	question = &model.ExamQuestion{Tags: []*model.ExamQuestionTag{{Tag: "数据存储"}}}
+	assert.Error(t, normalizeQuestionTags(question))
+	question = &model.ExamQuestion{Tags: []*model.ExamQuestionTag{{Tag: "系统设计", SecondTag: "Redis"}}}
+	assert.Error(t, normalizeQuestionTags(question))
+
+	question = &model.ExamQuestion{}
+	assert.NoError(t, normalizeQuestionTags(question))
+	assert.Empty(t, question.Tags)
+}
+
+func TestValidateRandomTagParams(t *testing.T) {
+	setTestKnowledgeTree(t, testKnowledgeTree)
+
+	tests := []struct {
+		name      string
+		tag       string
+		secondTag string
+		wantErr   string
+	}{
+		{name: "不指定分类", tag: "", secondTag: ""},
+		{name: "一级与二级匹配", tag: "数据存储", secondTag: "Redis"},
+		{name: "一级分类无效", tag: "不存在", secondTag: "Redis", wantErr: "一级分类无效"},
+		{name: "缺少二级分类", tag: "数据存储", secondTag: "", wantErr: "二级分类不能为空"},
+		{name: "二级分类不匹配", tag: "数据存储", secondTag: "分布式锁", wantErr: "不匹配"},
+		{name: "单独指定二级分类", tag: "", secondTag: "Redis", wantErr: "不能单独指定二级分类"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRandomTagParams(tt.tag, tt.secondTag)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+	"github.com/xuri/excelize/v2"
+)
+
+// 导入模板的工作表及下拉列表覆盖的行数
+const (
+	importTemplateQuestionSheet = "题目"
+	importTemplateGuideSheet    = "填写说明"
+	importTemplateTagSheet      = "分类数据" // 隐藏，供分类下拉列表引用（导入时忽略隐藏的工作表）
+	importTemplateMaxRow        = 1000
+)
+
+// importTemplateExample 导入模板中的示例题目
+type importTemplateExample struct {
+	questionType int
+	title        string
+	options      []string
+	answer       string
+	analysis     string
+}
+
+// importTemplateExamples 每种题型一道示例题目
+var importTemplateExamples = []importTemplateExample{
+	{consts.QuestionTypeChoice, "Redis默认监听的端口是？", []string{"6379", "3306", "5432", "27017"}, "A", "Redis默认端口为6379，MySQL为3306"},
+	{consts.QuestionTypeFillInTheBlank, "Linux下性能最好的I/O多路复用机制是____。", nil, "epoll|EPOLL", "多个空用分号分隔，同一空的多个答案用竖线分隔"},
+	{consts.QuestionTypeShortAnswer, "简述TCP三次握手的过程。", nil, "客户端发送SYN，服务端回复SYN+ACK，客户端再回复ACK", "按要点人工评分"},
+	{consts.QuestionTypeMultipleChoice, "以下属于关系型数据库的有？", []string{"MySQL", "Redis", "PostgreSQL", "MongoDB"}, "AC", "答案为多个字母，如AC或A,C"},
+	{consts.QuestionTypeTrueFalse, "HTTP是无状态协议。", nil, "对", "答案为对/错（也支持正确/错误、T/F）"},
+	{consts.QuestionTypeOrdering, "按OSI模型从下到上排列以下各层", []string{"传输层", "物理层", "网络层", "数据链路层"}, "BDCA", "答案为正确顺序，如BDCA或B>D>C>A"},
+	{consts.QuestionTypeMatching, "将以下数据库与其类型连线", []string{"MySQL|文档型", "MongoDB|键值型", "Redis|关系型"}, "A3,B1,C2", "选项为“左项|右项”，右项依次编号为1、2、3，答案为左项与右项序号的配对"},
+}
+
+// importTemplateGuide 导入模板的填写说明
+var importTemplateGuide = []string{
+	"1. 在「题目」工作表中从第2行开始填写题目，表头不要修改（列顺序可以调整），带*的列必填；也可以新增多个表头相同的工作表，导入时读取全部工作表。",
+	"2. 题型：从下拉列表选择题型名称（也支持题型编号：0=选择题 1=填空题 2=简答题 3=多选题 4=判断题 5=排序题 6=连线题）。",
+	"3. 选项：选择题、多选题、排序题、连线题从选项A开始依次填写2-8个选项，连线题的每个选项填写为“左项|右项”，其他题型的选项留空。",
+	"4. 正确答案：选择题为单个字母；多选题为多个字母；排序题为正确顺序；连线题为左项与右项序号的配对，如A3,B1,C2；判断题为对/错；填空题多个空用分号分隔，同一空的多个答案用竖线分隔。",
+	"5. 分类：先从下拉列表选择一级分类，二级分类的下拉列表只显示该一级分类下的分类；一级、二级分类需同时填写或同时留空。",
+	fmt.Sprintf("6. 题目编号（可选，最多%d个字符）：再次导入相同编号的题目时更新已有题目而不是新增，同一文件中编号不能重复。", consts.QuestionExternalIDMaxLen),
+	"7. 导入前可先使用导入预览检查每一行，下载的校验报告中会标出有误的行及原因。",
+}
+
+// importTemplateHeaders 导入模板的表头（均可被parseExcelHeader识别，带*的为必填列）
+func importTemplateHeaders() []string {
+	headers := []string{"题型*", "题干*"}
+	for i := 0; i < consts.QuestionOptionMaxCount; i++ {
+		headers = append(headers, "选项"+model.OptionLetter(i))
+	}
+	return append(headers, "正确答案*", "答案解析", "题目备注", "一级分类", "二级分类", "题目编号")
+}
+
+// importTemplateExampleRows 按表头列映射生成示例行，示例使用知识体系中的第一个分类
+func importTemplateExampleRows(headers []string, columns *excelColumns, tree []consts.PrimaryTag) [][]string {
+	tag, secondTag := "", ""
+	if len(tree) > 0 && len(tree[0].SecondTag) > 0 {
+		tag, secondTag = tree[0].Name, tree[0].SecondTag[0]
+	}
+	rows := make([][]string, 0, len(importTemplateExamples))
+	for i, example := range importTemplateExamples {
+		row := make([]string, len(headers))
+		row[columns.Type] = consts.GetQuestionTypeName(example.questionType)
+		row[columns.Title] = example.title
+		for j, option := range example.options {
+			row[columns.Options[j]] = option
+		}
+		row[columns.Answer] = example.answer
+		row[columns.Analysis] = example.analysis
+		row[columns.Tag] = tag
+		row[columns.SecondTag] = secondTag
+		row[columns.ExternalID] = fmt.Sprintf("EXAMPLE-%d", i+1)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// BuildImportTemplate 生成Excel导入模板：「题目」工作表包含导入所需的表头及题型、一级分类、二级分类（随一级分类联动）下拉列表，
+// 「填写说明」工作表包含填写规则及各题型示例；分类来自当前知识体系
+func BuildImportTemplate() (*excelize.File, error) {
+	headers := importTemplateHeaders()
+	columns, err := parseExcelHeader(headers)
+	if err != nil {
+		return nil, err
+	}
+	tree := consts.GetKnowledgeTree()
+
+	file := excelize.NewFile()
+	if err := writeImportTemplate(file, headers, columns, tree); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("生成导入模板失败：%w", err)
+	}
+	return file, nil
+}
+
+// writeImportTemplate 写入导入模板的各工作表
+func writeImportTemplate(file *excelize.File, headers []string, columns *excelColumns, tree []consts.PrimaryTag) error {
+	headerStyle, err := file.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"D9E1F2"}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// 1. 题目工作表：表头、列宽及冻结表头
+	sheet := importTemplateQuestionSheet
+	if err := file.SetSheetName(file.GetSheetName(0), sheet); err != nil {
+		return err
+	}
+	if err := writeTemplateHeader(file, sheet, 1, headers, headerStyle); err != nil {
+		return err
+	}
+	if err := setImportTemplateColWidths(file, sheet, headers, columns); err != nil {
+		return err
+	}
+	if err := file.SetPanes(sheet, &excelize.Panes{
+		Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft",
+	}); err != nil {
+		return err
+	}
+
+	// 2. 填写说明工作表：填写规则及各题型示例（首行不是题目表头，导入时忽略）
+	guide := importTemplateGuideSheet
+	if _, err := file.NewSheet(guide); err != nil {
+		return err
+	}
+	if err := file.SetCellValue(guide, "A1", "填写说明"); err != nil {
+		return err
+	}
+	if err := file.SetCellStyle(guide, "A1", "A1", headerStyle); err != nil {
+		return err
+	}
+	for i, line := range importTemplateGuide {
+		if err := file.SetCellValue(guide, fmt.Sprintf("A%d", i+2), line); err != nil {
+			return err
+		}
+	}
+	exampleRow := len(importTemplateGuide) + 3
+	if err := file.SetCellValue(guide, fmt.Sprintf("A%d", exampleRow), "示例（可复制到「题目」工作表中修改后导入）："); err != nil {
+		return err
+	}
+	if err := writeTemplateHeader(file, guide, exampleRow+1, headers, headerStyle); err != nil {
+		return err
+	}
+	for i, row := range importTemplateExampleRows(headers, columns, tree) {
+		if err := writeTemplateRow(file, guide, exampleRow+2+i, row); err != nil {
+			return err
+		}
+	}
+	if err := setImportTemplateColWidths(file, guide, headers, columns); err != nil {
+		return err
+	}
+
+	// 3. 题目工作表的题型、分类下拉列表
+	return addImportTemplateValidations(file, sheet, columns, tree)
+}
+
+// addImportTemplateValidations 为题型、一级分类、二级分类列添加下拉列表；
+// 分类写入隐藏的分类数据工作表：第1行为一级分类，第2行为其下二级分类的数量，第3行起为二级分类，
+// 二级分类的下拉列表按同行的一级分类用OFFSET/MATCH定位到对应的列
+func addImportTemplateValidations(file *excelize.File, sheet string, columns *excelColumns, tree []consts.PrimaryTag) error {
+	typeNames := make([]string, 0, len(consts.QuestionTypes))
+	for _, questionType := range consts.QuestionTypes {
+		typeNames = append(typeNames, consts.GetQuestionTypeName(questionType))
+	}
+	typeValidation := newTemplateValidation(columns.Type, "题型无效", "请从下拉列表中选择题型")
+	if err := typeValidation.SetDropList(typeNames); err != nil {
+		return err
+	}
+	if err := file.AddDataValidation(sheet, typeValidation); err != nil {
+		return err
+	}
+	if len(tree) == 0 {
+		return nil
+	}
+
+	tagSheet := importTemplateTagSheet
+	if _, err := file.NewSheet(tagSheet); err != nil {
+		return err
+	}
+	for i, primary := range tree {
+		values := []interface{}{primary.Name, len(primary.SecondTag)}
+		for _, secondTag := range primary.SecondTag {
+			values = append(values, secondTag)
+		}
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		if err := file.SetSheetCol(tagSheet, cell, &values); err != nil {
+			return err
+		}
+	}
+	if err := file.SetSheetVisible(tagSheet, false); err != nil {
+		return err
+	}
+
+	lastColumn, _ := excelize.ColumnNumberToName(len(tree))
+	tagValidation := newTemplateValidation(columns.Tag, "一级分类无效", "请从下拉列表中选择一级分类")
+	tagValidation.SetSqrefDropList(fmt.Sprintf("'%s'!$A$1:$%s$1", tagSheet, lastColumn))
+	if err := file.AddDataValidation(sheet, tagValidation); err != nil {
+		return err
+	}
+
+	tagColumn, _ := excelize.ColumnNumberToName(columns.Tag + 1)
+	match := fmt.Sprintf("MATCH($%s2,'%s'!$1:$1,0)", tagColumn, tagSheet)
+	secondTagValidation := newTemplateValidation(columns.SecondTag, "二级分类无效", "请先选择一级分类，再从下拉列表中选择该分类下的二级分类")
+	secondTagValidation.SetSqrefDropList(fmt.Sprintf("OFFSET('%s'!$A$3,0,%s-1,INDEX('%s'!$2:$2,%s),1)", tagSheet, match, tagSheet, match))
+	return file.AddDataValidation(sheet, secondTagValidation)
+}
+
+// newTemplateValidation 创建覆盖指定列（从0开始）第2行至importTemplateMaxRow行的数据验证，输入无效时提示错误
+func newTemplateValidation(column int, errorTitle, errorMsg string) *excelize.DataValidation {
+	name, _ := excelize.ColumnNumberToName(column + 1)
+	validation := excelize.NewDataValidation(true)
+	validation.SetSqref(fmt.Sprintf("%s2:%s%d", name, name, importTemplateMaxRow))
+	validation.SetError(excelize.DataValidationErrorStyleStop, errorTitle, errorMsg)
+	return validation
+}
+
+// writeTemplateHeader 在指定行写入表头并设置表头样式
+func writeTemplateHeader(file *excelize.File, sheet string, row int, headers []string, style int) error {
+	if err := writeTemplateRow(file, sheet, row, headers); err != nil {
+		return err
+	}
+	first, _ := excelize.CoordinatesToCellName(1, row)
+	last, _ := excelize.CoordinatesToCellName(len(headers), row)
+	return file.SetCellStyle(sheet, first, last, style)
+}
+
+// writeTemplateRow 从第1列开始写入一行
+func writeTemplateRow(file *excelize.File, sheet string, row int, values []string) error {
+	cells := make([]interface{}, len(values))
+	for i, value := range values {
+		cells[i] = value
+	}
+	cell, _ := excelize.CoordinatesToCellName(1, row)
+	return file.SetSheetRow(sheet, cell, &cells)
+}
+
+// setImportTemplateColWidths 按字段设置列宽：题干、答案解析较宽，其余列适中
+func setImportTemplateColWidths(file *excelize.File, sheet string, headers []string, columns *excelColumns) error {
+	widths := map[int]float64{
+		columns.Type:     10,
+		columns.Title:    40,
+		columns.Answer:   20,
+		columns.Analysis: 30,
+		columns.Remark:   20,
+	}
+	for i := range headers {
+		width, ok := widths[i]
+		if !ok {
+			width = 14
+		}
+		name, _ := excelize.ColumnNumberToName(i + 1)
+		if err := file.SetColWidth(sheet, name, name, width); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestBuildImportTemplate(t *testing.T) {
+	setTestKnowledgeTree(t, testKnowledgeTree)
+	file, err := BuildImportTemplate()
+	require.NoError(t, err)
+	defer file.Close()
+
+	// 表头包含导入识别的全部字段
+	rows, err := file.GetRows(importTemplateQuestionSheet)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	columns, err := parseExcelHeader(rows[0])
+	require.NoError(t, err)
+	assert.Equal(t, 16, len(rows[0]))
+	for _, col := range append(columns.Options[:], columns.Analysis, columns.Remark, columns.Tag, columns.SecondTag, columns.ExternalID) {
+		assert.NotEqual(t, -1, col)
+	}
+
+	validations, err := file.GetDataValidations(importTemplateQuestionSheet)
+	require.NoError(t, err)
+	assert.Len(t, validations, 3)
+
+	tags, err := file.GetCols(importTemplateTagSheet)
+	require.NoError(t, err)
+	require.Len(t, tags, 3)
+	assert.Equal(t, []string{"数据存储", "4", "Redis", "持久化", "AOF", "MySQL"}, tags[0])
+	visible, _ := file.GetSheetVisible(importTemplateTagSheet)
+	assert.False(t, visible)
+
+	// 填写说明中的示例行复制到题目工作表后全部可以导入
+	guide, err := file.GetRows(importTemplateGuideSheet)
+	require.NoError(t, err)
+	examples := guide[len(importTemplateGuide)+3:]
+	require.Len(t, examples, len(importTemplateExamples)+1)
+	parsed := parseImportSheets([]*importSheet{{Name: importTemplateQuestionSheet, Rows: examples, Columns: columns}}, false)
+	require.Len(t, parsed, len(importTemplateExamples))
+	for i, row := range parsed {
+		require.Empty(t, row.Error, "示例%d", i+1)
+		assert.Equal(t, int8(importTemplateExamples[i].questionType), row.Question.QuestionType)
+		assert.Equal(t, "数据存储", row.Question.Tag)
+	}
+
+	// 未填写题目的模板导入时提示无有效数据（填写说明、分类数据工作表被忽略）
+	var buf bytes.Buffer
+	require.NoError(t, file.Write(&buf))
+	_, _, err = openImportWorkbook(&buf, "template.xlsx")
+	assert.ErrorContains(t, err, "无有效数据")
+}
+
+func TestBuildImportTemplate_EmptyTree(t *testing.T) {
+	setTestKnowledgeTree(t, nil)
+	file, err := BuildImportTemplate()
+	require.NoError(t, err)
+	defer file.Close()
+
+	validations, err := file.GetDataValidations(importTemplateQuestionSheet)
+	require.NoError(t, err)
+	assert.Len(t, validations, 1) // 只有题型下拉列表
+	assert.Equal(t, []string{importTemplateQuestionSheet, importTemplateGuideSheet}, file.GetSheetList())
+
+	cell, _ := excelize.CoordinatesToCellName(1, len(importTemplateGuide)+5)
+	value, _ := file.GetCellValue(importTemplateGuideSheet, cell)
+	assert.Equal(t, "选择题", value)
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+)
+
+// optionLetterSeparators 选项字母答案中允许出现的分隔符，如“A,C,D”“C>A>B>D”
+const optionLetterSeparators = ",，、;；/|>＞-→ \t"
+
+// matchingPairSeparators 连线题答案中各配对之间允许出现的分隔符，如“A2,B3,C1”“A-2；B-3；C-1”
+const matchingPairSeparators = ",，、;；/| \t"
+
+// matchingPairPattern 连线题答案中的一个配对：左项字母+右项序号，中间可以有连接符，如“A2”“A-2”“A→2”“A=2”
+var matchingPairPattern = regexp.MustCompile(`^([A-Z])[-－→=＝:：>＞]?([0-9]+)$`)
+
+// validateQuestionContent 按题型校验题干、选项与正确答案，并将正确答案规范化：
+// 单选/多选/排序题转为大写字母（多选题按字母排序，如“ACD”），判断题转为“对/错”，
+// 连线题转为按左项字母排序的配对（如“A2,B3,C1”），填空题解析出各空的可接受答案（保留原大小写）；
+// 校验通过后计算题干指纹
+func validateQuestionContent(question *model.ExamQuestion) error {
+	questionType := int(question.QuestionType)
+	if !consts.CheckQuestionType(questionType) {
+		return errors.New("题型无效！仅支持0（选择题）、1（填空题）、2（问答题）、3（多选题）、4（判断题）、5（排序题）、6（连线题）")
+	}
+
+	question.CorrectAnswer = strings.TrimSpace(question.CorrectAnswer)
+	if question.QuestionTitle == "" || question.CorrectAnswer == "" {
+		return errors.New("题干和正确答案不能为空！")
+	}
+
+	question.SyncOptions()
+	if consts.IsOptionQuestionType(questionType) {
+		if err := validateQuestionOptions(question); err != nil {
+			return err
+		}
+	}
+	letters := filledOptionLetters(question)
+
+	if questionType != consts.QuestionTypeFillInTheBlank {
+		question.Blanks = model.FillBlanks{}
+	}
+
+	switch questionType {
+	case consts.QuestionTypeFillInTheBlank:
+		if err := normalizeFillBlanks(question); err != nil {
+			return err
+		}
+	case consts.QuestionTypeChoice:
+		answer := strings.ToUpper(question.CorrectAnswer)
+		if len(answer) != 1 || !strings.Contains(letters, answer) {
+			return fmt.Errorf("选择题正确答案只能是%s中的一个字母，且需对应已有选项！", letters)
+		}
+		question.CorrectAnswer = answer
+	case consts.QuestionTypeMultipleChoice:
+		answer, err := normalizeMultipleChoiceAnswer(question.CorrectAnswer)
+		if err != nil || strings.Trim(answer, letters) != "" {
+			return fmt.Errorf("多选题正确答案只能由已有选项%s组成，如“%s”！", letters, letters[:2])
+		}
+		if len(answer) < 2 {
+			return errors.New("多选题正确答案至少包含两个选项！")
+		}
+		question.CorrectAnswer = answer
+	case consts.QuestionTypeTrueFalse:
+		answer, ok := normalizeTrueFalseAnswer(question.CorrectAnswer)
+		if !ok {
+			return errors.New("判断题正确答案只能是对/错（也支持正确/错误、T/F、√/×）！")
+		}
+		question.CorrectAnswer = answer
+		question.Options = model.QuestionOptions{}
+		question.OptionA, question.OptionB, question.OptionC, question.OptionD = "", "", "", ""
+	case consts.QuestionTypeOrdering:
+		answer, err := parseOptionLetters(question.CorrectAnswer)
+		if err != nil || !isPermutation(answer, letters) {
+			return fmt.Errorf("排序题正确答案需为选项%s的一个排列，如“%s”！", letters, reverseString(letters))
+		}
+		question.CorrectAnswer = answer
+	case consts.QuestionTypeMatching:
+		if err := validateMatchingOptions(question); err != nil {
+			return err
+		}
+		answer, err := normalizeMatchingAnswer(question.CorrectAnswer)
+		if err != nil || !isMatchingAnswerComplete(answer, letters) {
+			return fmt.Errorf("连线题正确答案需为选项%s与右项序号1-%d的一一配对，如“%s”！", letters, len(letters), matchingAnswerExample(letters))
+		}
+		question.CorrectAnswer = answer
+	}
+	syncTitleFingerprint(question)
+	return nil
+}
+
+// validateQuestionOptions 校验选项题的选项：数量在2-8个之间，依次从A开始填写且不能为空
+func validateQuestionOptions(question *model.ExamQuestion) error {
+	typeName := consts.GetQuestionTypeName(int(question.QuestionType))
+	count := len(question.Options)
+	if count < consts.QuestionOptionMinCount || count > consts.QuestionOptionMaxCount {
+		return fmt.Errorf("%s需要%d-%d个选项，当前为%d个！", typeName, consts.QuestionOptionMinCount, consts.QuestionOptionMaxCount, count)
+	}
+	for i, option := range question.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return fmt.Errorf("%s的选项%s不能为空，选项需从A开始连续填写！", typeName, model.OptionLetter(i))
+		}
+		if utf8.RuneCountInString(option) > consts.QuestionOptionMaxLen {
+			return fmt.Errorf("%s的选项%s不能超过%d个字符！", typeName, model.OptionLetter(i), consts.QuestionOptionMaxLen)
+		}
+		question.Options[i] = option
+	}
+	question.SyncOptions()
+	return nil
+}
+
+// validateMatchingOptions 校验连线题的选项：每个选项都需用“|”分隔为非空的左项与右项，如“MySQL|关系型”；
+// 右项按选项顺序编号为1、2、3...展示，与左项的对应关系由正确答案给出
+func validateMatchingOptions(question *model.ExamQuestion) error {
+	for i, option := range question.Options {
+		left, right, ok := strings.Cut(option, consts.MatchingOptionSeparator)
+		if !ok || strings.TrimSpace(left) == "" || strings.TrimSpace(right) == "" {
+			return fmt.Errorf("连线题的选项%s需填写为“左项%s右项”，如“MySQL%s关系型”！",
+				model.OptionLetter(i), consts.MatchingOptionSeparator, consts.MatchingOptionSeparator)
+		}
+	}
+	return nil
+}
+
+// normalizeMatchingAnswer 连线题答案规范化：解析“左项字母+右项序号”的配对并按左项字母排序，如“c1；a-2 b3”→“A2,B3,C1”；
+// 同一左项出现多次时返回错误
+func normalizeMatchingAnswer(answer string) (string, error) {
+	pairs := strings.FieldsFunc(strings.ToUpper(answer), func(r rune) bool {
+		return strings.ContainsRune(matchingPairSeparators, r)
+	})
+	if len(pairs) == 0 {
+		return "", errors.New("连线题答案为空")
+	}
+	rights := make(map[string]int, len(pairs))
+	lefts := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		match := matchingPairPattern.FindStringSubmatch(pair)
+		if match == nil {
+			return "", fmt.Errorf("无效的配对：%s", pair)
+		}
+		if _, ok := rights[match[1]]; ok {
+			return "", fmt.Errorf("左项%s重复配对", match[1])
+		}
+		right, err := strconv.Atoi(match[2])
+		if err != nil {
+			return "", fmt.Errorf("无效的配对：%s", pair)
+		}
+		rights[match[1]] = right
+		lefts = append(lefts, match[1])
+	}
+	sort.Strings(lefts)
+	for i, left := range lefts {
+		lefts[i] = left + strconv.Itoa(rights[left])
+	}
+	return strings.Join(lefts, ","), nil
+}
+
+// isMatchingAnswerComplete 判断规范化后的连线题答案是否将letters中的每个左项与右项1-n一一配对
+func isMatchingAnswerComplete(answer, letters string) bool {
+	pairs := strings.Split(answer, ",")
+	if len(pairs) != len(letters) {
+		return false
+	}
+	used := make(map[int]bool, len(pairs))
+	for i, pair := range pairs {
+		right, err := strconv.Atoi(pair[1:])
+		if pair[0] != letters[i] || err != nil || right < 1 || right > len(letters) || used[right] {
+			return false
+		}
+		used[right] = true
+	}
+	return true
+}
+
+// matchingAnswerExample 生成连线题答案示例（右项逆序），如“A3,B2,C1”
+func matchingAnswerExample(letters string) string {
+	pairs := make([]string, len(letters))
+	for i := range letters {
+		pairs[i] = letters[i:i+1] + strconv.Itoa(len(letters)-i)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// parseOptionLetters 解析由选项字母组成的答案（忽略大小写和分隔符），返回按原顺序排列的大写字母
+func parseOptionLetters(answer string) (string, error) {
+	var builder strings.Builder
+	for _, r := range strings.ToUpper(answer) {
+		if strings.ContainsRune(optionLetterSeparators, r) {
+			continue
+		}
+		if r < 'A' || r > 'Z' {
+			return "", fmt.Errorf("无效的选项：%c", r)
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String(), nil
+}
+
+// normalizeMultipleChoiceAnswer 多选题答案规范化：去重并按字母排序，如“c, a d”→“ACD”
+func normalizeMultipleChoiceAnswer(answer string) (string, error) {
+	letters, err := parseOptionLetters(answer)
+	if err != nil {
+		return "", err
+	}
+	runes := []rune(letters)
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	var builder strings.Builder
+	for i, r := range runes {
+		if i > 0 && runes[i-1] == r {
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String(), nil
+}
+
+// normalizeTrueFalseAnswer 判断题答案规范化为“对/错”
+func normalizeTrueFalseAnswer(answer string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "对", "正确", "是", "√", "✓", "✔", "t", "true", "y", "yes", "1":
+		return consts.TrueFalseAnswerTrue, true
+	case "错", "错误", "否", "×", "✗", "✘", "x", "f", "false", "n", "no", "0":
+		return consts.TrueFalseAnswerFalse, true
+	}
+	return "", false
+}
+
+// filledOptionLetters 返回已填写内容的选项字母，如“ABC”
+func filledOptionLetters(question *model.ExamQuestion) string {
+	var letters string
+	for i, option := range question.Options {
+		if strings.TrimSpace(option) != "" {
+			letters += model.OptionLetter(i)
+		}
+	}
+	return letters
+}
+
+// isPermutation 判断answer是否恰好是letters中全部字母的一个排列
+func isPermutation(answer, letters string) bool {
+	if len(answer) != len(letters) {
+		return false
+	}
+	for _, r := range letters {
+		if strings.Count(answer, string(r)) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// reverseString 反转字符串（用于生成排序题答案示例）
+func reverseString(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
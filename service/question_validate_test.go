@@ -0,0 +1,121 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+)
+
+// 测试validateQuestionContent按题型校验并规范化正确答案
+func TestValidateQuestionContent(t *testing.T) {
+	withOptions := func(questionType int, answer string, options ...string) *model.ExamQuestion {
+		question := &model.ExamQuestion{QuestionType: int8(questionType), QuestionTitle: "题干", CorrectAnswer: answer}
+		fields := []*string{&question.OptionA, &question.OptionB, &question.OptionC, &question.OptionD}
+		for i, option := range options {
+			*fields[i] = option
+		}
+		return question
+	}
+
+	testCases := []struct {
+		name     string
+		question *model.ExamQuestion
+		wantErr  bool
+		expected string
+	}{
+		{"单选题小写答案", withOptions(consts.QuestionTypeChoice, "b", "1", "2", "3", "4"), false, "B"},
+		{"单选题多个答案", withOptions(consts.QuestionTypeChoice, "AB", "1", "2", "3", "4"), true, ""},
+		{"单选题三个选项", withOptions(consts.QuestionTypeChoice, "c", "1", "2", "3"), false, "C"},
+		{"单选题答案对应的选项不存在", withOptions(consts.QuestionTypeChoice, "D", "1", "2", "3"), true, ""},
+		{"多选题规范化", withOptions(consts.QuestionTypeMultipleChoice, "d,a，c", "1", "2", "3", "4"), false, "ACD"},
+		{"多选题只有一个答案", withOptions(consts.QuestionTypeMultipleChoice, "A", "1", "2", "3", "4"), true, ""},
+		{"多选题超出选项", withOptions(consts.QuestionTypeMultipleChoice, "AE", "1", "2", "3", "4"), true, ""},
+		{"判断题同义写法", withOptions(consts.QuestionTypeTrueFalse, "×"), false, consts.TrueFalseAnswerFalse},
+		{"判断题无法识别", withOptions(consts.QuestionTypeTrueFalse, "也许"), true, ""},
+		{"排序题三项", withOptions(consts.QuestionTypeOrdering, "c > a > b", "1", "2", "3"), false, "CAB"},
+		{"排序题缺项", withOptions(consts.QuestionTypeOrdering, "CA", "1", "2", "3"), true, ""},
+		{"排序题重复项", withOptions(consts.QuestionTypeOrdering, "CAA", "1", "2", "3"), true, ""},
+		{"排序题选项不连续", withOptions(consts.QuestionTypeOrdering, "AC", "1", "", "3"), true, ""},
+		{"连线题规范化", withOptions(consts.QuestionTypeMatching, "c-1；a→3 b2", "MySQL|文档型", "MongoDB|键值型", "Redis|关系型"), false, "A3,B2,C1"},
+		{"连线题缺少配对", withOptions(consts.QuestionTypeMatching, "A3,B1", "MySQL|文档型", "MongoDB|键值型", "Redis|关系型"), true, ""},
+		{"连线题右项重复", withOptions(consts.QuestionTypeMatching, "A1,B1,C2", "MySQL|文档型", "MongoDB|键值型", "Redis|关系型"), true, ""},
+		{"连线题左项重复", withOptions(consts.QuestionTypeMatching, "A1,A2,C3", "MySQL|文档型", "MongoDB|键值型", "Redis|关系型"), true, ""},
+		{"连线题右项超出", withOptions(consts.QuestionTypeMatching, "A1,B4,C2", "MySQL|文档型", "MongoDB|键值型", "Redis|关系型"), true, ""},
+		{"连线题选项缺少右项", withOptions(consts.QuestionTypeMatching, "A2,B1", "MySQL|关系型", "MongoDB"), true, ""},
+		{"单选题两个选项", withOptions(consts.QuestionTypeChoice, "b", "对", "错"), false, "B"},
+		{"单选题答案超出选项", withOptions(consts.QuestionTypeChoice, "C", "1", "2"), true, ""},
+		{"单选题只有一个选项", withOptions(consts.QuestionTypeChoice, "A", "1"), true, ""},
+		{"填空题保留原答案", withOptions(consts.QuestionTypeFillInTheBlank, " epoll "), false, "epoll"},
+		{"无效题型", withOptions(9, "A"), true, ""},
+		{"答案为空", withOptions(consts.QuestionTypeShortAnswer, " "), true, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateQuestionContent(tc.question)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, tc.question.CorrectAnswer)
+		})
+	}
+}
+
+// 测试判断题选项会被清空
+func TestValidateQuestionContent_TrueFalseClearsOptions(t *testing.T) {
+	question := &model.ExamQuestion{
+		QuestionType:  consts.QuestionTypeTrueFalse,
+		QuestionTitle: "TCP是面向连接的协议",
+		CorrectAnswer: "T",
+		OptionA:       "对",
+		OptionB:       "错",
+	}
+	assert.NoError(t, validateQuestionContent(question))
+	assert.Equal(t, consts.TrueFalseAnswerTrue, question.CorrectAnswer)
+	assert.Empty(t, question.OptionA)
+	assert.Empty(t, question.OptionB)
+}
+
+// 测试选项列表支持2-8个选项，答案字母需对应已有选项
+func TestValidateQuestionContent_OptionList(t *testing.T) {
+	newQuestion := func(questionType int, answer string, count int) *model.ExamQuestion {
+		question := &model.ExamQuestion{QuestionType: int8(questionType), QuestionTitle: "题干", CorrectAnswer: answer}
+		for i := 0; i < count; i++ {
+			question.Options = append(question.Options, " 选项"+model.OptionLetter(i)+" ")
+		}
+		return question
+	}
+
+	question := newQuestion(consts.QuestionTypeMultipleChoice, "f,b", 6)
+	assert.NoError(t, validateQuestionContent(question))
+	assert.Equal(t, "BF", question.CorrectAnswer)
+	assert.Equal(t, "选项A", question.Options[0])
+	assert.Equal(t, "选项D", question.OptionD)
+
+	assert.NoError(t, validateQuestionContent(newQuestion(consts.QuestionTypeChoice, "H", 8)))
+	assert.Error(t, validateQuestionContent(newQuestion(consts.QuestionTypeChoice, "G", 6)))
+	assert.Error(t, validateQuestionContent(newQuestion(consts.QuestionTypeChoice, "A", 9)))
+	assert.NoError(t, validateQuestionContent(newQuestion(consts.QuestionTypeOrdering, "EDCBA", 5)))
+	assert.Error(t, validateQuestionContent(newQuestion(consts.QuestionTypeOrdering, "DCBA", 5)))
+
+	gap := newQuestion(consts.QuestionTypeChoice, "A", 5)
+	gap.Options[2] = " "
+	assert.Error(t, validateQuestionContent(gap))
+}
+
+// 测试Excel行解析：选项E-H位于二级分类之后，缺少的列按空值处理
+func TestParseAndValidateRow_ExtraOptions(t *testing.T) {
+	row := []string{"0", "题干", "A1", "B1", "C1", "D1", "f", "", "", "", "", "E1", "F1"}
+	question, err := parseAndValidateRow(row, &testExcelColumns, "")
+	assert.NoError(t, err)
+	assert.Equal(t, model.QuestionOptions{"A1", "B1", "C1", "D1", "E1", "F1"}, question.Options)
+	assert.Equal(t, "F", question.CorrectAnswer)
+
+	question, err = parseAndValidateRow([]string{"4", "TCP是面向连接的协议", "", "", "", "", "对", "", ""}, &testExcelColumns, "")
+	assert.NoError(t, err)
+	assert.Empty(t, question.Options)
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/vaynedu/exam_system/config"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/model"
+	"gorm.io/gorm"
+)
+
+// ReviewDueResult 今日待复习结果
+type ReviewDueResult struct {
+	Cards         []*model.ExamReviewCard `json:"cards"`
+	DueTotal      int64                   `json:"due_total"`      // 当前到期总数（不受每日上限限制）
+	ReviewedToday int64                   `json:"reviewed_today"` // 今日已复习数量
+	DailyLimit    int                     `json:"daily_limit"`    // 每日复习上限
+}
+
+// ScheduleReview 按SM-2算法根据自评等级更新复习卡片的难度系数、间隔与下次复习日期
+func ScheduleReview(card *model.ExamReviewCard, rating int, now time.Time) {
+	quality := consts.GetReviewQuality(rating)
+
+	if quality < 3 {
+		// 回忆失败：重新开始学习，明天再复习
+		card.Repetitions = 0
+		card.IntervalDays = 1
+		card.LapseCount++
+	} else {
+		card.Repetitions++
+		switch card.Repetitions {
+		case 1:
+			card.IntervalDays = 1
+		case 2:
+			card.IntervalDays = 6
+		default:
+			card.IntervalDays = int(math.Round(float64(card.IntervalDays) * card.EaseFactor))
+		}
+	}
+
+	if card.EaseFactor == 0 {
+		card.EaseFactor = consts.ReviewInitialEaseFactor
+	}
+	diff := float64(5 - quality)
+	card.EaseFactor += 0.1 - diff*(0.08+diff*0.02)
+	if card.EaseFactor < consts.ReviewMinEaseFactor {
+		card.EaseFactor = consts.ReviewMinEaseFactor
+	}
+
+	card.ReviewCount++
+	card.LastRating = int8(rating)
+	card.LastReviewedAt = &now
+	card.DueDate = startOfDay(now).AddDate(0, 0, card.IntervalDays)
+}
+
+// RateReviewService 用户对题目自评，更新复习计划
+func RateReviewService(userID, questionID uint, rating int) (*model.ExamReviewCard, error) {
+	if questionID == 0 {
+		return nil, errors.New("题目ID不能为空")
+	}
+	if !consts.CheckReviewRating(rating) {
+		return nil, fmt.Errorf("无效的自评等级：%d", rating)
+	}
+
+	questions, err := dao.NewQuestionDao(config.DB).GetQuestionsByIDList([]uint{questionID})
+	if err != nil {
+		return nil, err
+	}
+	if len(questions) == 0 {
+		return nil, errors.New("题目不存在")
+	}
+
+	return applyReviewRating(config.DB, userID, &questions[0], rating, time.Now())
+}
+
+// GetDueReviewQuestionsService 获取今日到期待复习的题目（受每日上限限制）
+func GetDueReviewQuestionsService(userID uint, tag, secondTag string) (*ReviewDueResult, error) {
+	if err := validateFilterTags(tag, secondTag); err != nil {
+		return nil, err
+	}
+
+	setting, err := GetReviewSettingService(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewDao := dao.NewReviewDao(config.DB)
+	today := startOfDay(time.Now())
+	tomorrow := today.AddDate(0, 0, 1)
+
+	dueTotal, err := reviewDao.CountDueCards(userID, tag, secondTag, tomorrow)
+	if err != nil {
+		return nil, err
+	}
+	reviewedToday, err := reviewDao.CountReviewedSince(userID, today)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReviewDueResult{
+		Cards:         []*model.ExamReviewCard{},
+		DueTotal:      dueTotal,
+		ReviewedToday: reviewedToday,
+		DailyLimit:    setting.DailyLimit,
+	}
+	remaining := setting.DailyLimit - int(reviewedToday)
+	if remaining <= 0 {
+		return result, nil
+	}
+
+	cards, err := reviewDao.GetDueCards(userID, tag, secondTag, tomorrow, remaining)
+	if err != nil {
+		return nil, err
+	}
+	for _, card := range cards {
+		if card.Question != nil {
+			result.Cards = append(result.Cards, card)
+		}
+	}
+	return result, nil
+}
+
+// GetReviewForecastService 预测未来days天每天的待复习数量（已逾期的计入今天）
+func GetReviewForecastService(userID uint, days int) ([]dao.ReviewForecast, error) {
+	if days <= 0 {
+		days = consts.ReviewDefaultForecastDay
+	}
+	if days > consts.ReviewMaxForecastDay {
+		return nil, fmt.Errorf("预测天数不能超过%d天", consts.ReviewMaxForecastDay)
+	}
+
+	reviewDao := dao.NewReviewDao(config.DB)
+	today := startOfDay(time.Now())
+	tomorrow := today.AddDate(0, 0, 1)
+
+	todayCount, err := reviewDao.CountDueCards(userID, "", "", tomorrow)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := reviewDao.GetDueForecast(userID, tomorrow, today.AddDate(0, 0, days))
+	if err != nil {
+		return nil, err
+	}
+	countMap := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		countMap[row.Date] = row.Count
+	}
+
+	forecast := make([]dao.ReviewForecast, 0, days)
+	forecast = append(forecast, dao.ReviewForecast{Date: today.Format("2006-01-02"), Count: todayCount})
+	for i := 1; i < days; i++ {
+		date := today.AddDate(0, 0, i).Format("2006-01-02")
+		forecast = append(forecast, dao.ReviewForecast{Date: date, Count: countMap[date]})
+	}
+	return forecast, nil
+}
+
+// GetReviewSettingService 获取复习设置，未设置时返回默认值
+func GetReviewSettingService(userID uint) (*model.ExamReviewSetting, error) {
+	setting, err := dao.NewReviewDao(config.DB).GetSetting(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &model.ExamReviewSetting{UserID: userID, DailyLimit: consts.ReviewDefaultDailyLimit}, nil
+		}
+		return nil, err
+	}
+	return setting, nil
+}
+
+// UpdateReviewSettingService 更新每日复习上限
+func UpdateReviewSettingService(userID uint, dailyLimit int) (*model.ExamReviewSetting, error) {
+	if dailyLimit <= 0 || dailyLimit > consts.ReviewMaxDailyLimit {
+		return nil, fmt.Errorf("每日复习上限需在1-%d之间", consts.ReviewMaxDailyLimit)
+	}
+
+	setting, err := GetReviewSettingService(userID)
+	if err != nil {
+		return nil, err
+	}
+	setting.DailyLimit = dailyLimit
+	if err := dao.NewReviewDao(config.DB).SaveSetting(setting); err != nil {
+		return nil, err
+	}
+	return setting, nil
+}
+
+// applyReviewRating 获取（或新建）题目的复习卡片并按自评等级更新
+func applyReviewRating(db *gorm.DB, userID uint, question *model.ExamQuestion, rating int, now time.Time) (*model.ExamReviewCard, error) {
+	reviewDao := dao.NewReviewDao(db)
+	card, err := reviewDao.GetCardByQuestionID(userID, question.ID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		card = &model.ExamReviewCard{
+			UserID:     userID,
+			QuestionID: question.ID,
+			EaseFactor: consts.ReviewInitialEaseFactor,
+		}
+	}
+	card.Tag = question.Tag
+	card.SecondTag = question.SecondTag
+
+	ScheduleReview(card, rating, now)
+	if err := reviewDao.SaveCard(card); err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+// recordReviewFromAnswers 根据判分结果更新复习计划：答对视为良好，答错视为忘记，未作答和待评阅不更新
+func recordReviewFromAnswers(tx *gorm.DB, userID uint, answers []*model.ExamSessionAnswer) error {
+	for _, answer := range answers {
+		if answer.Question == nil || answer.AnsweredAt == nil {
+			continue
+		}
+		var rating int
+		switch int(answer.Result) {
+		case consts.AnswerResultCorrect:
+			rating = consts.ReviewRatingGood
+		case consts.AnswerResultWrong:
+			rating = consts.ReviewRatingAgain
+		default:
+			continue
+		}
+		if _, err := applyReviewRating(tx, userID, answer.Question, rating, *answer.AnsweredAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateFilterTags 筛选用的标签校验：允许只传一级分类，传入的分类必须存在于知识树
+func validateFilterTags(tag, secondTag string) error {
+	if tag != "" && !IsValidPrimaryTag(tag) {
+		return errors.New("一级分类无效")
+	}
+	if secondTag != "" {
+		if tag != "" && !IsSecondaryOfPrimary(tag, secondTag) {
+			return errors.New("二级分类与一级分类不匹配")
+		}
+		if tag == "" && !consts.IsValidSecondaryTag(secondTag) {
+			return errors.New("二级分类无效")
+		}
+	}
+	return nil
+}
+
+// startOfDay 返回当天零点
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
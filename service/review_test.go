@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+)
+
+// 测试ScheduleReview连续答对时间隔按SM-2增长
+func TestScheduleReview_Success(t *testing.T) {
+	now := time.Date(2025, 1, 1, 15, 30, 0, 0, time.Local)
+	card := &model.ExamReviewCard{EaseFactor: consts.ReviewInitialEaseFactor}
+
+	ScheduleReview(card, consts.ReviewRatingGood, now)
+	assert.Equal(t, 1, card.Repetitions)
+	assert.Equal(t, 1, card.IntervalDays)
+	assert.Equal(t, time.Date(2025, 1, 2, 0, 0, 0, 0, time.Local), card.DueDate)
+	assert.InDelta(t, 2.5, card.EaseFactor, 1e-9)
+
+	ScheduleReview(card, consts.ReviewRatingGood, now)
+	assert.Equal(t, 6, card.IntervalDays)
+
+	ScheduleReview(card, consts.ReviewRatingEasy, now)
+	assert.Equal(t, 15, card.IntervalDays)
+	assert.InDelta(t, 2.6, card.EaseFactor, 1e-9)
+	assert.Equal(t, 3, card.ReviewCount)
+}
+
+// 测试ScheduleReview遗忘后重置间隔且难度系数不低于下限
+func TestScheduleReview_Lapse(t *testing.T) {
+	now := time.Date(2025, 1, 1, 8, 0, 0, 0, time.Local)
+	card := &model.ExamReviewCard{EaseFactor: 1.4, Repetitions: 5, IntervalDays: 30}
+
+	ScheduleReview(card, consts.ReviewRatingAgain, now)
+	assert.Equal(t, 0, card.Repetitions)
+	assert.Equal(t, 1, card.IntervalDays)
+	assert.Equal(t, 1, card.LapseCount)
+	assert.Equal(t, consts.ReviewMinEaseFactor, card.EaseFactor)
+	assert.Equal(t, int8(consts.ReviewRatingAgain), card.LastRating)
+}
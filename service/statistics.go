@@ -0,0 +1,111 @@
+package service
+
+import (
+	"time"
+
+	"github.com/vaynedu/exam_system/config"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
+)
+
+// UserStatistics 当前用户的学习统计
+type UserStatistics struct {
+	*dao.SessionStatistics
+	AccuracyRate       int   `json:"accuracy_rate"`        // 正确率（百分制，仅统计已判分题目）
+	CollectionCount    int64 `json:"collection_count"`     // 收藏数量
+	WrongQuestionCount int64 `json:"wrong_question_count"` // 错题本题目数量
+	UnmasteredCount    int64 `json:"unmastered_count"`     // 未掌握错题数量
+	ReviewDueCount     int64 `json:"review_due_count"`     // 今日到期待复习数量
+
+	TypeStatistics []*QuestionTypeStatistics `json:"type_statistics"` // 按题型的作答统计
+}
+
+// QuestionTypeStatistics 用户按题型的作答统计
+type QuestionTypeStatistics struct {
+	*dao.QuestionTypeAnswerStatistics
+	TypeName     string `json:"type_name"`
+	AccuracyRate int    `json:"accuracy_rate"` // 正确率（百分制，仅统计已判分题目）
+}
+
+// QuestionTypeBankCount 题库按题型的题目数量
+type QuestionTypeBankCount struct {
+	QuestionType int    `json:"question_type"`
+	TypeName     string `json:"type_name"`
+	Count        int64  `json:"count"`
+}
+
+// GetUserStatisticsService 获取当前用户的练习、收藏、错题、复习统计
+func GetUserStatisticsService(userID uint) (*UserStatistics, error) {
+	sessionStats, err := dao.NewExamSessionDao(config.DB).GetSessionStatistics(userID)
+	if err != nil {
+		return nil, err
+	}
+	collectionCount, err := dao.NewCollectionDao(config.DB).CountByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	unmastered, wrongTotal, err := dao.NewWrongQuestionDao(config.DB).CountByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	typeRows, err := dao.NewExamSessionDao(config.DB).GetAnswerStatisticsByType(userID)
+	if err != nil {
+		return nil, err
+	}
+	tomorrow := startOfDay(time.Now()).AddDate(0, 0, 1)
+	reviewDue, err := dao.NewReviewDao(config.DB).CountDueCards(userID, "", "", tomorrow)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &UserStatistics{
+		SessionStatistics:  sessionStats,
+		CollectionCount:    collectionCount,
+		WrongQuestionCount: wrongTotal,
+		UnmasteredCount:    unmastered,
+		ReviewDueCount:     reviewDue,
+		TypeStatistics:     buildQuestionTypeStatistics(typeRows),
+	}
+	if graded := sessionStats.CorrectCount + sessionStats.WrongCount; graded > 0 {
+		stats.AccuracyRate = int(sessionStats.CorrectCount * 100 / graded)
+	}
+	return stats, nil
+}
+
+// GetQuestionTypeCountsService 获取题库中各题型的题目数量（没有题目的题型数量为0）
+func GetQuestionTypeCountsService() ([]*QuestionTypeBankCount, error) {
+	rows, err := dao.NewQuestionDao(config.DB).CountByQuestionType()
+	if err != nil {
+		return nil, err
+	}
+	countMap := make(map[int]int64, len(rows))
+	for _, row := range rows {
+		countMap[row.QuestionType] = row.Count
+	}
+
+	counts := make([]*QuestionTypeBankCount, 0, len(consts.QuestionTypes))
+	for _, questionType := range consts.QuestionTypes {
+		counts = append(counts, &QuestionTypeBankCount{
+			QuestionType: questionType,
+			TypeName:     consts.GetQuestionTypeName(questionType),
+			Count:        countMap[questionType],
+		})
+	}
+	return counts, nil
+}
+
+// buildQuestionTypeStatistics 补充题型名称并计算各题型正确率
+func buildQuestionTypeStatistics(rows []*dao.QuestionTypeAnswerStatistics) []*QuestionTypeStatistics {
+	stats := make([]*QuestionTypeStatistics, 0, len(rows))
+	for _, row := range rows {
+		stat := &QuestionTypeStatistics{
+			QuestionTypeAnswerStatistics: row,
+			TypeName:                     consts.GetQuestionTypeName(row.QuestionType),
+		}
+		if graded := row.CorrectCount + row.WrongCount; graded > 0 {
+			stat.AccuracyRate = int(row.CorrectCount * 100 / graded)
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
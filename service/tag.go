@@ -0,0 +1,744 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vaynedu/exam_system/config"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/model"
+	"gorm.io/gorm"
+)
+
+// CreatePrimaryTagRequest 新增一级分类请求参数
+type CreatePrimaryTagRequest struct {
+	Name        string `json:"name"`
+	SortOrder   int    `json:"sort_order"`
+	Description string `json:"description"`
+}
+
+// CreateSecondaryTagRequest 新增二级分类请求参数
+type CreateSecondaryTagRequest struct {
+	PrimaryID   uint   `json:"primary_id"`
+	ParentID    uint   `json:"parent_id"` // 上级分类ID，为空表示直属于一级分类
+	Name        string `json:"name"`
+	SortOrder   int    `json:"sort_order"`
+	Description string `json:"description"`
+}
+
+// UpdateTagRequest 更新分类请求参数（名称不可在此修改，字段为空表示不修改）
+type UpdateTagRequest struct {
+	SortOrder   *int    `json:"sort_order"`
+	Description *string `json:"description"`
+	Enabled     *bool   `json:"enabled"`
+}
+
+// InitKnowledgeTreeService 初始化知识树：分类表为空时用默认知识体系填充，然后加载到内存缓存
+func InitKnowledgeTreeService() error {
+	total, err := dao.NewTagDao(config.DB).CountPrimaryTags()
+	if err != nil {
+		return err
+	}
+	if total == 0 {
+		err = config.DB.Transaction(func(tx *gorm.DB) error {
+			tagDao := dao.NewTagDao(tx)
+			for i, pt := range consts.DefaultKnowledgeTree {
+				primary := &model.ExamTagPrimary{Name: pt.Name, SortOrder: i + 1, Enabled: true}
+				if err := tagDao.CreatePrimaryTag(primary); err != nil {
+					return err
+				}
+				secondaries := make([]*model.ExamTagSecondary, 0, len(pt.SecondTag))
+				for j, name := range pt.SecondTag {
+					secondaries = append(secondaries, &model.ExamTagSecondary{
+						PrimaryID: primary.ID,
+						Name:      name,
+						SortOrder: j + 1,
+						Enabled:   true,
+					})
+				}
+				if err := tagDao.CreateSecondaryTagsInBatches(secondaries, 100); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("初始化分类表失败：%w", err)
+		}
+	}
+	return RefreshKnowledgeTreeCache()
+}
+
+// RefreshKnowledgeTreeCache 从数据库重新加载知识树到内存缓存
+func RefreshKnowledgeTreeCache() error {
+	primaries, err := dao.NewTagDao(config.DB).GetAllPrimaryTags()
+	if err != nil {
+		return fmt.Errorf("加载知识树失败：%w", err)
+	}
+	consts.SetKnowledgeTree(BuildKnowledgeTree(primaries))
+	return nil
+}
+
+// refreshKnowledgeTreeAfterChange 分类变更提交后刷新知识树缓存：变更已写入数据库，刷新失败只记录日志、不向调用方返回错误，
+// 缓存在下一次分类变更或服务重启时重新加载
+func refreshKnowledgeTreeAfterChange() {
+	if err := RefreshKnowledgeTreeCache(); err != nil {
+		log.Printf("分类变更已保存，但刷新知识树缓存失败：%v", err)
+	}
+}
+
+// BuildKnowledgeTree 将分类表数据转换为知识树，停用的分类（及其全部下级分类）不出现在结果中
+func BuildKnowledgeTree(primaries []*model.ExamTagPrimary) []consts.PrimaryTag {
+	tree := make([]consts.PrimaryTag, 0, len(primaries))
+	for _, primary := range primaries {
+		if !primary.Enabled {
+			continue
+		}
+		children := buildTagNodes(primary.Secondaries)
+		tree = append(tree, consts.PrimaryTag{
+			Name:      primary.Name,
+			SecondTag: flattenTagNodes(children, make([]string, 0, len(primary.Secondaries))),
+			Children:  children,
+		})
+	}
+	return tree
+}
+
+// buildTagNodes 构建一级分类下的分类树，上级分类已不存在的节点视为直属于一级分类
+func buildTagNodes(secondaries []*model.ExamTagSecondary) []consts.TagNode {
+	ids := make(map[uint]bool, len(secondaries))
+	for _, secondary := range secondaries {
+		ids[secondary.ID] = true
+	}
+	childrenOf := make(map[uint][]*model.ExamTagSecondary, len(secondaries))
+	var roots []*model.ExamTagSecondary
+	for _, secondary := range secondaries {
+		if secondary.ParentID == 0 || !ids[secondary.ParentID] {
+			roots = append(roots, secondary)
+		} else {
+			childrenOf[secondary.ParentID] = append(childrenOf[secondary.ParentID], secondary)
+		}
+	}
+	return buildTagChildren(roots, childrenOf, make(map[*model.ExamTagSecondary]bool, len(secondaries)))
+}
+
+// buildTagChildren 递归构建分类节点，跳过停用的分类及其下级分类，visited防止脏数据成环
+func buildTagChildren(secondaries []*model.ExamTagSecondary, childrenOf map[uint][]*model.ExamTagSecondary, visited map[*model.ExamTagSecondary]bool) []consts.TagNode {
+	var nodes []consts.TagNode
+	for _, secondary := range secondaries {
+		if !secondary.Enabled || visited[secondary] {
+			continue
+		}
+		visited[secondary] = true
+		nodes = append(nodes, consts.TagNode{
+			Name:     secondary.Name,
+			Children: buildTagChildren(childrenOf[secondary.ID], childrenOf, visited),
+		})
+	}
+	return nodes
+}
+
+// flattenTagNodes 按先序遍历平铺分类树中的名称
+func flattenTagNodes(nodes []consts.TagNode, names []string) []string {
+	for _, node := range nodes {
+		names = append(names, node.Name)
+		names = flattenTagNodes(node.Children, names)
+	}
+	return names
+}
+
+// GetAllTagsService 获取全部分类（含停用的），供分类管理使用
+func GetAllTagsService() ([]*model.ExamTagPrimary, error) {
+	return dao.NewTagDao(config.DB).GetAllPrimaryTags()
+}
+
+// CreatePrimaryTagService 新增一级分类
+func CreatePrimaryTagService(req *CreatePrimaryTagRequest) (*model.ExamTagPrimary, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if err := validateTagFields(req.Name, req.Description, consts.TagPrimaryNameMaxLen); err != nil {
+		return nil, err
+	}
+
+	tagDao := dao.NewTagDao(config.DB)
+	if _, err := tagDao.GetPrimaryTagByName(req.Name); err == nil {
+		return nil, fmt.Errorf("一级分类已存在：%s", req.Name)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	tag := &model.ExamTagPrimary{
+		Name:        req.Name,
+		SortOrder:   req.SortOrder,
+		Description: req.Description,
+		Enabled:     true,
+	}
+	if err := tagDao.CreatePrimaryTag(tag); err != nil {
+		return nil, err
+	}
+	refreshKnowledgeTreeAfterChange()
+	return tag, nil
+}
+
+// UpdatePrimaryTagService 更新一级分类的排序、描述与启用状态
+func UpdatePrimaryTagService(id uint, req *UpdateTagRequest) (*model.ExamTagPrimary, error) {
+	tagDao := dao.NewTagDao(config.DB)
+	tag, err := getPrimaryTag(tagDao, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyUpdateTagRequest(req, &tag.SortOrder, &tag.Description, &tag.Enabled); err != nil {
+		return nil, err
+	}
+	if err := tagDao.UpdatePrimaryTag(tag); err != nil {
+		return nil, err
+	}
+	refreshKnowledgeTreeAfterChange()
+	return tag, nil
+}
+
+// DeletePrimaryTagService 删除一级分类：存在二级分类或仍有题目引用时不允许删除
+func DeletePrimaryTagService(id uint) error {
+	tagDao := dao.NewTagDao(config.DB)
+	tag, err := getPrimaryTag(tagDao, id)
+	if err != nil {
+		return err
+	}
+
+	secondaryCount, err := tagDao.CountSecondaryTags(id)
+	if err != nil {
+		return err
+	}
+	if secondaryCount > 0 {
+		return fmt.Errorf("该一级分类下还有%d个二级分类，请先删除二级分类", secondaryCount)
+	}
+	questionCount, err := tagDao.CountQuestionRefs(dao.TagRef{Tag: tag.Name})
+	if err != nil {
+		return err
+	}
+	if questionCount > 0 {
+		return fmt.Errorf("该分类下还有%d道题目，不能删除（可改为停用）", questionCount)
+	}
+
+	if err := tagDao.DeletePrimaryTag(id); err != nil {
+		return err
+	}
+	refreshKnowledgeTreeAfterChange()
+	return nil
+}
+
+// CreateSecondaryTagService 新增二级分类
+func CreateSecondaryTagService(req *CreateSecondaryTagRequest) (*model.ExamTagSecondary, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if err := validateTagFields(req.Name, req.Description, consts.TagSecondaryNameMaxLen); err != nil {
+		return nil, err
+	}
+
+	tagDao := dao.NewTagDao(config.DB)
+	primary, err := getPrimaryTag(tagDao, req.PrimaryID)
+	if err != nil {
+		return nil, err
+	}
+	if req.ParentID != 0 {
+		parent, _, err := getSecondaryTagWithPrimary(tagDao, req.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("上级%w", err)
+		}
+		if parent.PrimaryID != req.PrimaryID {
+			return nil, errors.New("上级分类不属于该一级分类")
+		}
+	}
+	if err := ensureSecondaryTagNameFree(tagDao, primary, req.Name); err != nil {
+		return nil, err
+	}
+
+	tag := &model.ExamTagSecondary{
+		PrimaryID:   req.PrimaryID,
+		ParentID:    req.ParentID,
+		Name:        req.Name,
+		SortOrder:   req.SortOrder,
+		Description: req.Description,
+		Enabled:     true,
+	}
+	if err := tagDao.CreateSecondaryTag(tag); err != nil {
+		return nil, err
+	}
+	refreshKnowledgeTreeAfterChange()
+	return tag, nil
+}
+
+// UpdateSecondaryTagService 更新二级分类的排序、描述与启用状态
+func UpdateSecondaryTagService(id uint, req *UpdateTagRequest) (*model.ExamTagSecondary, error) {
+	tagDao := dao.NewTagDao(config.DB)
+	tag, err := tagDao.GetSecondaryTagByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("二级分类不存在")
+		}
+		return nil, err
+	}
+
+	if err := applyUpdateTagRequest(req, &tag.SortOrder, &tag.Description, &tag.Enabled); err != nil {
+		return nil, err
+	}
+	if err := tagDao.UpdateSecondaryTag(tag); err != nil {
+		return nil, err
+	}
+	refreshKnowledgeTreeAfterChange()
+	return tag, nil
+}
+
+// DeleteSecondaryTagService 删除二级分类：存在下级分类或仍有题目引用时不允许删除
+func DeleteSecondaryTagService(id uint) error {
+	tagDao := dao.NewTagDao(config.DB)
+	tag, primary, err := getSecondaryTagWithPrimary(tagDao, id)
+	if err != nil {
+		return err
+	}
+
+	childCount, err := tagDao.CountChildTags(id)
+	if err != nil {
+		return err
+	}
+	if childCount > 0 {
+		return fmt.Errorf("该分类下还有%d个下级分类，请先删除下级分类", childCount)
+	}
+	questionCount, err := tagDao.CountQuestionRefs(dao.TagRef{Tag: primary.Name, SecondTag: tag.Name})
+	if err != nil {
+		return err
+	}
+	if questionCount > 0 {
+		return fmt.Errorf("该分类下还有%d道题目，不能删除（可改为停用）", questionCount)
+	}
+
+	if err := tagDao.DeleteSecondaryTag(id); err != nil {
+		return err
+	}
+	refreshKnowledgeTreeAfterChange()
+	return nil
+}
+
+// validateTagFields 校验分类名称与描述
+func validateTagFields(name, description string, nameMaxLen int) error {
+	if name == "" {
+		return errors.New("分类名称不能为空")
+	}
+	if utf8.RuneCountInString(name) > nameMaxLen {
+		return fmt.Errorf("分类名称不能超过%d个字符", nameMaxLen)
+	}
+	if utf8.RuneCountInString(description) > consts.TagDescriptionMaxLen {
+		return fmt.Errorf("分类描述不能超过%d个字符", consts.TagDescriptionMaxLen)
+	}
+	return nil
+}
+
+// applyUpdateTagRequest 将更新参数中非空的字段写入分类
+func applyUpdateTagRequest(req *UpdateTagRequest, sortOrder *int, description *string, enabled *bool) error {
+	if req.Description != nil {
+		if utf8.RuneCountInString(*req.Description) > consts.TagDescriptionMaxLen {
+			return fmt.Errorf("分类描述不能超过%d个字符", consts.TagDescriptionMaxLen)
+		}
+		*description = *req.Description
+	}
+	if req.SortOrder != nil {
+		*sortOrder = *req.SortOrder
+	}
+	if req.Enabled != nil {
+		*enabled = *req.Enabled
+	}
+	return nil
+}
+
+// TagOperationResult 分类重命名/合并/移动的影响范围
+type TagOperationResult struct {
+	DryRun          bool  `json:"dry_run"`          // 是否仅预览（未实际修改）
+	QuestionCount   int64 `json:"question_count"`   // 受影响的题目数量
+	CollectionCount int64 `json:"collection_count"` // 受影响的收藏数量
+}
+
+// RenamePrimaryTagService 重命名一级分类，同步更新引用该分类的题目、收藏等数据
+func RenamePrimaryTagService(id uint, name string, dryRun bool) (*TagOperationResult, error) {
+	name = strings.TrimSpace(name)
+	if err := validateTagFields(name, "", consts.TagPrimaryNameMaxLen); err != nil {
+		return nil, err
+	}
+
+	var result *TagOperationResult
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		tagDao := dao.NewTagDao(tx)
+		tag, err := getPrimaryTag(tagDao, id)
+		if err != nil {
+			return err
+		}
+		if tag.Name == name {
+			return errors.New("新名称与原名称相同")
+		}
+		if err := ensurePrimaryTagNameFree(tagDao, name); err != nil {
+			return err
+		}
+
+		from, to := dao.TagRef{Tag: tag.Name}, dao.TagRef{Tag: name}
+		if result, err = countTagOperation(tagDao, dryRun, from); err != nil || dryRun {
+			return err
+		}
+		if err := tagDao.RenamePrimaryTag(id, name); err != nil {
+			return err
+		}
+		return tagDao.ReplaceTagRefs(from, to)
+	})
+	return finishTagOperation(result, dryRun, err)
+}
+
+// MergePrimaryTagService 将一级分类合并到目标一级分类：下级分类迁移到目标下（重名的分类合并），源分类删除
+func MergePrimaryTagService(sourceID, targetID uint, dryRun bool) (*TagOperationResult, error) {
+	if sourceID == targetID {
+		return nil, errors.New("不能合并到自身")
+	}
+
+	var result *TagOperationResult
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		tagDao := dao.NewTagDao(tx)
+		source, err := getPrimaryTag(tagDao, sourceID)
+		if err != nil {
+			return err
+		}
+		target, err := getPrimaryTag(tagDao, targetID)
+		if err != nil {
+			return fmt.Errorf("目标%w", err)
+		}
+
+		from, to := dao.TagRef{Tag: source.Name}, dao.TagRef{Tag: target.Name}
+		if result, err = countTagOperation(tagDao, dryRun, from); err != nil || dryRun {
+			return err
+		}
+
+		sourceSecondaries, err := tagDao.GetSecondaryTagsByPrimaryID(sourceID)
+		if err != nil {
+			return err
+		}
+		targetSecondaries, err := tagDao.GetSecondaryTagsByPrimaryID(targetID)
+		if err != nil {
+			return err
+		}
+		moves, duplicates := splitMergedSecondaries(sourceSecondaries, targetSecondaries)
+		for _, secondary := range moves {
+			// 上级分类被合并时，挂到目标一级分类下的同名分类
+			parentID := secondary.ParentID
+			if mergedID, ok := duplicates[parentID]; ok {
+				parentID = mergedID
+			}
+			if err := tagDao.MoveSecondaryTag(secondary.ID, targetID, parentID); err != nil {
+				return err
+			}
+		}
+		for sourceSecondaryID := range duplicates {
+			if err := tagDao.DeleteSecondaryTag(sourceSecondaryID); err != nil {
+				return err
+			}
+		}
+		if err := tagDao.DeletePrimaryTag(sourceID); err != nil {
+			return err
+		}
+		return tagDao.ReplaceTagRefs(from, to)
+	})
+	return finishTagOperation(result, dryRun, err)
+}
+
+// RenameSecondaryTagService 重命名二级分类，同步更新引用该分类的题目、收藏等数据
+func RenameSecondaryTagService(id uint, name string, dryRun bool) (*TagOperationResult, error) {
+	name = strings.TrimSpace(name)
+	if err := validateTagFields(name, "", consts.TagSecondaryNameMaxLen); err != nil {
+		return nil, err
+	}
+
+	var result *TagOperationResult
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		tagDao := dao.NewTagDao(tx)
+		tag, primary, err := getSecondaryTagWithPrimary(tagDao, id)
+		if err != nil {
+			return err
+		}
+		if tag.Name == name {
+			return errors.New("新名称与原名称相同")
+		}
+		if err := ensureSecondaryTagNameFree(tagDao, primary, name); err != nil {
+			return err
+		}
+
+		from := dao.TagRef{Tag: primary.Name, SecondTag: tag.Name}
+		to := dao.TagRef{Tag: primary.Name, SecondTag: name}
+		if result, err = countTagOperation(tagDao, dryRun, from); err != nil || dryRun {
+			return err
+		}
+		if err := tagDao.RenameSecondaryTag(id, name); err != nil {
+			return err
+		}
+		return tagDao.ReplaceTagRefs(from, to)
+	})
+	return finishTagOperation(result, dryRun, err)
+}
+
+// MergeSecondaryTagService 将二级分类合并到目标二级分类（可跨一级分类），源分类的下级分类挂到目标分类下，源分类删除
+func MergeSecondaryTagService(sourceID, targetID uint, dryRun bool) (*TagOperationResult, error) {
+	if sourceID == targetID {
+		return nil, errors.New("不能合并到自身")
+	}
+
+	var result *TagOperationResult
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		tagDao := dao.NewTagDao(tx)
+		source, sourcePrimary, err := getSecondaryTagWithPrimary(tagDao, sourceID)
+		if err != nil {
+			return err
+		}
+		target, targetPrimary, err := getSecondaryTagWithPrimary(tagDao, targetID)
+		if err != nil {
+			return fmt.Errorf("目标%w", err)
+		}
+		all, err := tagDao.GetSecondaryTagsByPrimaryID(sourcePrimary.ID)
+		if err != nil {
+			return err
+		}
+		subtree := collectTagSubtree(all, sourceID)
+		for _, node := range subtree {
+			if node.ID == targetID {
+				return errors.New("不能合并到自身的下级分类")
+			}
+		}
+
+		// 跨一级分类合并时，源分类的下级分类需整体迁移到目标一级分类
+		descendants := subtree[1:]
+		if sourcePrimary.ID != targetPrimary.ID {
+			for _, node := range descendants {
+				if err := ensureSecondaryTagNameFree(tagDao, targetPrimary, node.Name); err != nil {
+					return err
+				}
+			}
+		} else {
+			descendants = nil
+		}
+
+		from := dao.TagRef{Tag: sourcePrimary.Name, SecondTag: source.Name}
+		to := dao.TagRef{Tag: targetPrimary.Name, SecondTag: target.Name}
+		refs := []dao.TagRef{from}
+		for _, node := range descendants {
+			refs = append(refs, dao.TagRef{Tag: sourcePrimary.Name, SecondTag: node.Name})
+		}
+		if result, err = countTagOperation(tagDao, dryRun, refs...); err != nil || dryRun {
+			return err
+		}
+
+		if err := tagDao.ReparentSecondaryTags(sourceID, targetID); err != nil {
+			return err
+		}
+		if err := tagDao.DeleteSecondaryTag(sourceID); err != nil {
+			return err
+		}
+		if err := tagDao.ReplaceTagRefs(from, to); err != nil {
+			return err
+		}
+		return moveTagDescendants(tagDao, descendants, sourcePrimary, targetPrimary)
+	})
+	return finishTagOperation(result, dryRun, err)
+}
+
+// MoveSecondaryTagService 将二级分类（连同其下级分类）移动到指定一级分类下的指定位置，同步更新引用该分类的数据
+// parentID为0表示直属于一级分类
+func MoveSecondaryTagService(id, primaryID, parentID uint, dryRun bool) (*TagOperationResult, error) {
+	var result *TagOperationResult
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		tagDao := dao.NewTagDao(tx)
+		tag, primary, err := getSecondaryTagWithPrimary(tagDao, id)
+		if err != nil {
+			return err
+		}
+		if primary.ID == primaryID && tag.ParentID == parentID {
+			return errors.New("分类已在该位置")
+		}
+		target, err := getPrimaryTag(tagDao, primaryID)
+		if err != nil {
+			return fmt.Errorf("目标%w", err)
+		}
+		if parentID != 0 {
+			parent, _, err := getSecondaryTagWithPrimary(tagDao, parentID)
+			if err != nil {
+				return fmt.Errorf("上级%w", err)
+			}
+			if parent.PrimaryID != primaryID {
+				return errors.New("上级分类不属于目标一级分类")
+			}
+		}
+		all, err := tagDao.GetSecondaryTagsByPrimaryID(primary.ID)
+		if err != nil {
+			return err
+		}
+		subtree := collectTagSubtree(all, id)
+		for _, node := range subtree {
+			if node.ID == parentID {
+				return errors.New("不能移动到自身或自身的下级分类下")
+			}
+		}
+
+		// 同一一级分类内只调整层级，分类名称不变，无需更新引用数据
+		if primary.ID == primaryID {
+			result = &TagOperationResult{DryRun: dryRun}
+			if dryRun {
+				return nil
+			}
+			return tagDao.MoveSecondaryTag(id, primaryID, parentID)
+		}
+
+		refs := make([]dao.TagRef, 0, len(subtree))
+		for _, node := range subtree {
+			if err := ensureSecondaryTagNameFree(tagDao, target, node.Name); err != nil {
+				return err
+			}
+			refs = append(refs, dao.TagRef{Tag: primary.Name, SecondTag: node.Name})
+		}
+		if result, err = countTagOperation(tagDao, dryRun, refs...); err != nil || dryRun {
+			return err
+		}
+		if err := tagDao.MoveSecondaryTag(id, primaryID, parentID); err != nil {
+			return err
+		}
+		if err := tagDao.ReplaceTagRefs(refs[0], dao.TagRef{Tag: target.Name, SecondTag: tag.Name}); err != nil {
+			return err
+		}
+		return moveTagDescendants(tagDao, subtree[1:], primary, target)
+	})
+	return finishTagOperation(result, dryRun, err)
+}
+
+// moveTagDescendants 将下级分类迁移到目标一级分类（层级关系不变），并同步更新引用数据
+func moveTagDescendants(tagDao *dao.TagDao, nodes []*model.ExamTagSecondary, from, to *model.ExamTagPrimary) error {
+	ids := make([]uint, 0, len(nodes))
+	for _, node := range nodes {
+		ids = append(ids, node.ID)
+	}
+	if err := tagDao.MoveSecondaryTagsToPrimary(ids, to.ID); err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		fromRef := dao.TagRef{Tag: from.Name, SecondTag: node.Name}
+		toRef := dao.TagRef{Tag: to.Name, SecondTag: node.Name}
+		if err := tagDao.ReplaceTagRefs(fromRef, toRef); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectTagSubtree 收集某分类及其全部下级分类（第一个元素为该分类本身，分类不存在时返回空）
+func collectTagSubtree(all []*model.ExamTagSecondary, rootID uint) []*model.ExamTagSecondary {
+	var subtree []*model.ExamTagSecondary
+	for _, node := range all {
+		if node.ID == rootID {
+			subtree = append(subtree, node)
+			break
+		}
+	}
+	// 按层级逐层展开，visited防止脏数据成环
+	visited := map[uint]bool{rootID: true}
+	for i := 0; i < len(subtree); i++ {
+		for _, node := range all {
+			if node.ParentID == subtree[i].ID && !visited[node.ID] {
+				visited[node.ID] = true
+				subtree = append(subtree, node)
+			}
+		}
+	}
+	return subtree
+}
+
+// splitMergedSecondaries 合并一级分类时，将源分类下的二级分类分为可直接迁移的，以及与目标分类下重名需合并的（源ID→目标同名分类ID）
+func splitMergedSecondaries(source, target []*model.ExamTagSecondary) (moves []*model.ExamTagSecondary, duplicates map[uint]uint) {
+	targetIDs := make(map[string]uint, len(target))
+	for _, secondary := range target {
+		targetIDs[secondary.Name] = secondary.ID
+	}
+	duplicates = make(map[uint]uint)
+	for _, secondary := range source {
+		if targetID, ok := targetIDs[secondary.Name]; ok {
+			duplicates[secondary.ID] = targetID
+		} else {
+			moves = append(moves, secondary)
+		}
+	}
+	return moves, duplicates
+}
+
+// countTagOperation 统计分类变更影响的题目与收藏数量
+func countTagOperation(tagDao *dao.TagDao, dryRun bool, refs ...dao.TagRef) (*TagOperationResult, error) {
+	questionCount, err := tagDao.CountQuestionRefs(refs...)
+	if err != nil {
+		return nil, err
+	}
+	collectionCount, err := tagDao.CountCollectionRefs(refs...)
+	if err != nil {
+		return nil, err
+	}
+	return &TagOperationResult{DryRun: dryRun, QuestionCount: questionCount, CollectionCount: collectionCount}, nil
+}
+
+// finishTagOperation 分类变更提交后刷新知识树缓存（预览模式不刷新）
+func finishTagOperation(result *TagOperationResult, dryRun bool, err error) (*TagOperationResult, error) {
+	if err != nil {
+		return nil, err
+	}
+	if !dryRun {
+		refreshKnowledgeTreeAfterChange()
+	}
+	return result, nil
+}
+
+// getPrimaryTag 获取一级分类，不存在时返回业务错误
+func getPrimaryTag(tagDao *dao.TagDao, id uint) (*model.ExamTagPrimary, error) {
+	tag, err := tagDao.GetPrimaryTagByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("一级分类不存在")
+		}
+		return nil, err
+	}
+	return tag, nil
+}
+
+// getSecondaryTagWithPrimary 获取二级分类及其所属一级分类，不存在时返回业务错误
+func getSecondaryTagWithPrimary(tagDao *dao.TagDao, id uint) (*model.ExamTagSecondary, *model.ExamTagPrimary, error) {
+	tag, err := tagDao.GetSecondaryTagByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("二级分类不存在")
+		}
+		return nil, nil, err
+	}
+	primary, err := getPrimaryTag(tagDao, tag.PrimaryID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tag, primary, nil
+}
+
+// ensurePrimaryTagNameFree 校验一级分类名称未被占用
+func ensurePrimaryTagNameFree(tagDao *dao.TagDao, name string) error {
+	if _, err := tagDao.GetPrimaryTagByName(name); err == nil {
+		return fmt.Errorf("一级分类已存在：%s（如需合并请使用合并操作）", name)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// ensureSecondaryTagNameFree 校验一级分类下的二级分类名称未被占用
+func ensureSecondaryTagNameFree(tagDao *dao.TagDao, primary *model.ExamTagPrimary, name string) error {
+	if _, err := tagDao.GetSecondaryTagByName(primary.ID, name); err == nil {
+		return fmt.Errorf("%s下已存在二级分类：%s（如需合并请使用合并操作）", primary.Name, name)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
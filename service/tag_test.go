@@ -0,0 +1,105 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/model"
+)
+
+func TestBuildKnowledgeTree(t *testing.T) {
+	primaries := []*model.ExamTagPrimary{
+		{
+			Name:    "数据存储",
+			Enabled: true,
+			Secondaries: []*model.ExamTagSecondary{
+				{Name: "MySQL", Enabled: true},
+				{Name: "HBase", Enabled: false},
+				{Name: "Kafka", Enabled: true},
+			},
+		},
+		{
+			Name:        "已停用",
+			Enabled:     false,
+			Secondaries: []*model.ExamTagSecondary{{Name: "子分类", Enabled: true}},
+		},
+		{Name: "空分类", Enabled: true},
+	}
+
+	tree := BuildKnowledgeTree(primaries)
+	assert.Equal(t, []consts.PrimaryTag{
+		{
+			Name:      "数据存储",
+			SecondTag: []string{"MySQL", "Kafka"},
+			Children:  []consts.TagNode{{Name: "MySQL"}, {Name: "Kafka"}},
+		},
+		{Name: "空分类", SecondTag: []string{}},
+	}, tree)
+}
+
+func TestBuildKnowledgeTreeNested(t *testing.T) {
+	primaries := []*model.ExamTagPrimary{
+		{
+			Name:    "数据存储",
+			Enabled: true,
+			Secondaries: []*model.ExamTagSecondary{
+				{ID: 1, Name: "Redis", Enabled: true},
+				{ID: 2, ParentID: 1, Name: "持久化", Enabled: true},
+				{ID: 3, ParentID: 2, Name: "AOF", Enabled: true},
+				{ID: 4, ParentID: 1, Name: "集群", Enabled: false},
+				{ID: 5, ParentID: 4, Name: "槽位", Enabled: true},
+				{ID: 6, ParentID: 99, Name: "孤立分类", Enabled: true},
+			},
+		},
+	}
+
+	tree := BuildKnowledgeTree(primaries)
+	assert.Len(t, tree, 1)
+	assert.Equal(t, []string{"Redis", "持久化", "AOF", "孤立分类"}, tree[0].SecondTag)
+	assert.Equal(t, []consts.TagNode{
+		{Name: "Redis", Children: []consts.TagNode{
+			{Name: "持久化", Children: []consts.TagNode{{Name: "AOF"}}},
+		}},
+		{Name: "孤立分类"},
+	}, tree[0].Children)
+}
+
+func TestCollectTagSubtree(t *testing.T) {
+	all := []*model.ExamTagSecondary{
+		{ID: 1, Name: "Redis"},
+		{ID: 2, ParentID: 1, Name: "持久化"},
+		{ID: 3, ParentID: 2, Name: "AOF"},
+		{ID: 4, Name: "MySQL"},
+		{ID: 5, ParentID: 1, Name: "集群"},
+	}
+
+	subtree := collectTagSubtree(all, 1)
+	names := make([]string, 0, len(subtree))
+	for _, node := range subtree {
+		names = append(names, node.Name)
+	}
+	assert.Equal(t, []string{"Redis", "持久化", "集群", "AOF"}, names)
+	assert.Len(t, collectTagSubtree(all, 4), 1)
+	assert.Empty(t, collectTagSubtree(all, 100))
+}
+
+func TestValidateTagFields(t *testing.T) {
+	assert.NoError(t, validateTagFields("Kafka", "", consts.TagSecondaryNameMaxLen))
+	assert.Error(t, validateTagFields("", "", consts.TagSecondaryNameMaxLen))
+	assert.Error(t, validateTagFields(strings.Repeat("分", consts.TagPrimaryNameMaxLen+1), "", consts.TagPrimaryNameMaxLen))
+}
+
+func TestSplitMergedSecondaries(t *testing.T) {
+	source := []*model.ExamTagSecondary{{ID: 1, Name: "Redis"}, {ID: 2, Name: "Kafka"}, {ID: 3, Name: "MySQL"}}
+	target := []*model.ExamTagSecondary{{ID: 10, Name: "MySQL"}, {ID: 11, Name: "Redis"}}
+
+	moves, duplicates := splitMergedSecondaries(source, target)
+	assert.Equal(t, []*model.ExamTagSecondary{source[1]}, moves)
+	assert.Equal(t, map[uint]uint{1: 11, 3: 10}, duplicates)
+
+	moves, duplicates = splitMergedSecondaries(source, nil)
+	assert.Len(t, moves, 3)
+	assert.Empty(t, duplicates)
+}
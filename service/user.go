@@ -0,0 +1,220 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vaynedu/exam_system/config"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/model"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// usernamePattern 用户名仅允许字母、数字、下划线
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// RegisterUserRequest 注册请求参数
+type RegisterUserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Nickname string `json:"nickname"`
+}
+
+// LoginUserRequest 登录请求参数
+type LoginUserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// LoginResult 登录结果
+type LoginResult struct {
+	Token     string          `json:"token"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	User      *model.ExamUser `json:"user"`
+}
+
+// ValidateRegisterUserRequest 校验注册参数
+func ValidateRegisterUserRequest(req *RegisterUserRequest) error {
+	req.Username = strings.TrimSpace(req.Username)
+	req.Nickname = strings.TrimSpace(req.Nickname)
+
+	if len(req.Username) < consts.UserUsernameMinLen || len(req.Username) > consts.UserUsernameMaxLen {
+		return fmt.Errorf("用户名长度需在%d-%d之间", consts.UserUsernameMinLen, consts.UserUsernameMaxLen)
+	}
+	if !usernamePattern.MatchString(req.Username) {
+		return errors.New("用户名只能包含字母、数字和下划线")
+	}
+	if len(req.Password) < consts.UserPasswordMinLen || len(req.Password) > consts.UserPasswordMaxLen {
+		return fmt.Errorf("密码长度需在%d-%d之间", consts.UserPasswordMinLen, consts.UserPasswordMaxLen)
+	}
+	if utf8.RuneCountInString(req.Nickname) > consts.UserNicknameMaxLen {
+		return fmt.Errorf("昵称不能超过%d个字符", consts.UserNicknameMaxLen)
+	}
+	return nil
+}
+
+// RegisterUserService 注册用户
+func RegisterUserService(req *RegisterUserRequest) (*model.ExamUser, error) {
+	if err := ValidateRegisterUserRequest(req); err != nil {
+		return nil, err
+	}
+
+	userDao := dao.NewUserDao(config.DB)
+	_, err := userDao.GetUserByUsername(req.Username)
+	if err == nil {
+		return nil, errors.New("用户名已存在")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("密码加密失败：%w", err)
+	}
+
+	nickname := req.Nickname
+	if nickname == "" {
+		nickname = req.Username
+	}
+
+	user := &model.ExamUser{
+		Username:     req.Username,
+		PasswordHash: string(passwordHash),
+		Nickname:     nickname,
+		Role:         consts.UserRoleLearner,
+	}
+	// 第一个注册的用户自动成为管理员，其余默认为学员；判断与插入在同一事务中并加锁，
+	// 并发注册时后到的请求等待先到的提交后再判断（空表时InnoDB检测到死锁，回滚其中一个请求）
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		txUserDao := dao.NewUserDao(tx)
+		hasUsers, err := txUserDao.HasUsersForUpdate()
+		if err != nil {
+			return err
+		}
+		if !hasUsers {
+			user.Role = consts.UserRoleAdmin
+		}
+		return txUserDao.CreateUser(user)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("注册失败，请重试：%w", err)
+	}
+	return user, nil
+}
+
+// LoginUserService 用户名密码登录，成功后签发登录态token
+func LoginUserService(req *LoginUserRequest) (*LoginResult, error) {
+	userDao := dao.NewUserDao(config.DB)
+	user, err := userDao.GetUserByUsername(strings.TrimSpace(req.Username))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("用户名或密码错误")
+		}
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, errors.New("用户名或密码错误")
+	}
+
+	token, err := generateUserToken()
+	if err != nil {
+		return nil, fmt.Errorf("生成登录态失败：%w", err)
+	}
+	now := time.Now()
+	expiresAt := now.Add(consts.UserTokenExpire)
+	if err := userDao.CreateToken(&model.ExamUserToken{
+		UserID:    user.ID,
+		TokenHash: hashUserToken(token),
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return nil, err
+	}
+	// 顺带清理该用户已过期的登录态，失败不影响登录
+	_ = userDao.DeleteExpiredTokens(user.ID, now)
+
+	return &LoginResult{Token: token, ExpiresAt: expiresAt, User: user}, nil
+}
+
+// LogoutUserService 退出登录，使token失效
+func LogoutUserService(token string) error {
+	if token == "" {
+		return nil
+	}
+	return dao.NewUserDao(config.DB).DeleteToken(hashUserToken(token))
+}
+
+// GetUserByTokenService 根据登录态token获取当前用户
+func GetUserByTokenService(token string) (*model.ExamUser, error) {
+	if token == "" {
+		return nil, errors.New("未登录")
+	}
+
+	userDao := dao.NewUserDao(config.DB)
+	userToken, err := userDao.GetValidToken(hashUserToken(token), time.Now())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("登录已失效，请重新登录")
+		}
+		return nil, err
+	}
+
+	user, err := userDao.GetUserByID(userToken.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("用户不存在")
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateUserRoleService 管理员修改用户角色（不能修改自己的角色，避免误操作失去管理员权限）
+func UpdateUserRoleService(operatorID, userID uint, role string) error {
+	if userID == 0 {
+		return errors.New("用户ID不能为空")
+	}
+	if !consts.CheckUserRole(role) {
+		return fmt.Errorf("无效的角色：%s", role)
+	}
+	if operatorID == userID {
+		return errors.New("不能修改自己的角色")
+	}
+
+	userDao := dao.NewUserDao(config.DB)
+	if _, err := userDao.GetUserByID(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户不存在")
+		}
+		return err
+	}
+	return userDao.UpdateUserRole(userID, role)
+}
+
+// GetUserListService 分页获取用户列表
+func GetUserListService(page, size int) ([]*model.ExamUser, int64, error) {
+	return dao.NewUserDao(config.DB).GetUserList(page, size)
+}
+
+// generateUserToken 生成随机登录态token
+func generateUserToken() (string, error) {
+	buf := make([]byte, consts.UserTokenRandomBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashUserToken 计算token的SHA-256摘要，数据库只保存摘要
+func hashUserToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/exam_system/consts"
+)
+
+// 测试ValidateRegisterUserRequest校验注册参数
+func TestValidateRegisterUserRequest(t *testing.T) {
+	testCases := []struct {
+		name    string
+		req     RegisterUserRequest
+		wantErr bool
+	}{
+		{"合法参数", RegisterUserRequest{Username: "alice_01", Password: "123456", Nickname: "爱丽丝"}, false},
+		{"用户名前后空格", RegisterUserRequest{Username: "  bob  ", Password: "123456"}, false},
+		{"用户名过短", RegisterUserRequest{Username: "ab", Password: "123456"}, true},
+		{"用户名过长", RegisterUserRequest{Username: strings.Repeat("a", consts.UserUsernameMaxLen+1), Password: "123456"}, true},
+		{"用户名含非法字符", RegisterUserRequest{Username: "bob-1", Password: "123456"}, true},
+		{"用户名含中文", RegisterUserRequest{Username: "张三abc", Password: "123456"}, true},
+		{"密码过短", RegisterUserRequest{Username: "alice", Password: "12345"}, true},
+		{"密码过长", RegisterUserRequest{Username: "alice", Password: strings.Repeat("x", consts.UserPasswordMaxLen+1)}, true},
+		{"昵称按字符计算长度", RegisterUserRequest{Username: "alice", Password: "123456", Nickname: strings.Repeat("昵", consts.UserNicknameMaxLen)}, false},
+		{"昵称过长", RegisterUserRequest{Username: "alice", Password: "123456", Nickname: strings.Repeat("昵", consts.UserNicknameMaxLen+1)}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := tc.req
+			err := ValidateRegisterUserRequest(&req)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, strings.TrimSpace(tc.req.Username), req.Username)
+		})
+	}
+}
+
+// 测试登录态token的生成与摘要：token随机且长度固定，数据库只保存确定的SHA-256摘要
+func TestUserToken(t *testing.T) {
+	token, err := generateUserToken()
+	assert.NoError(t, err)
+	assert.Len(t, token, consts.UserTokenRandomBytes*2)
+	other, err := generateUserToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, other)
+
+	testCases := []struct {
+		token    string
+		expected string
+	}{
+		{"", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"abc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, hashUserToken(tc.token))
+	}
+	assert.Equal(t, hashUserToken(token), hashUserToken(token))
+	assert.NotEqual(t, token, hashUserToken(token))
+	assert.NotEqual(t, hashUserToken(token), hashUserToken(other))
+
+	// 未携带token时不查询数据库，直接返回未登录
+	_, err = GetUserByTokenService("")
+	assert.EqualError(t, err, "未登录")
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/vaynedu/exam_system/config"
+	"github.com/vaynedu/exam_system/consts"
+	"github.com/vaynedu/exam_system/dao"
+	"github.com/vaynedu/exam_system/model"
+	"gorm.io/gorm"
+)
+
+// GetWrongQuestionListService 获取错题列表
+func GetWrongQuestionListService(userID uint, tag, secondTag string, mastered *bool, page, size int) ([]*model.ExamWrongQuestion, int64, error) {
+	wrongQuestionDao := dao.NewWrongQuestionDao(config.DB)
+	return wrongQuestionDao.GetWrongQuestionList(userID, tag, secondTag, mastered, page, size)
+}
+
+// MarkWrongQuestionMasteredService 将错题标记为已掌握
+func MarkWrongQuestionMasteredService(userID, questionID uint) error {
+	if questionID == 0 {
+		return errors.New("题目ID不能为空")
+	}
+
+	affected, err := dao.NewWrongQuestionDao(config.DB).MarkMastered(userID, questionID, time.Now())
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errors.New("错题本中不存在该题目")
+	}
+	return nil
+}
+
+// GetRandomWrongQuestionsService 错题练习：从未掌握的错题中随机抽题，
+// 标签参数需由调用方先经 ValidateRandomTagParams 校验（参数错误与查询错误分开返回）
+func GetRandomWrongQuestionsService(userID uint, tag, secondTag string, limit int) ([]model.ExamQuestion, error) {
+	return dao.NewWrongQuestionDao(config.DB).GetRandomWrongQuestions(userID, tag, secondTag, -1, limit)
+}
+
+// recordWrongAnswers 将判定为错误的作答记入错题本（未作答不计入）
+func recordWrongAnswers(tx *gorm.DB, userID uint, answers []*model.ExamSessionAnswer) error {
+	wrongQuestionDao := dao.NewWrongQuestionDao(tx)
+	for _, answer := range answers {
+		if answer.Result != consts.AnswerResultWrong || answer.Question == nil || answer.AnsweredAt == nil {
+			continue
+		}
+		if err := wrongQuestionDao.RecordWrongQuestion(userID, answer.Question, answer.UserAnswer, *answer.AnsweredAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
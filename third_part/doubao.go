@@ -3,52 +3,75 @@ package third_part
 import (
 	"context"
 	"errors"
-	"fmt"
 	"os"
 
-	"github.com/vaynedu/exam_system/utils"
 	"github.com/volcengine/volcengine-go-sdk/service/arkruntime"
 	"github.com/volcengine/volcengine-go-sdk/service/arkruntime/model"
 	"github.com/volcengine/volcengine-go-sdk/volcengine"
 )
 
 type DouBaoAiService struct {
-	ApiKey string // 从os获取，防止泄露
-	Model  string // 模型名称, 可以调用换模型调用
+	ApiKey  string // 从os获取，防止泄露
+	Model   string // 模型名称, 可以调用换模型调用
+	BaseURL string // 接口地址，为空时使用SDK默认地址；本地测试可指向假的LLM服务
 }
 
 func NewDouBaoAiService() *DouBaoAiService {
 	return &DouBaoAiService{
-		ApiKey: os.Getenv("ARK_API_KEY"),
-		Model:  "doubao-1-5-pro-32k-250115", // 一定要和火山官网的模型名称一致
+		ApiKey:  os.Getenv("ARK_API_KEY"),
+		Model:   envOrDefault("ARK_MODEL", "doubao-1-5-pro-32k-250115"), // 一定要和火山官网的模型名称一致
+		BaseURL: os.Getenv("ARK_BASE_URL"),
 	}
 }
 
 func (d *DouBaoAiService) GetAiGenerateQuestion(ctx context.Context, questionDesc string) (string, error) {
-	client := arkruntime.NewClientWithApiKey(d.ApiKey)
+	return d.Chat(ctx, questionDesc)
+}
+
+// Chat 发送单轮对话，返回模型回复内容
+func (d *DouBaoAiService) Chat(ctx context.Context, prompt string) (string, error) {
+	return d.chat(ctx, prompt, nil)
+}
+
+// ChatJSON 以JSON模式发送单轮对话，模型回复为JSON对象
+func (d *DouBaoAiService) ChatJSON(ctx context.Context, prompt string) (string, error) {
+	return d.chat(ctx, prompt, &model.ResponseFormat{Type: model.ResponseFormatJsonObject})
+}
+
+func (d *DouBaoAiService) chat(ctx context.Context, prompt string, responseFormat *model.ResponseFormat) (string, error) {
+	var options []arkruntime.ConfigOption
+	if d.BaseURL != "" {
+		options = append(options, arkruntime.WithBaseUrl(d.BaseURL))
+	}
+	options = append(options, arkruntime.WithTimeout(llmRequestTimeout))
+	client := arkruntime.NewClientWithApiKey(d.ApiKey, options...)
 	req := model.CreateChatCompletionRequest{
 		Model: d.Model,
 		Messages: []*model.ChatCompletionMessage{
 			&model.ChatCompletionMessage{
 				Role: "user",
 				Content: &model.ChatCompletionMessageContent{
-					StringValue: volcengine.String(questionDesc),
+					StringValue: volcengine.String(prompt),
 				},
 				Name: nil,
 			},
 		},
+		ResponseFormat: responseFormat,
 	}
 	resp, err := client.CreateChatCompletion(ctx, req)
 	if err != nil {
 		return "", err
 	}
 
-	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == nil {
+	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == nil || resp.Choices[0].Message.Content.StringValue == nil {
 		return "", errors.New("standard chat Choices is empty or content is nil")
 	}
 
-	// 调研日志组件，打印日志，临时先用fmt
-	fmt.Println(utils.PrintJsonString(resp))
-
+	reportUsage(ctx, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
 	return *resp.Choices[0].Message.Content.StringValue, nil
 }
+
+// ModelName 返回实际调用的模型名称
+func (d *DouBaoAiService) ModelName() string {
+	return d.Model
+}
@@ -0,0 +1,59 @@
+package third_part
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	arkmodel "github.com/volcengine/volcengine-go-sdk/service/arkruntime/model"
+)
+
+// StatusError 大模型接口返回的HTTP错误状态
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("chat completions failed: %s: %s", e.Status, e.Message)
+	}
+	return fmt.Sprintf("chat completions failed: %s", e.Status)
+}
+
+// IsTransientError 判断调用大模型接口的错误是否为临时性错误（网络错误、超时、HTTP 429及5xx），可以重试；
+// 参数错误、鉴权失败、回复内容无法解析等错误重试也不会成功
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return isTransientStatus(statusErr.StatusCode)
+	}
+	var apiErr *arkmodel.APIError
+	if errors.As(err, &apiErr) {
+		return isTransientStatus(apiErr.HTTPStatusCode)
+	}
+	var requestErr *arkmodel.RequestError
+	if errors.As(err, &requestErr) {
+		return isTransientStatus(requestErr.HTTPStatusCode)
+	}
+	return false
+}
+
+// isTransientStatus 限流（429）及服务端错误（5xx）可以重试
+func isTransientStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
@@ -0,0 +1,71 @@
+package third_part
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// FakeLLMService 进程内假模型（仅供测试直接注入，不能按名称选用）：按顺序返回预设回复（用完后重复最后一条），并记录收到的提示词
+type FakeLLMService struct {
+	Model string
+
+	mu      sync.Mutex
+	replies []string
+	err     error
+	prompts []string
+}
+
+func NewFakeLLMService(replies ...string) *FakeLLMService {
+	return &FakeLLMService{Model: "fake-model", replies: replies}
+}
+
+// SetReplies 重置预设回复与调用记录
+func (f *FakeLLMService) SetReplies(replies ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.replies, f.err, f.prompts = replies, nil, nil
+}
+
+// SetError 设置后每次调用都返回该错误
+func (f *FakeLLMService) SetError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+// Prompts 返回收到的全部提示词
+func (f *FakeLLMService) Prompts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.prompts...)
+}
+
+// Chat 返回下一条预设回复
+func (f *FakeLLMService) Chat(ctx context.Context, prompt string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.prompts = append(f.prompts, prompt)
+	if f.err != nil {
+		return "", f.err
+	}
+	if len(f.replies) == 0 {
+		return "", errors.New("fake llm has no reply")
+	}
+	reply := f.replies[0]
+	if len(f.replies) > 1 {
+		f.replies = f.replies[1:]
+	}
+	reportUsage(ctx, estimateTokens(prompt), estimateTokens(reply))
+	return reply, nil
+}
+
+// ChatJSON 与Chat相同，返回下一条预设回复
+func (f *FakeLLMService) ChatJSON(ctx context.Context, prompt string) (string, error) {
+	return f.Chat(ctx, prompt)
+}
+
+// ModelName 返回模型名称
+func (f *FakeLLMService) ModelName() string {
+	return f.Model
+}
@@ -0,0 +1,86 @@
+package third_part
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LLMProvider 大模型服务提供方（单轮对话）
+type LLMProvider interface {
+	// Chat 发送单轮对话，返回模型回复内容
+	Chat(ctx context.Context, prompt string) (string, error)
+	// ModelName 返回实际调用的模型名称
+	ModelName() string
+}
+
+// JSONChatProvider 支持JSON模式的大模型服务（要求模型只输出一个合法的JSON对象）
+type JSONChatProvider interface {
+	ChatJSON(ctx context.Context, prompt string) (string, error)
+}
+
+// 支持的大模型服务提供方名称
+const (
+	LLMProviderDouBao = "doubao" // 火山方舟（豆包）
+	LLMProviderAli    = "ali"    // 阿里云百炼（OpenAI兼容模式）
+	LLMProviderYunWu  = "yunwu"  // 云雾AI（OpenAI兼容）
+	LLMProviderOpenAI = "openai" // 任意OpenAI兼容接口（如OpenAI、Ollama、vLLM等本地服务）
+)
+
+// LLMProviders 全部大模型服务提供方名称
+var LLMProviders = []string{LLMProviderDouBao, LLMProviderAli, LLMProviderYunWu, LLMProviderOpenAI}
+
+// DefaultLLMProvider 默认的大模型服务提供方，可通过环境变量LLM_PROVIDER修改
+func DefaultLLMProvider() string {
+	if provider := strings.TrimSpace(os.Getenv("LLM_PROVIDER")); provider != "" {
+		return provider
+	}
+	return LLMProviderDouBao
+}
+
+// NewLLMProvider 按名称创建大模型服务提供方，name为空时使用默认提供方，modelName为空时使用该提供方的默认模型。
+// 各提供方的密钥、地址与默认模型通过环境变量配置：
+//   - doubao：ARK_API_KEY、ARK_BASE_URL、ARK_MODEL
+//   - ali：DASHSCOPE_API_KEY、ALI_BASE_URL、ALI_MODEL
+//   - yunwu：YUNWU_API_KEY、YUNWU_BASE_URL、YUNWU_MODEL
+//   - openai：OPENAI_API_KEY、OPENAI_BASE_URL、OPENAI_MODEL（Ollama可设置OPENAI_BASE_URL=http://localhost:11434/v1）
+func NewLLMProvider(name, modelName string) (LLMProvider, error) {
+	if name == "" {
+		name = DefaultLLMProvider()
+	}
+	switch name {
+	case LLMProviderDouBao:
+		service := NewDouBaoAiService()
+		if modelName != "" {
+			service.Model = modelName
+		}
+		return service, nil
+	case LLMProviderAli:
+		return newOpenAICompatibleFromEnv("DASHSCOPE_API_KEY", "ALI_BASE_URL", "ALI_MODEL",
+			"https://dashscope.aliyuncs.com/compatible-mode/v1", "qwen-plus", modelName), nil
+	case LLMProviderYunWu:
+		return newOpenAICompatibleFromEnv("YUNWU_API_KEY", "YUNWU_BASE_URL", "YUNWU_MODEL",
+			"https://yunwu.ai/v1", "gpt-4o-mini", modelName), nil
+	case LLMProviderOpenAI:
+		return newOpenAICompatibleFromEnv("OPENAI_API_KEY", "OPENAI_BASE_URL", "OPENAI_MODEL",
+			"https://api.openai.com/v1", "gpt-4o-mini", modelName), nil
+	}
+	return nil, fmt.Errorf("不支持的大模型服务：%s", name)
+}
+
+// newOpenAICompatibleFromEnv 从环境变量创建OpenAI兼容服务，未配置时使用默认地址与模型
+func newOpenAICompatibleFromEnv(keyEnv, baseURLEnv, modelEnv, defaultBaseURL, defaultModel, modelName string) *OpenAICompatibleService {
+	if modelName == "" {
+		modelName = envOrDefault(modelEnv, defaultModel)
+	}
+	return NewOpenAICompatibleService(envOrDefault(baseURLEnv, defaultBaseURL), os.Getenv(keyEnv), modelName)
+}
+
+// envOrDefault 读取环境变量，未设置时返回默认值
+func envOrDefault(key, defaultValue string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return defaultValue
+}
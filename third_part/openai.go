@@ -0,0 +1,116 @@
+package third_part
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// llmRequestTimeout 大模型接口超时时间（生成题目耗时较长，不使用全局HTTPClient的10秒超时）
+const llmRequestTimeout = 3 * time.Minute
+
+// OpenAICompatibleService OpenAI兼容的chat/completions接口（阿里百炼、云雾、Ollama等）
+type OpenAICompatibleService struct {
+	BaseURL string // 接口地址，如https://api.openai.com/v1
+	ApiKey  string
+	Model   string
+
+	client *resty.Client
+}
+
+func NewOpenAICompatibleService(baseURL, apiKey, model string) *OpenAICompatibleService {
+	return &OpenAICompatibleService{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		ApiKey:  apiKey,
+		Model:   model,
+		client: resty.NewWithClient(&http.Client{
+			Transport: NewTransport(),
+			Timeout:   llmRequestTimeout,
+		}),
+	}
+}
+
+type openAIChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIResponseFormat struct {
+	Type string `json:"type"`
+}
+
+type openAIChatRequest struct {
+	Model          string                `json:"model"`
+	Messages       []openAIChatMessage   `json:"messages"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message openAIChatMessage `json:"message"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage,omitempty"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Chat 发送单轮对话，返回模型回复内容
+func (o *OpenAICompatibleService) Chat(ctx context.Context, prompt string) (string, error) {
+	return o.chat(ctx, prompt, nil)
+}
+
+// ChatJSON 以JSON模式（response_format=json_object）发送单轮对话，模型回复为JSON对象
+func (o *OpenAICompatibleService) ChatJSON(ctx context.Context, prompt string) (string, error) {
+	return o.chat(ctx, prompt, &openAIResponseFormat{Type: "json_object"})
+}
+
+func (o *OpenAICompatibleService) chat(ctx context.Context, prompt string, responseFormat *openAIResponseFormat) (string, error) {
+	var result openAIChatResponse
+	req := o.client.R().
+		SetContext(ctx).
+		SetBody(openAIChatRequest{
+			Model:          o.Model,
+			Messages:       []openAIChatMessage{{Role: "user", Content: prompt}},
+			ResponseFormat: responseFormat,
+		}).
+		SetResult(&result).
+		SetError(&result)
+	if o.ApiKey != "" {
+		req.SetAuthToken(o.ApiKey)
+	}
+
+	resp, err := req.Post(o.BaseURL + "/chat/completions")
+	if err != nil {
+		return "", err
+	}
+	if resp.IsError() {
+		statusErr := &StatusError{StatusCode: resp.StatusCode(), Status: resp.Status()}
+		if result.Error != nil {
+			statusErr.Message = result.Error.Message
+		}
+		return "", statusErr
+	}
+	if len(result.Choices) == 0 || result.Choices[0].Message.Content == "" {
+		return "", errors.New("chat completions choices is empty or content is empty")
+	}
+	if result.Usage != nil {
+		reportUsage(ctx, result.Usage.PromptTokens, result.Usage.CompletionTokens)
+	} else {
+		// 部分本地服务不返回用量，按字符数估算
+		reportUsage(ctx, estimateTokens(prompt), estimateTokens(result.Choices[0].Message.Content))
+	}
+	return result.Choices[0].Message.Content, nil
+}
+
+// ModelName 返回实际调用的模型名称
+func (o *OpenAICompatibleService) ModelName() string {
+	return o.Model
+}
@@ -0,0 +1,33 @@
+package third_part
+
+import (
+	"context"
+	"unicode/utf8"
+)
+
+// Usage 单次调用的token用量
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+type usageContextKey struct{}
+
+// WithUsage 返回携带用量记录的ctx，使用该ctx调用大模型后，可从返回的Usage读取本次调用的token用量
+func WithUsage(ctx context.Context) (context.Context, *Usage) {
+	usage := &Usage{}
+	return context.WithValue(ctx, usageContextKey{}, usage), usage
+}
+
+// reportUsage 将接口返回的token用量写入ctx携带的用量记录（未携带时忽略）
+func reportUsage(ctx context.Context, promptTokens, completionTokens int) {
+	if usage, ok := ctx.Value(usageContextKey{}).(*Usage); ok {
+		usage.PromptTokens = promptTokens
+		usage.CompletionTokens = completionTokens
+	}
+}
+
+// estimateTokens 按字符数粗略估算token数（接口未返回用量时使用）
+func estimateTokens(text string) int {
+	return utf8.RuneCountInString(text)
+}
@@ -0,0 +1,116 @@
+// 登录态公共脚本：保存登录token，请求需要登录的接口时携带 Authorization: Bearer 请求头，
+// 未登录或登录失效（401）、权限不足（403）时跳转到登录页。各页面在自身脚本之前引入：<script src="auth.js"></script>
+const authBaseUrl = "http://127.0.0.1:8080/api";
+const authTokenKey = "exam_token";
+const authUserKey = "exam_user";
+
+// 获取已保存的登录token，未登录时返回空字符串
+function getAuthToken() {
+    return localStorage.getItem(authTokenKey) || "";
+}
+
+// 获取已保存的当前用户（登录时写入），未登录时返回null
+function getAuthUser() {
+    try {
+        return JSON.parse(localStorage.getItem(authUserKey));
+    } catch (e) {
+        return null;
+    }
+}
+
+// 登录成功后保存token与用户信息
+function saveAuth(token, user) {
+    localStorage.setItem(authTokenKey, token);
+    localStorage.setItem(authUserKey, JSON.stringify(user));
+}
+
+// 清除登录态
+function clearAuth() {
+    localStorage.removeItem(authTokenKey);
+    localStorage.removeItem(authUserKey);
+}
+
+// 当前页面的文件名及查询参数（如“random.html?tag=Redis”），作为登录后的返回地址
+function currentPage() {
+    return (location.pathname.split("/").pop() || "index.html") + location.search;
+}
+
+// 跳转到登录页，登录成功后返回当前页面
+function redirectToLogin(msg) {
+    let url = "login.html?redirect=" + encodeURIComponent(currentPage());
+    if (msg) {
+        url += "&msg=" + encodeURIComponent(msg);
+    }
+    location.href = url;
+}
+
+// authFetch 请求需要登录的接口：自动携带登录token；401时清除登录态并跳转登录页，
+// 403时提示权限不足并跳转登录页（可切换为有权限的账号）。其余响应原样返回，调用方按原来的方式处理
+function authFetch(url, options = {}) {
+    const token = getAuthToken();
+    if (!token) {
+        redirectToLogin("请先登录");
+        return Promise.reject(new Error("请先登录"));
+    }
+    const headers = Object.assign({}, options.headers, { "Authorization": "Bearer " + token });
+    return fetch(url, Object.assign({}, options, { headers })).then(res => {
+        if (res.status === 401) {
+            clearAuth();
+            redirectToLogin("登录已失效，请重新登录");
+            throw new Error("登录已失效，请重新登录");
+        }
+        if (res.status === 403) {
+            alert("当前账号没有权限执行该操作，请使用有权限的账号登录");
+            redirectToLogin("当前账号没有权限执行该操作");
+            throw new Error("没有权限执行该操作");
+        }
+        return res;
+    });
+}
+
+// 退出登录：通知后端使token失效（失败也清除本地登录态）后跳转登录页
+function logout() {
+    const token = getAuthToken();
+    const done = () => {
+        clearAuth();
+        location.href = "login.html";
+    };
+    if (!token) {
+        done();
+        return;
+    }
+    fetch(`${authBaseUrl}/user/logout`, {
+        method: "POST",
+        headers: { "Authorization": "Bearer " + token }
+    }).finally(done);
+}
+
+// 在页面右上角显示当前用户及登录/退出入口
+function renderAuthBar() {
+    if (currentPage().startsWith("login.html")) {
+        return;
+    }
+    const roleNames = { admin: "管理员", editor: "编辑", learner: "学员" };
+    const bar = document.createElement("div");
+    bar.style.cssText = "position: fixed; top: 10px; right: 20px; z-index: 1000; padding: 6px 12px;" +
+        "background: white; border: 1px solid #ddd; border-radius: 4px; font-size: 14px; color: #333;";
+    const user = getAuthToken() ? getAuthUser() : null;
+    if (user) {
+        const name = document.createElement("span");
+        name.textContent = `${user.nickname || user.username}（${roleNames[user.role] || user.role}） `;
+        const link = document.createElement("a");
+        link.href = "javascript:void(0)";
+        link.textContent = "退出登录";
+        link.onclick = logout;
+        bar.appendChild(name);
+        bar.appendChild(link);
+    } else {
+        const link = document.createElement("a");
+        link.href = "login.html?redirect=" + encodeURIComponent(currentPage());
+        link.textContent = "登录 / 注册";
+        bar.appendChild(link);
+    }
+    document.body.appendChild(bar);
+}
+
+document.addEventListener("DOMContentLoaded", renderAuthBar);